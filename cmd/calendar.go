@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/calendar"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/config"
+)
+
+// newDeliveryCalendar builds the calendar that turns deadlines into delivery
+// dates, from the embedded holidays plus the ones in the optional holidays
+// file.
+func newDeliveryCalendar(cfg *config.Config) (*calendar.Calendar, error) {
+	holidays, err := calendar.Embedded()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load embedded holidays: %w", err)
+	}
+
+	if cfg.DeliveryHolidaysPath != "" {
+		extra, err := calendar.Load(cfg.DeliveryHolidaysPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load holidays: %w", err)
+		}
+		holidays = append(holidays, extra...)
+	}
+
+	location, err := time.LoadLocation(cfg.DeliveryTimezone)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load delivery timezone: %w", err)
+	}
+
+	cutoff, err := calendar.ParseCutoff(cfg.DeliveryDispatchCutoff)
+	if err != nil {
+		return nil, err
+	}
+
+	deliveryCalendar, err := calendar.New(holidays, location, cutoff)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("delivery calendar enabled", "timezone", cfg.DeliveryTimezone, "dispatch_cutoff", cfg.DeliveryDispatchCutoff, "holidays", len(holidays))
+
+	return deliveryCalendar, nil
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/config"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/database"
+	"github.com/spf13/pflag"
+)
+
+type command struct {
+	name string
+	run  func(args []string) int
+}
+
+var commands = []command{
+	{"config check", configCheck},
+	{"tenant create", tenantCreate},
+	{"apikey create", apiKeyCreate},
+	{"apikey revoke", apiKeyRevoke},
+	{"quote import", quoteImport},
+}
+
+func runCommand(args []string) (int, bool) {
+	if len(args) < 2 {
+		return 0, false
+	}
+
+	for _, c := range commands {
+		if c.name == args[0]+" "+args[1] {
+			return c.run(args[2:]), true
+		}
+	}
+
+	return 0, false
+}
+
+func commandFlags(name string) *pflag.FlagSet {
+	return pflag.NewFlagSet(name, pflag.ContinueOnError)
+}
+
+// withDatabase loads the configuration from the environment and config file
+// and hands a connected pool to fn.
+func withDatabase(fn func(ctx context.Context, cfg *config.Config, db *pgxpool.Pool) error) int {
+	cfg, err := config.New(nil)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	if cfg.StorageBackend != config.StoragePostgres {
+		fmt.Fprintf(os.Stderr, "this command requires STORAGE_BACKEND=%s, got %s\n", config.StoragePostgres, cfg.StorageBackend)
+		return 1
+	}
+
+	db, err := database.NewConnection(cfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer db.Close()
+
+	if err := fn(context.Background(), cfg, db); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	return 0
+}
+
+func parseFlags(fs *pflag.FlagSet, args []string, required ...string) (bool, int) {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, pflag.ErrHelp) {
+			return false, 0
+		}
+		return false, 2
+	}
+
+	for _, name := range required {
+		if !fs.Changed(name) {
+			fmt.Fprintf(os.Stderr, "missing required flag --%s\n", name)
+			fs.PrintDefaults()
+			return false, 2
+		}
+	}
+
+	return true, 0
+}
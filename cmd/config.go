@@ -0,0 +1,36 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/config"
+	"github.com/spf13/pflag"
+)
+
+func configCheck(args []string) int {
+	cfg, err := config.New(args)
+	if errors.Is(err, pflag.ErrHelp) {
+		return 0
+	}
+
+	var validationErr *config.ValidationError
+	if err != nil && !errors.As(err, &validationErr) {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	for _, s := range cfg.Redacted() {
+		fmt.Printf("%s=%s\n", s.Key, s.Value)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	fmt.Fprintln(os.Stderr, "\nconfiguration is valid")
+	return 0
+}
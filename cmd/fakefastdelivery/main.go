@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"slices"
+	"syscall"
+	"time"
+
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/fakefastdelivery"
+	"github.com/spf13/pflag"
+)
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		if errors.Is(err, pflag.ErrHelp) {
+			return
+		}
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	flags := pflag.NewFlagSet("fakefastdelivery", pflag.ContinueOnError)
+	port := flags.String("port", envOr("FAKE_FASTDELIVERY_PORT", "8081"), "port to listen on")
+	scenario := flags.String("scenario", envOr("FAKE_FASTDELIVERY_SCENARIO", string(fakefastdelivery.ScenarioOK)), "default scenario, overridable per request with the X-Fake-Scenario header")
+	latency := flags.String("latency", envOr("FAKE_FASTDELIVERY_LATENCY", "2s"), "delay applied by the latency scenario")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if !slices.Contains(fakefastdelivery.Scenarios, fakefastdelivery.Scenario(*scenario)) {
+		return fmt.Errorf("unknown scenario %q, expected one of %v", *scenario, fakefastdelivery.Scenarios)
+	}
+
+	delay, err := time.ParseDuration(*latency)
+	if err != nil {
+		return fmt.Errorf("invalid latency: %w", err)
+	}
+
+	srv := &http.Server{
+		Addr: ":" + *port,
+		Handler: fakefastdelivery.New(fakefastdelivery.Config{
+			Scenario: fakefastdelivery.Scenario(*scenario),
+			Latency:  delay,
+		}),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	listenErr := make(chan error, 1)
+	go func() {
+		slog.Info("fake fast delivery api listening", "addr", srv.Addr, "scenario", *scenario, "latency", delay)
+		listenErr <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-listenErr:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return srv.Shutdown(shutdownCtx)
+}
+
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+
+	return fallback
+}
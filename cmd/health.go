@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/config"
+	fastdeliveryapi "github.com/jeancarloshp/desafio-frete-rapido/pkg/fastdelivery_api"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/health"
+)
+
+const healthCheckTimeout = 2 * time.Second
+
+func readiness(cfg *config.Config, db *databaseCheck, api *fastdeliveryapi.FastDeliveryAPI) *health.Checker {
+	checker := health.NewChecker(healthCheckTimeout)
+
+	// db is nil with the in-memory storage backend.
+	if db != nil {
+		checker.Add("database", true, db.ping)
+
+		checker.Add("migrations", true, func(ctx context.Context) error {
+			current, err := db.schemaVersion(ctx)
+			if err != nil {
+				return err
+			}
+
+			if current < db.latestVersion {
+				return fmt.Errorf("schema version is %d, expected %d", current, db.latestVersion)
+			}
+
+			return nil
+		})
+	}
+
+	checker.Add("fastdelivery_circuit_breaker", false, func(context.Context) error {
+		if api.BreakerState() == fastdeliveryapi.BreakerOpen {
+			return fastdeliveryapi.ErrCircuitOpen
+		}
+
+		return nil
+	})
+
+	if cfg.HealthUpstreamProbe {
+		checker.Add("fastdelivery_api", true, health.Cached(api.Probe, cfg.HealthUpstreamProbeInterval))
+	}
+
+	return checker
+}
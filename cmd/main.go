@@ -1,47 +1,260 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
+	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jeancarloshp/desafio-frete-rapido/internal/batch"
+	"github.com/jeancarloshp/desafio-frete-rapido/internal/policy"
 	"github.com/jeancarloshp/desafio-frete-rapido/internal/quote"
+	"github.com/jeancarloshp/desafio-frete-rapido/internal/route"
+	"github.com/jeancarloshp/desafio-frete-rapido/internal/spreadsheet"
+	"github.com/jeancarloshp/desafio-frete-rapido/internal/tenant"
+	"github.com/jeancarloshp/desafio-frete-rapido/internal/webhook"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/calendar"
 	"github.com/jeancarloshp/desafio-frete-rapido/pkg/config"
-	"github.com/jeancarloshp/desafio-frete-rapido/pkg/database"
 	"github.com/jeancarloshp/desafio-frete-rapido/pkg/database/querier"
 	fastdeliveryapi "github.com/jeancarloshp/desafio-frete-rapido/pkg/fastdelivery_api"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/health"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/lifecycle"
 	"github.com/jeancarloshp/desafio-frete-rapido/pkg/logger"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/pricing"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/ratelimit"
 	"github.com/jeancarloshp/desafio-frete-rapido/pkg/server"
+	"github.com/spf13/pflag"
 )
 
-var cfg *config.Config
+func main() {
+	if code, ok := runCommand(os.Args[1:]); ok {
+		os.Exit(code)
+	}
+
+	cfg, err := config.New(os.Args[1:])
+	if errors.Is(err, pflag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
 
-func init() {
-	cfg = config.New()
 	logger.New(cfg)
+
+	err = run(cfg, os.Args[1:])
+	if err != nil {
+		slog.Error("application stopped with error", "error", err)
+	}
+
+	_ = os.Stdout.Sync()
+
+	if err != nil {
+		os.Exit(1)
+	}
 }
 
-func main() {
-	app := server.New(cfg)
+func run(cfg *config.Config, args []string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	store, err := openStorage(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer store.close()
+
+	workers := lifecycle.NewGroup(context.Background())
+
+	watcher := config.NewWatcher(cfg, args)
+	watcher.Subscribe(func(c *config.Config) {
+		logger.SetLevel(c.LoggingLevel)
+	})
+	workers.Go("config-watcher", watcher.Run)
+
+	app := server.New(watcher)
 	v1 := app.Group("/v1")
 
-	db, err := database.NewConnection(cfg)
+	fastDeliveryAPI := fastdeliveryapi.New(cfg)
+	watcher.Subscribe(func(c *config.Config) {
+		fastDeliveryAPI.SetTimeout(c.FastDeliveryAPITimeout)
+	})
+
+	fallback, err := newFallback(cfg)
 	if err != nil {
-		slog.Error("failed to connect to database", "error", err)
-		return
+		return err
+	}
+
+	rules, err := newPricingRules(cfg)
+	if err != nil {
+		return err
 	}
-	defer db.Close()
 
+	deliveryCalendar, err := newDeliveryCalendar(cfg)
+	if err != nil {
+		return err
+	}
+
+	limiter := ratelimit.New(cfg.RateLimitRequestsPerMinute, cfg.RateLimitBurst)
+	admin := app.Group("/admin", server.AdminAuth(cfg))
+	admin.Get("/rate-limits", server.RateLimitStatsHandler(limiter))
+	admin.Get("/config/reloads", server.ConfigReloadsHandler(watcher))
+
+	// Throttle by IP before authentication, so bad keys never reach the
+	// database unthrottled.
+	v1.Use(server.RateLimitByIP(limiter))
+
+	var quoteController *quote.QuoteController
+	if store.postgres == nil {
+		quoteController, err = quote.NewQuoteController(cfg, store.quotes, fastDeliveryAPI, quote.QuoteOptions{
+			Fallback: fallback,
+			Rules:    rules,
+			Calendar: deliveryCalendar,
+		})
+		if err != nil {
+			return err
+		}
+		registerQuoteRoutes(cfg, v1, quoteController, nil)
+	} else {
+		quoteController, err = registerPostgresRoutes(cfg, v1, admin, limiter, workers, store.postgres, fastDeliveryAPI, fallback, rules, deliveryCalendar)
+		if err != nil {
+			return err
+		}
+	}
+	watcher.Subscribe(func(c *config.Config) {
+		reloadPricingRules(c, quoteController)
+	})
+
+	app.Get(health.ReadinessPath, readiness(cfg, store.database, fastDeliveryAPI).ReadinessHandler)
+
+	listenErr := make(chan error, 1)
+	go func() {
+		listenErr <- app.Listen(":" + cfg.AppPort)
+	}()
+
+	select {
+	case err := <-listenErr:
+		_ = workers.Shutdown(cfg.ShutdownGracePeriod)
+		return fmt.Errorf("failed to start server: %w", err)
+	case <-ctx.Done():
+		// A second signal terminates the process immediately.
+		stop()
+	}
+
+	return shutdown(app, workers, cfg.ShutdownGracePeriod)
+}
+
+func shutdown(app *fiber.App, workers *lifecycle.Group, grace time.Duration) error {
+	slog.Info("shutdown signal received, draining in-flight requests", "grace_period", grace)
+	deadline := time.Now().Add(grace)
+
+	var errs []error
+	if err := app.ShutdownWithTimeout(grace); err != nil {
+		errs = append(errs, fmt.Errorf("http server did not drain: %w", err))
+	}
+
+	if err := workers.Shutdown(time.Until(deadline)); err != nil {
+		errs = append(errs, err)
+	}
+
+	slog.Info("http server and background workers stopped")
+
+	return errors.Join(errs...)
+}
+
+func registerPostgresRoutes(cfg *config.Config, v1, admin fiber.Router, limiter *ratelimit.Limiter, workers *lifecycle.Group, db *pgxpool.Pool, fastDeliveryAPI *fastdeliveryapi.FastDeliveryAPI, fallback quote.FastDeliveryClient, rules *pricing.Engine, deliveryCalendar *calendar.Calendar) (*quote.QuoteController, error) {
 	q := querier.New(db)
 
-	fastDeliveryAPI := fastdeliveryapi.New(cfg)
+	tenantRepository := tenant.NewTenantRepository(q)
+	tenantController := tenant.NewTenantController(tenantRepository)
+	tenantHandler := tenant.NewTenantHandler(tenantController)
 
-	quoteRepository := quote.NewQuoteRepository(q)
-	quoteController := quote.NewQuoteController(cfg, quoteRepository, fastDeliveryAPI)
-	quoteHandler := quote.NewQuoteHandler(quoteController)
+	if cfg.AuthEnabled {
+		v1.Use(tenantHandler.AuthMiddleware, server.RateLimitByKey(limiter))
+	} else {
+		slog.Warn("api key authentication is disabled, quotes use the configured shipper credentials")
+	}
+
+	quoteRepository := quote.NewPostgresQuoteRepository(db)
+	webhookRepository := webhook.NewWebhookRepository(q)
+	webhookController := webhook.NewWebhookController(cfg, webhookRepository)
+	webhookHandler := webhook.NewWebhookHandler(webhookController)
 
-	v1.Post("/quote", quoteHandler.QuoteSimulationHandler)
-	v1.Get("/metrics", quoteHandler.QuoteMetricsHandler)
+	policyRepository := policy.NewPolicyRepository(q)
+	policyController := policy.NewPolicyController(policyRepository)
+	policyHandler := policy.NewPolicyHandler(policyController)
 
-	if err := app.Listen(":" + cfg.AppPort); err != nil {
-		slog.Error("failed to start server", "error", err)
+	quoteController, err := quote.NewQuoteController(cfg, quoteRepository, fastDeliveryAPI, quote.QuoteOptions{
+		Fallback:  fallback,
+		Rules:     rules,
+		Policies:  policyController,
+		Calendar:  deliveryCalendar,
+		Publisher: webhookController,
+	})
+	if err != nil {
+		return nil, err
 	}
+	registerQuoteRoutes(cfg, v1, quoteController, tenantController, tenantHandler.QuotaMiddleware)
+
+	batchRepository := batch.NewBatchRepository(db)
+	batchController := batch.NewBatchController(cfg, batchRepository, quoteController, tenantController)
+	batchHandler := batch.NewBatchHandler(batchController)
+
+	v1.Post("/quote-batches", server.Timeout(cfg, "POST /v1/quote-batches", batchHandler.CreateBatchHandler))
+	v1.Get("/quote-batches/:id", server.Timeout(cfg, "GET /v1/quote-batches/:id", batchHandler.BatchHandler))
+
+	v1.Post("/webhooks", webhookHandler.CreateSubscriptionHandler)
+	v1.Get("/webhooks", webhookHandler.ListSubscriptionsHandler)
+	v1.Delete("/webhooks/:id", webhookHandler.DeleteSubscriptionHandler)
+	v1.Get("/webhooks/:id/deliveries", webhookHandler.ListDeliveriesHandler)
+	v1.Post("/webhooks/:id/deliveries/:delivery_id/retry", webhookHandler.RetryDeliveryHandler)
+
+	routeRepository := route.NewRouteRepository(q)
+	routeController := route.NewRouteController(cfg, routeRepository, fastDeliveryAPI, tenantController)
+	routeHandler := route.NewRouteHandler(routeController)
+
+	v1.Post("/routes", routeHandler.CreateRouteHandler)
+	v1.Get("/routes", routeHandler.ListRoutesHandler)
+	v1.Delete("/routes/:id", routeHandler.DeleteRouteHandler)
+	v1.Get("/routes/:id/history", server.Timeout(cfg, "GET /v1/routes/:id/history", routeHandler.HistoryHandler))
+
+	batchWorker := batch.NewWorker(batchController, cfg.BatchPollInterval)
+	for i := range cfg.BatchWorkers {
+		workers.Go(fmt.Sprintf("quote-batch-worker-%d", i+1), batchWorker.Run)
+	}
+	workers.Go("quote-batch-requeue", batchWorker.RequeueStale)
+
+	workers.Go("route-scheduler", route.NewScheduler(routeController, cfg.RouteSchedulerInterval).Run)
+
+	webhookWorker := webhook.NewWorker(webhookController, time.Second)
+	for i := range cfg.WebhookWorkers {
+		workers.Go(fmt.Sprintf("webhook-worker-%d", i+1), webhookWorker.Run)
+	}
+
+	admin.Get("/quotas", tenantHandler.QuotaUsageHandler)
+	admin.Post("/carrier-policies", policyHandler.CreatePolicyHandler)
+	admin.Get("/carrier-policies", policyHandler.ListPoliciesHandler)
+	admin.Delete("/carrier-policies/:id", policyHandler.DeletePolicyHandler)
+
+	return quoteController, nil
+}
+
+// registerQuoteRoutes installs the routes that work with every storage
+// backend. tenantController may be nil when requests never carry a tenant.
+func registerQuoteRoutes(cfg *config.Config, v1 fiber.Router, quoteController *quote.QuoteController, tenantController *tenant.TenantController, quoteMiddleware ...fiber.Handler) {
+	quoteHandler := quote.NewQuoteHandler(quoteController)
+
+	v1.Post("/quote", append(quoteMiddleware, server.Timeout(cfg, "POST /v1/quote", quoteHandler.QuoteSimulationHandler))...)
+	v1.Get("/metrics", server.Timeout(cfg, "GET /v1/metrics", quoteHandler.QuoteMetricsHandler))
+
+	spreadsheetController := spreadsheet.NewSpreadsheetController(cfg, quoteController, tenantController)
+	spreadsheetHandler := spreadsheet.NewSpreadsheetHandler(spreadsheetController)
+
+	v1.Post("/quote-imports", server.RouteTimeout(cfg, "POST /v1/quote-imports", cfg.HTTPImportTimeout, spreadsheetHandler.QuoteImportHandler))
 }
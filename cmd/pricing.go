@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/jeancarloshp/desafio-frete-rapido/internal/quote"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/config"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/pricing"
+)
+
+// newPricingRules loads the rules that adjust carrier prices. It returns nil,
+// which keeps the Frete Rápido prices, when no rules file is configured.
+func newPricingRules(cfg *config.Config) (*pricing.Engine, error) {
+	if cfg.FreightPricingRulesPath == "" {
+		return nil, nil
+	}
+
+	rules, err := pricing.Load(cfg.FreightPricingRulesPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load pricing rules: %w", err)
+	}
+
+	slog.Info("pricing rules enabled", "path", cfg.FreightPricingRulesPath, "rules", rules.Len())
+
+	return rules, nil
+}
+
+// reloadPricingRules loads the rules again after a config reload or an edit
+// of the rules file, so that neither needs a restart. A file that fails to
+// load is logged and the current rules are kept.
+func reloadPricingRules(cfg *config.Config, quoteController *quote.QuoteController) {
+	rules, err := newPricingRules(cfg)
+	if err != nil {
+		slog.Error("pricing rules reload rejected, keeping current rules", "path", cfg.FreightPricingRulesPath, "error", err)
+		return
+	}
+
+	if rules == nil {
+		slog.Info("pricing rules disabled")
+	}
+
+	quoteController.SetPricingRules(rules)
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jeancarloshp/desafio-frete-rapido/internal/policy"
+	"github.com/jeancarloshp/desafio-frete-rapido/internal/quote"
+	"github.com/jeancarloshp/desafio-frete-rapido/internal/spreadsheet"
+	"github.com/jeancarloshp/desafio-frete-rapido/internal/tenant"
+	"github.com/jeancarloshp/desafio-frete-rapido/internal/webhook"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/config"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/database/querier"
+	fastdeliveryapi "github.com/jeancarloshp/desafio-frete-rapido/pkg/fastdelivery_api"
+)
+
+func quoteImport(args []string) int {
+	fs := commandFlags("quote import")
+	input := fs.String("input", "", "CSV file with the carts to quote")
+	output := fs.String("output", "-", "file to write the results to, - for stdout")
+	formatName := fs.String("format", "", "output format, csv or xlsx (default from the output extension)")
+	tenantName := fs.String("tenant", "", "quote with the credentials and quota of this tenant")
+
+	if ok, code := parseFlags(fs, args, "input"); !ok {
+		return code
+	}
+
+	if *formatName == "" {
+		*formatName = strings.TrimPrefix(filepath.Ext(*output), ".")
+		if *formatName != string(spreadsheet.FormatXLSX) {
+			*formatName = string(spreadsheet.FormatCSV)
+		}
+	}
+
+	format, err := spreadsheet.ParseFormat(*formatName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	return withDatabase(func(ctx context.Context, cfg *config.Config, db *pgxpool.Pool) error {
+		tenantController := newTenantController(db)
+		q := querier.New(db)
+		webhookController := webhook.NewWebhookController(cfg, webhook.NewWebhookRepository(q))
+		fallback, err := newFallback(cfg)
+		if err != nil {
+			return err
+		}
+		rules, err := newPricingRules(cfg)
+		if err != nil {
+			return err
+		}
+		deliveryCalendar, err := newDeliveryCalendar(cfg)
+		if err != nil {
+			return err
+		}
+		policyController := policy.NewPolicyController(policy.NewPolicyRepository(q))
+		quoteController, err := quote.NewQuoteController(cfg, quote.NewPostgresQuoteRepository(db), fastdeliveryapi.New(cfg), quote.QuoteOptions{
+			Fallback:  fallback,
+			Rules:     rules,
+			Policies:  policyController,
+			Calendar:  deliveryCalendar,
+			Publisher: webhookController,
+		})
+		if err != nil {
+			return err
+		}
+
+		if *tenantName != "" {
+			t, err := tenantController.FindTenantByName(ctx, *tenantName)
+			if err != nil {
+				return err
+			}
+			ctx = tenant.NewContext(ctx, t)
+		}
+
+		in, err := os.Open(*input)
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+
+		results, err := spreadsheet.NewSpreadsheetController(cfg, quoteController, tenantController).QuoteCSV(ctx, in)
+		if err != nil {
+			return err
+		}
+
+		var out io.Writer = os.Stdout
+		if *output != "-" {
+			f, err := os.Create(*output)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			out = f
+		}
+
+		if err := spreadsheet.Write(out, format, results); err != nil {
+			return err
+		}
+
+		failed := 0
+		for _, r := range results {
+			if r.Error != "" {
+				failed++
+			}
+		}
+
+		fmt.Fprintf(os.Stderr, "%d result rows written, %d with errors\n", len(results), failed)
+		return nil
+	})
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/jeancarloshp/desafio-frete-rapido/internal/quote"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/config"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/ratetable"
+)
+
+// newFallback loads the rate tables quotes fall back to when the Frete
+// Rápido API fails. It returns nil when no tables are configured.
+func newFallback(cfg *config.Config) (quote.FastDeliveryClient, error) {
+	if cfg.FreightRateTablesPath == "" {
+		return nil, nil
+	}
+
+	factors, err := cfg.CubageFactors()
+	if err != nil {
+		return nil, err
+	}
+
+	table, err := ratetable.Load(cfg.FreightRateTablesPath, factors)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load rate tables: %w", err)
+	}
+
+	slog.Info("rate table fallback enabled", "path", cfg.FreightRateTablesPath, "rates", table.Len())
+
+	return table, nil
+}
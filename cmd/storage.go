@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jeancarloshp/desafio-frete-rapido/internal/quote"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/config"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/database"
+)
+
+// storage holds what the selected STORAGE_BACKEND provides. postgres is nil
+// unless the backend is postgres, and database is nil for the memory backend.
+type storage struct {
+	postgres *pgxpool.Pool
+	quotes   quote.QuoteRepository
+	database *databaseCheck
+	close    func()
+}
+
+// databaseCheck feeds the readiness probe of a SQL backend.
+type databaseCheck struct {
+	ping          func(ctx context.Context) error
+	schemaVersion func(ctx context.Context) (int, error)
+	latestVersion int
+}
+
+func openStorage(ctx context.Context, cfg *config.Config) (*storage, error) {
+	switch cfg.StorageBackend {
+	case config.StoragePostgres:
+		pool, err := database.NewConnection(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to database: %w", err)
+		}
+
+		if err := database.Migrate(ctx, pool); err != nil {
+			pool.Close()
+			return nil, fmt.Errorf("failed to migrate database: %w", err)
+		}
+
+		return &storage{
+			postgres: pool,
+			database: &databaseCheck{
+				ping: pool.Ping,
+				schemaVersion: func(ctx context.Context) (int, error) {
+					return database.SchemaVersion(ctx, pool)
+				},
+				latestVersion: database.LatestSchemaVersion(),
+			},
+			close: func() {
+				pool.Close()
+				slog.Info("database connection pool closed")
+			},
+		}, nil
+
+	case config.StorageSQLite:
+		db, err := database.NewSQLiteConnection(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open sqlite database: %w", err)
+		}
+
+		if err := database.MigrateSQLite(ctx, db); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to migrate sqlite database: %w", err)
+		}
+
+		slog.Warn("using sqlite storage, tenants, batches, webhooks and watched routes are disabled", "path", cfg.SQLitePath)
+
+		return &storage{
+			quotes: quote.NewSQLiteQuoteRepository(db),
+			database: &databaseCheck{
+				ping: db.PingContext,
+				schemaVersion: func(ctx context.Context) (int, error) {
+					return database.SQLiteSchemaVersion(ctx, db)
+				},
+				latestVersion: database.LatestSQLiteSchemaVersion(),
+			},
+			close: func() {
+				db.Close()
+				slog.Info("sqlite database closed")
+			},
+		}, nil
+
+	default:
+		slog.Warn("using in-memory storage, quotes are lost on restart and tenants, batches, webhooks and watched routes are disabled")
+
+		return &storage{
+			quotes: quote.NewMemoryQuoteRepository(),
+			close:  func() {},
+		}, nil
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jeancarloshp/desafio-frete-rapido/internal/tenant"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/config"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/database/querier"
+)
+
+func newTenantController(db *pgxpool.Pool) *tenant.TenantController {
+	return tenant.NewTenantController(tenant.NewTenantRepository(querier.New(db)))
+}
+
+func tenantCreate(args []string) int {
+	fs := commandFlags("tenant create")
+	name := fs.String("name", "", "unique tenant name")
+	cnpj := fs.String("cnpj", "", "shipper CNPJ (14 digits)")
+	token := fs.String("token", "", "Frete Rápido token of the shipper")
+	platformCode := fs.String("platform-code", "", "Frete Rápido platform code")
+	zipcode := fs.Int("zipcode", 0, "origin zipcode of the dispatcher")
+	monthlyQuota := fs.Int("monthly-quota", 0, "maximum quotes per month, 0 for unlimited")
+
+	if ok, code := parseFlags(fs, args, "name", "cnpj", "token", "platform-code", "zipcode"); !ok {
+		return code
+	}
+
+	t := tenant.Tenant{
+		Name:          *name,
+		ShipperCNPJ:   *cnpj,
+		ShipperToken:  *token,
+		PlatformCode:  *platformCode,
+		OriginZipCode: *zipcode,
+	}
+	if *monthlyQuota > 0 {
+		t.MonthlyQuota = monthlyQuota
+	}
+
+	return withDatabase(func(ctx context.Context, _ *config.Config, db *pgxpool.Pool) error {
+		t, err := newTenantController(db).CreateTenant(ctx, t)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("tenant %q created with id %d\n", t.Name, t.ID)
+		return nil
+	})
+}
+
+func apiKeyCreate(args []string) int {
+	fs := commandFlags("apikey create")
+	tenantName := fs.String("tenant", "", "name of the tenant that owns the key")
+
+	if ok, code := parseFlags(fs, args, "tenant"); !ok {
+		return code
+	}
+
+	return withDatabase(func(ctx context.Context, _ *config.Config, db *pgxpool.Pool) error {
+		key, err := newTenantController(db).IssueAPIKey(ctx, *tenantName)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("api key for tenant %q (prefix %s), store it now, it will not be shown again:\n%s\n", *tenantName, key.Prefix, key.Key)
+		return nil
+	})
+}
+
+func apiKeyRevoke(args []string) int {
+	fs := commandFlags("apikey revoke")
+	prefix := fs.String("prefix", "", "prefix of the api key to revoke")
+
+	if ok, code := parseFlags(fs, args, "prefix"); !ok {
+		return code
+	}
+
+	return withDatabase(func(ctx context.Context, _ *config.Config, db *pgxpool.Pool) error {
+		if err := newTenantController(db).RevokeAPIKey(ctx, *prefix); err != nil {
+			return err
+		}
+
+		fmt.Printf("api key %s revoked\n", *prefix)
+		return nil
+	})
+}
@@ -0,0 +1,154 @@
+package batch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/jeancarloshp/desafio-frete-rapido/internal/quote"
+	"github.com/jeancarloshp/desafio-frete-rapido/internal/tenant"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/config"
+)
+
+var ErrTooManyItems = errors.New("too many quote requests in batch")
+
+type BatchController struct {
+	cfg              *config.Config
+	batchRepository  *BatchRepository
+	quoteController  *quote.QuoteController
+	tenantController *tenant.TenantController
+}
+
+func NewBatchController(cfg *config.Config, batchRepository *BatchRepository, quoteController *quote.QuoteController, tenantController *tenant.TenantController) *BatchController {
+	return &BatchController{
+		cfg:              cfg,
+		batchRepository:  batchRepository,
+		quoteController:  quoteController,
+		tenantController: tenantController,
+	}
+}
+
+func (bc *BatchController) CreateBatch(ctx context.Context, batchRequest BatchRequest) (Batch, error) {
+	if len(batchRequest.Requests) > bc.cfg.BatchMaxItems {
+		return Batch{}, fmt.Errorf("%w: got %d, the limit is %d", ErrTooManyItems, len(batchRequest.Requests), bc.cfg.BatchMaxItems)
+	}
+
+	var tenantID *int
+	if t, ok := tenant.FromContext(ctx); ok {
+		tenantID = &t.ID
+	}
+
+	return bc.batchRepository.CreateBatch(ctx, tenantID, batchRequest.Requests)
+}
+
+// FindBatch only returns batches created by the tenant in ctx, so that batch
+// ids cannot be used to read other tenants' results.
+func (bc *BatchController) FindBatch(ctx context.Context, id int) (Batch, error) {
+	batch, err := bc.batchRepository.FindBatch(ctx, id)
+	if err != nil {
+		return Batch{}, err
+	}
+
+	t, ok := tenant.FromContext(ctx)
+	if ok && (batch.TenantID == nil || *batch.TenantID != t.ID) {
+		return Batch{}, ErrNotFound
+	}
+
+	return batch, nil
+}
+
+// ProcessNext claims one pending item, quotes it and stores the outcome. It
+// reports false when the queue is empty.
+func (bc *BatchController) ProcessNext(ctx context.Context) (bool, error) {
+	item, err := bc.batchRepository.ClaimItem(ctx)
+	if errors.Is(err, errNoPendingItems) {
+		return false, nil
+	}
+	// An item whose request cannot be decoded is still claimed and is
+	// recorded as failed below.
+	if err != nil && item.ID == 0 {
+		return false, err
+	}
+
+	var result *quote.QuoteResponse
+	if err == nil {
+		result, err = bc.quote(ctx, item)
+	}
+
+	if err != nil {
+		slog.Warn("quote batch item failed", "batch_id", item.BatchID, "item_id", item.ID, "error", err)
+	}
+
+	err = bc.batchRepository.CompleteItem(ctx, item, result, err)
+	// The item was taken back while this worker quoted it; the outcome of the
+	// newer claim wins.
+	if errors.Is(err, errItemReclaimed) {
+		slog.Warn("quote batch item was claimed again, discarding result", "batch_id", item.BatchID, "item_id", item.ID, "attempts", item.Attempts)
+		return true, nil
+	}
+	if err != nil {
+		return true, err
+	}
+
+	return true, bc.batchRepository.FinishBatch(ctx, item.BatchID)
+}
+
+// RequeueStaleItems takes back the items abandoned by crashed instances. Each
+// is queued again until it was claimed maxItemAttempts times, and failed after
+// that, so a request that keeps crashing workers cannot loop forever. It
+// returns how many items were requeued and failed.
+func (bc *BatchController) RequeueStaleItems(ctx context.Context) (int, int, error) {
+	items, err := bc.batchRepository.RequeueStaleItems(ctx, staleItemAfter, maxItemAttempts)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var requeued, failed int
+	finished := make(map[int]bool)
+	for _, item := range items {
+		if item.Status != ItemFailed {
+			requeued++
+			continue
+		}
+
+		failed++
+		if finished[item.BatchID] {
+			continue
+		}
+		finished[item.BatchID] = true
+
+		if err := bc.batchRepository.FinishBatch(ctx, item.BatchID); err != nil {
+			return requeued, failed, err
+		}
+	}
+
+	return requeued, failed, nil
+}
+
+func (bc *BatchController) quote(ctx context.Context, item claimedItem) (*quote.QuoteResponse, error) {
+	if item.TenantID != nil {
+		t, err := bc.tenantController.FindTenant(ctx, *item.TenantID)
+		if err != nil {
+			return nil, err
+		}
+
+		ctx = tenant.NewContext(ctx, t)
+	}
+
+	usage, err := bc.tenantController.ConsumeQuote(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := bc.quoteController.SimulateQuote(ctx, item.Request)
+	if err != nil {
+		if refundErr := bc.tenantController.RefundQuote(ctx, usage); refundErr != nil {
+			slog.Error("failed to refund quote quota", "tenant", usage.TenantName, "error", refundErr)
+		}
+
+		return nil, err
+	}
+
+	return response, nil
+}
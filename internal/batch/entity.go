@@ -0,0 +1,66 @@
+package batch
+
+import (
+	"time"
+
+	"github.com/jeancarloshp/desafio-frete-rapido/internal/quote"
+)
+
+type Status string
+
+const (
+	StatusPending    Status = "pending"
+	StatusProcessing Status = "processing"
+	StatusCompleted  Status = "completed"
+)
+
+type ItemStatus string
+
+const (
+	ItemPending    ItemStatus = "pending"
+	ItemProcessing ItemStatus = "processing"
+	ItemSucceeded  ItemStatus = "succeeded"
+	ItemFailed     ItemStatus = "failed"
+)
+
+type BatchRequest struct {
+	Requests []quote.QuoteRequest `json:"requests" validate:"required,min=1,dive"`
+}
+
+type Batch struct {
+	ID          int        `json:"id"`
+	TenantID    *int       `json:"-"`
+	Status      Status     `json:"status"`
+	TotalItems  int        `json:"total_items"`
+	Pending     int        `json:"pending_items"`
+	Succeeded   int        `json:"succeeded_items"`
+	Failed      int        `json:"failed_items"`
+	CreatedAt   time.Time  `json:"created_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	Items       []Item     `json:"items,omitempty"`
+}
+
+type Item struct {
+	Position int                  `json:"position"`
+	Status   ItemStatus           `json:"status"`
+	Attempts int                  `json:"attempts"`
+	Result   *quote.QuoteResponse `json:"result,omitempty"`
+	Error    string               `json:"error,omitempty"`
+}
+
+// claimedItem is a queued request taken by a worker.
+type claimedItem struct {
+	ID       int
+	BatchID  int
+	Attempts int
+	TenantID *int
+	Request  quote.QuoteRequest
+}
+
+// staleItem is an item taken back from a worker that stopped processing it,
+// either queued again or failed.
+type staleItem struct {
+	ID      int
+	BatchID int
+	Status  ItemStatus
+}
@@ -0,0 +1,80 @@
+package batch
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type BatchHandler struct {
+	batchController *BatchController
+}
+
+func NewBatchHandler(batchController *BatchController) *BatchHandler {
+	handler := &BatchHandler{
+		batchController: batchController,
+	}
+
+	return handler
+}
+
+func (bh *BatchHandler) CreateBatchHandler(c *fiber.Ctx) error {
+	var batchRequest BatchRequest
+	if err := c.BodyParser(&batchRequest); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	v := validator.New()
+	if err := v.Struct(batchRequest); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "validation failed",
+			"details": err.Error(),
+		})
+	}
+
+	batch, err := bh.batchController.CreateBatch(c.UserContext(), batchRequest)
+	if errors.Is(err, ErrTooManyItems) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "validation failed",
+			"details": err.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "failed to create quote batch",
+			"details": err.Error(),
+		})
+	}
+
+	c.Location(c.Path() + "/" + strconv.Itoa(batch.ID))
+
+	return c.Status(fiber.StatusAccepted).JSON(batch)
+}
+
+func (bh *BatchHandler) BatchHandler(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "id must be a positive integer",
+		})
+	}
+
+	batch, err := bh.batchController.FindBatch(c.UserContext(), id)
+	if errors.Is(err, ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "quote batch not found",
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "failed to retrieve quote batch",
+			"details": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(batch)
+}
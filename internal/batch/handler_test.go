@@ -0,0 +1,70 @@
+package batch_test
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jeancarloshp/desafio-frete-rapido/internal/batch"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/config"
+)
+
+const quoteRequestJSON = `{"recipient":{"address":{"zipcode":"01311000"}},"volumes":[{"category":7,"amount":1,"unitary_weight":5,"price":349,"sku":"abc-teste-123","height":0.2,"width":0.2,"length":0.2}]}`
+
+func newTestApp(maxItems int) *fiber.App {
+	cfg := &config.Config{BatchMaxItems: maxItems}
+	handler := batch.NewBatchHandler(batch.NewBatchController(cfg, nil, nil, nil))
+
+	app := fiber.New()
+	app.Post("/v1/quote-batches", handler.CreateBatchHandler)
+	app.Get("/v1/quote-batches/:id", handler.BatchHandler)
+
+	return app
+}
+
+func TestCreateBatchHandler_RejectsInvalidRequests(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{name: "invalid json", body: `{"requests":`},
+		{name: "no requests", body: `{"requests":[]}`},
+		{name: "invalid item", body: `{"requests":[` + quoteRequestJSON + `,{"recipient":{"address":{"zipcode":"123"}}}]}`},
+		{name: "too many items", body: `{"requests":[` + quoteRequestJSON + `,` + quoteRequestJSON + `,` + quoteRequestJSON + `]}`},
+	}
+
+	app := newTestApp(2)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/v1/quote-batches", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			// Lotes inválidos devem ser rejeitados antes de qualquer acesso ao banco
+			if resp.StatusCode != fiber.StatusBadRequest {
+				t.Errorf("Expected status 400, got: %d", resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestBatchHandler_RejectsInvalidID(t *testing.T) {
+	app := newTestApp(2)
+
+	for _, id := range []string{"abc", "0", "-1"} {
+		resp, err := app.Test(httptest.NewRequest("GET", "/v1/quote-batches/"+id, nil))
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+
+		if resp.StatusCode != fiber.StatusBadRequest {
+			t.Errorf("Expected status 400 for id %q, got: %d", id, resp.StatusCode)
+		}
+	}
+}
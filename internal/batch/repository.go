@@ -0,0 +1,256 @@
+package batch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jeancarloshp/desafio-frete-rapido/internal/quote"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/database/querier"
+)
+
+var (
+	ErrNotFound       = errors.New("quote batch not found")
+	errNoPendingItems = errors.New("no pending quote batch items")
+	errItemReclaimed  = errors.New("quote batch item was claimed again")
+)
+
+type BatchRepository struct {
+	db   *pgxpool.Pool
+	conn *querier.Queries
+}
+
+func NewBatchRepository(db *pgxpool.Pool) *BatchRepository {
+	return &BatchRepository{
+		db:   db,
+		conn: querier.New(db),
+	}
+}
+
+// CreateBatch stores the batch and queues its items in one transaction, so
+// workers never see a batch with only part of its items.
+func (r *BatchRepository) CreateBatch(ctx context.Context, tenantID *int, requests []quote.QuoteRequest) (Batch, error) {
+	items := make([]querier.CreateQuoteBatchItemsParams, len(requests))
+	for i, req := range requests {
+		body, err := json.Marshal(req)
+		if err != nil {
+			return Batch{}, fmt.Errorf("failed to marshal quote request: %w", err)
+		}
+
+		items[i] = querier.CreateQuoteBatchItemsParams{
+			Position: i,
+			Request:  body,
+		}
+	}
+
+	var created querier.QuoteBatch
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		conn := r.conn.WithTx(tx)
+
+		var err error
+		created, err = conn.CreateQuoteBatch(ctx, querier.CreateQuoteBatchParams{
+			TenantID:   tenantID,
+			TotalItems: len(requests),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create quote batch: %w", err)
+		}
+
+		for i := range items {
+			items[i].BatchID = int(created.ID)
+		}
+
+		if _, err := conn.CreateQuoteBatchItems(ctx, items); err != nil {
+			return fmt.Errorf("failed to queue quote batch items: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return Batch{}, err
+	}
+
+	return Batch{
+		ID:         int(created.ID),
+		TenantID:   created.TenantID,
+		Status:     StatusPending,
+		TotalItems: created.TotalItems,
+		Pending:    created.TotalItems,
+		CreatedAt:  created.CreatedAt.Time,
+	}, nil
+}
+
+func (r *BatchRepository) FindBatch(ctx context.Context, id int) (Batch, error) {
+	row, err := r.conn.FindQuoteBatch(ctx, int32(id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Batch{}, ErrNotFound
+	}
+	if err != nil {
+		return Batch{}, fmt.Errorf("failed to find quote batch: %w", err)
+	}
+
+	items, err := r.conn.ListQuoteBatchItems(ctx, id)
+	if err != nil {
+		return Batch{}, fmt.Errorf("failed to list quote batch items: %w", err)
+	}
+
+	batch := Batch{
+		ID:         int(row.ID),
+		TenantID:   row.TenantID,
+		TotalItems: row.TotalItems,
+		Pending:    row.PendingItems,
+		Succeeded:  row.SucceededItems,
+		Failed:     row.FailedItems,
+		CreatedAt:  row.CreatedAt.Time,
+		Items:      make([]Item, len(items)),
+	}
+
+	if row.CompletedAt.Valid {
+		batch.CompletedAt = &row.CompletedAt.Time
+	}
+
+	for i, it := range items {
+		batch.Items[i], err = toItem(it)
+		if err != nil {
+			return Batch{}, err
+		}
+	}
+	batch.Status = batchStatus(batch.Items)
+
+	return batch, nil
+}
+
+func (r *BatchRepository) ClaimItem(ctx context.Context) (claimedItem, error) {
+	row, err := r.conn.ClaimQuoteBatchItem(ctx)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return claimedItem{}, errNoPendingItems
+	}
+	if err != nil {
+		return claimedItem{}, fmt.Errorf("failed to claim quote batch item: %w", err)
+	}
+
+	item := claimedItem{
+		ID:       int(row.ID),
+		BatchID:  row.BatchID,
+		Attempts: row.Attempts,
+		TenantID: row.TenantID,
+	}
+
+	if err := json.Unmarshal(row.Request, &item.Request); err != nil {
+		return item, fmt.Errorf("failed to unmarshal quote request: %w", err)
+	}
+
+	return item, nil
+}
+
+// CompleteItem stores the outcome of a claim. It only updates the item while
+// that claim still holds it: once the item was requeued as stale and claimed
+// again, or failed, it returns errItemReclaimed and leaves the item alone.
+func (r *BatchRepository) CompleteItem(ctx context.Context, item claimedItem, result *quote.QuoteResponse, itemErr error) error {
+	params := querier.CompleteQuoteBatchItemParams{
+		ID:       int32(item.ID),
+		Attempts: item.Attempts,
+		Status:   string(ItemSucceeded),
+	}
+
+	if itemErr != nil {
+		message := itemErr.Error()
+		params.Status = string(ItemFailed)
+		params.Error = &message
+	} else {
+		body, err := json.Marshal(result)
+		if err != nil {
+			return fmt.Errorf("failed to marshal quote response: %w", err)
+		}
+		params.Response = body
+	}
+
+	rows, err := r.conn.CompleteQuoteBatchItem(ctx, params)
+	if err != nil {
+		return fmt.Errorf("failed to complete quote batch item: %w", err)
+	}
+
+	if rows == 0 {
+		return errItemReclaimed
+	}
+
+	return nil
+}
+
+func (r *BatchRepository) FinishBatch(ctx context.Context, id int) error {
+	if err := r.conn.FinishQuoteBatch(ctx, int32(id)); err != nil {
+		return fmt.Errorf("failed to finish quote batch: %w", err)
+	}
+
+	return nil
+}
+
+// RequeueStaleItems queues the items stuck in processing again, or marks them
+// failed once they were claimed maxAttempts times, and returns what happened
+// to each of them.
+func (r *BatchRepository) RequeueStaleItems(ctx context.Context, staleAfter time.Duration, maxAttempts int) ([]staleItem, error) {
+	rows, err := r.conn.RequeueStaleQuoteBatchItems(ctx, querier.RequeueStaleQuoteBatchItemsParams{
+		MaxAttempts:       maxAttempts,
+		StaleAfterSeconds: int(staleAfter.Seconds()),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to requeue stale quote batch items: %w", err)
+	}
+
+	items := make([]staleItem, len(rows))
+	for i, row := range rows {
+		items[i] = staleItem{
+			ID:      int(row.ID),
+			BatchID: row.BatchID,
+			Status:  ItemStatus(row.Status),
+		}
+	}
+
+	return items, nil
+}
+
+func toItem(it querier.QuoteBatchItem) (Item, error) {
+	item := Item{
+		Position: it.Position,
+		Status:   ItemStatus(it.Status),
+		Attempts: it.Attempts,
+	}
+
+	if it.Error != nil {
+		item.Error = *it.Error
+	}
+
+	if it.Response != nil {
+		item.Result = &quote.QuoteResponse{}
+		if err := json.Unmarshal(it.Response, item.Result); err != nil {
+			return Item{}, fmt.Errorf("failed to unmarshal quote response: %w", err)
+		}
+	}
+
+	return item, nil
+}
+
+func batchStatus(items []Item) Status {
+	var pending, finished int
+	for _, it := range items {
+		switch it.Status {
+		case ItemPending:
+			pending++
+		case ItemSucceeded, ItemFailed:
+			finished++
+		}
+	}
+
+	switch {
+	case finished == len(items):
+		return StatusCompleted
+	case pending == len(items):
+		return StatusPending
+	default:
+		return StatusProcessing
+	}
+}
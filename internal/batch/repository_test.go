@@ -0,0 +1,173 @@
+package batch_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jeancarloshp/desafio-frete-rapido/internal/batch"
+	"github.com/jeancarloshp/desafio-frete-rapido/internal/quote"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/config"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/database/databasetest"
+)
+
+func quoteRequests(t *testing.T, n int) []quote.QuoteRequest {
+	t.Helper()
+
+	var req quote.QuoteRequest
+	if err := json.Unmarshal([]byte(quoteRequestJSON), &req); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	requests := make([]quote.QuoteRequest, n)
+	for i := range requests {
+		requests[i] = req
+	}
+
+	return requests
+}
+
+// Se os itens não puderem ser enfileirados, o lote também não é criado
+func TestBatchRepository_CreateBatchIsAtomic(t *testing.T) {
+	ctx := context.Background()
+	pool := databasetest.Migrated(t)
+	repository := batch.NewBatchRepository(pool)
+
+	created, err := repository.CreateBatch(ctx, nil, quoteRequests(t, 3))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	found, err := repository.FindBatch(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if found.TotalItems != 3 || len(found.Items) != 3 || found.Status != batch.StatusPending {
+		t.Errorf("Expected a pending batch with 3 items, got: %+v", found)
+	}
+
+	if _, err := pool.Exec(ctx, "DROP TABLE quote_batch_items"); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if _, err := repository.CreateBatch(ctx, nil, quoteRequests(t, 2)); err == nil {
+		t.Fatal("Expected an error queueing the items")
+	}
+
+	var batches int
+	if err := pool.QueryRow(ctx, "SELECT COUNT(*) FROM quote_batches").Scan(&batches); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if batches != 1 {
+		t.Errorf("Expected only the first batch to be stored, got: %d", batches)
+	}
+}
+
+// Um item abandonado volta para a fila até a terceira tentativa e depois é
+// marcado como falho, fechando o lote
+func TestRequeueStaleItems_FailsAfterMaxAttempts(t *testing.T) {
+	ctx := context.Background()
+	pool := databasetest.Migrated(t)
+	repository := batch.NewBatchRepository(pool)
+	controller := batch.NewBatchController(&config.Config{}, repository, nil, nil)
+
+	created, err := repository.CreateBatch(ctx, nil, quoteRequests(t, 1))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	for attempt := 1; attempt <= 3; attempt++ {
+		if _, err := repository.ClaimItem(ctx); err != nil {
+			t.Fatalf("Expected attempt %d to claim the item, got: %v", attempt, err)
+		}
+
+		// A instância caiu no meio do processamento
+		if _, err := pool.Exec(ctx, "UPDATE quote_batch_items SET updated_at = now() - interval '1 hour'"); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+
+		requeued, failed, err := controller.RequeueStaleItems(ctx)
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+
+		wantRequeued, wantFailed := 1, 0
+		if attempt == 3 {
+			wantRequeued, wantFailed = 0, 1
+		}
+
+		if requeued != wantRequeued || failed != wantFailed {
+			t.Errorf("Expected attempt %d to requeue %d and fail %d, got: %d and %d", attempt, wantRequeued, wantFailed, requeued, failed)
+		}
+	}
+
+	found, err := repository.FindBatch(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if found.Status != batch.StatusCompleted || found.CompletedAt == nil || found.Failed != 1 {
+		t.Errorf("Expected a completed batch with one failed item, got: %+v", found)
+	}
+
+	if item := found.Items[0]; item.Attempts != 3 || item.Error != "abandoned after 3 attempts" {
+		t.Errorf("Expected the item to be abandoned after 3 attempts, got: %+v", item)
+	}
+}
+
+// Um worker lento não sobrescreve o item depois que ele foi devolvido à fila e
+// reivindicado de novo
+func TestCompleteItem_IgnoresStaleClaims(t *testing.T) {
+	ctx := context.Background()
+	pool := databasetest.Migrated(t)
+	repository := batch.NewBatchRepository(pool)
+
+	created, err := repository.CreateBatch(ctx, nil, quoteRequests(t, 1))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	first, err := repository.ClaimItem(ctx)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if _, err := pool.Exec(ctx, "UPDATE quote_batch_items SET updated_at = now() - interval '1 hour'"); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if _, err := repository.RequeueStaleItems(ctx, time.Minute, 3); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	second, err := repository.ClaimItem(ctx)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if err := repository.CompleteItem(ctx, first, nil, errors.New("upstream timeout")); err == nil {
+		t.Fatal("Expected the stale claim to be rejected")
+	}
+
+	if err := repository.CompleteItem(ctx, second, &quote.QuoteResponse{}, nil); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	// O item já foi concluído, então a primeira reivindicação continua sem efeito
+	if err := repository.CompleteItem(ctx, first, nil, errors.New("upstream timeout")); err == nil {
+		t.Fatal("Expected the stale claim to be rejected")
+	}
+
+	found, err := repository.FindBatch(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if item := found.Items[0]; item.Status != batch.ItemSucceeded || item.Attempts != 2 || item.Error != "" {
+		t.Errorf("Expected the second claim to succeed, got: %+v", item)
+	}
+}
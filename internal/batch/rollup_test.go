@@ -0,0 +1,30 @@
+package batch
+
+import "testing"
+
+func TestBatchStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []ItemStatus
+		want     Status
+	}{
+		{name: "todos pendentes", statuses: []ItemStatus{ItemPending, ItemPending}, want: StatusPending},
+		{name: "um em processamento", statuses: []ItemStatus{ItemProcessing, ItemPending}, want: StatusProcessing},
+		{name: "parte concluída", statuses: []ItemStatus{ItemSucceeded, ItemPending}, want: StatusProcessing},
+		{name: "concluído com falhas", statuses: []ItemStatus{ItemSucceeded, ItemFailed}, want: StatusCompleted},
+		{name: "todos falharam", statuses: []ItemStatus{ItemFailed, ItemFailed}, want: StatusCompleted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items := make([]Item, len(tt.statuses))
+			for i, status := range tt.statuses {
+				items[i].Status = status
+			}
+
+			if got := batchStatus(items); got != tt.want {
+				t.Errorf("Expected status %s, got: %s", tt.want, got)
+			}
+		})
+	}
+}
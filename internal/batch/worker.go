@@ -0,0 +1,76 @@
+package batch
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+const (
+	// staleItemAfter is how long an item may stay in processing before it is
+	// considered abandoned by a crashed instance and queued again.
+	staleItemAfter = 5 * time.Minute
+	// maxItemAttempts is how many times an item may be claimed before a stale
+	// item is failed instead of queued again.
+	maxItemAttempts = 3
+	requeueEvery    = time.Minute
+)
+
+type Worker struct {
+	batchController *BatchController
+	pollInterval    time.Duration
+}
+
+func NewWorker(batchController *BatchController, pollInterval time.Duration) *Worker {
+	return &Worker{
+		batchController: batchController,
+		pollInterval:    pollInterval,
+	}
+}
+
+// Run processes queued items until ctx is cancelled. The item being processed
+// when that happens is allowed to finish.
+func (w *Worker) Run(ctx context.Context) error {
+	for {
+		processed, err := w.batchController.ProcessNext(context.WithoutCancel(ctx))
+		if err != nil {
+			slog.Error("failed to process quote batch item", "error", err)
+		}
+
+		if processed && err == nil && ctx.Err() == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(w.pollInterval):
+		}
+	}
+}
+
+func (w *Worker) RequeueStale(ctx context.Context) error {
+	ticker := time.NewTicker(requeueEvery)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			requeued, failed, err := w.batchController.RequeueStaleItems(ctx)
+			if err != nil {
+				slog.Error("failed to requeue stale quote batch items", "error", err)
+				continue
+			}
+
+			if requeued > 0 {
+				slog.Warn("requeued stale quote batch items", "items", requeued)
+			}
+
+			if failed > 0 {
+				slog.Warn("failed quote batch items abandoned too many times", "items", failed, "max_attempts", maxItemAttempts)
+			}
+		}
+	}
+}
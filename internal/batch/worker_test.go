@@ -0,0 +1,102 @@
+package batch_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jeancarloshp/desafio-frete-rapido/internal/batch"
+	"github.com/jeancarloshp/desafio-frete-rapido/internal/quote"
+	"github.com/jeancarloshp/desafio-frete-rapido/internal/quote/quotetest"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/config"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/database/databasetest"
+)
+
+// O worker reserva cada item, cota e grava o resultado; o lote só é concluído
+// quando o último item termina
+func TestWorker_ProcessesBatch(t *testing.T) {
+	ctx := context.Background()
+	repository := batch.NewBatchRepository(databasetest.Migrated(t))
+
+	client := &quotetest.Client{Response: quotetest.Response(quotetest.Offer("CORREIOS", "PAC", "25.50", 5))}
+	quoteController, err := quote.NewQuoteController(&config.Config{}, &quotetest.Repository{}, client, quote.QuoteOptions{})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	controller := batch.NewBatchController(&config.Config{}, repository, quoteController, nil)
+
+	requests := quoteRequests(t, 3)
+	// CEP inválido faz a cotação falhar sem derrubar o lote
+	requests[1].Recipient.Address.ZipCode = "abc"
+
+	created, err := repository.CreateBatch(ctx, nil, requests)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	processed, err := controller.ProcessNext(ctx)
+	if err != nil || !processed {
+		t.Fatalf("Expected an item to be processed, got: %v (%v)", processed, err)
+	}
+
+	found, err := controller.FindBatch(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if found.Status != batch.StatusProcessing || found.Pending != 2 || found.CompletedAt != nil {
+		t.Errorf("Expected a processing batch with 2 pending items, got: %+v", found)
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	done := make(chan error)
+	go func() { done <- batch.NewWorker(controller, 10*time.Millisecond).Run(runCtx) }()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for found.Status != batch.StatusCompleted && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+
+		found, err = controller.FindBatch(ctx, created.ID)
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("Expected the worker to stop cleanly, got: %v", err)
+	}
+
+	if found.Status != batch.StatusCompleted || found.CompletedAt == nil {
+		t.Fatalf("Expected the batch to be completed, got: %+v", found)
+	}
+
+	if found.Succeeded != 2 || found.Failed != 1 || found.Pending != 0 {
+		t.Errorf("Expected 2 succeeded and 1 failed items, got: %+v", found)
+	}
+
+	for _, item := range found.Items {
+		if item.Attempts != 1 {
+			t.Errorf("Expected item %d to be claimed once, got: %d", item.Position, item.Attempts)
+		}
+
+		if item.Position == 1 {
+			if item.Status != batch.ItemFailed || item.Error == "" || item.Result != nil {
+				t.Errorf("Expected item 1 to fail, got: %+v", item)
+			}
+			continue
+		}
+
+		if item.Status != batch.ItemSucceeded || item.Error != "" || item.Result == nil {
+			t.Errorf("Expected item %d to succeed, got: %+v", item.Position, item)
+		}
+	}
+
+	if processed, err := controller.ProcessNext(ctx); err != nil || processed {
+		t.Errorf("Expected an empty queue, got: %v (%v)", processed, err)
+	}
+
+	if len(client.Requests) != 2 {
+		t.Errorf("Expected 2 upstream quotes, got: %d", len(client.Requests))
+	}
+}
@@ -0,0 +1,115 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/cep"
+)
+
+var (
+	ErrInvalidUF           = errors.New("uf must be a Brazilian state, such as SP")
+	ErrInvalidZipCodeRange = errors.New("zipcode_end must not be lower than zipcode_start")
+)
+
+// cacheTTL bounds how long the cached policies are served. Changes made
+// through this instance drop the cache at once; the TTL lets the other
+// instances pick them up.
+const cacheTTL = 30 * time.Second
+
+type PolicyController struct {
+	policyRepository *PolicyRepository
+	now              func() time.Time
+
+	mu sync.Mutex
+	// generation is bumped on every change, so a list that started before
+	// it is not cached over the newer policies
+	generation uint64
+	cached     []Policy
+	cachedAt   time.Time
+}
+
+func NewPolicyController(policyRepository *PolicyRepository) *PolicyController {
+	return &PolicyController{
+		policyRepository: policyRepository,
+		now:              time.Now,
+	}
+}
+
+func (pc *PolicyController) CreatePolicy(ctx context.Context, req PolicyRequest) (Policy, error) {
+	if req.UF != "" && !cep.ValidUF(req.UF) {
+		return Policy{}, ErrInvalidUF
+	}
+
+	if req.ZipCodeStart != 0 && req.ZipCodeEnd != 0 && req.ZipCodeEnd < req.ZipCodeStart {
+		return Policy{}, ErrInvalidZipCodeRange
+	}
+
+	created, err := pc.policyRepository.CreatePolicy(ctx, Policy{
+		Name:         req.Name,
+		Action:       req.Action,
+		Carrier:      strings.TrimSpace(req.Carrier),
+		Service:      strings.TrimSpace(req.Service),
+		UF:           strings.ToUpper(req.UF),
+		ZipCodeStart: req.ZipCodeStart,
+		ZipCodeEnd:   req.ZipCodeEnd,
+		Category:     req.Category,
+	})
+	if err != nil {
+		return Policy{}, err
+	}
+
+	pc.invalidate()
+
+	return created, nil
+}
+
+// ListPolicies returns every policy in the order they were created. Quotes
+// call it once per simulation, so it serves a cached copy for up to cacheTTL;
+// changes made through this controller apply to the next quote. The returned
+// slice is shared and must not be modified.
+func (pc *PolicyController) ListPolicies(ctx context.Context) ([]Policy, error) {
+	pc.mu.Lock()
+	if pc.cached != nil && pc.now().Sub(pc.cachedAt) < cacheTTL {
+		cached := pc.cached
+		pc.mu.Unlock()
+		return cached, nil
+	}
+	generation := pc.generation
+	pc.mu.Unlock()
+
+	policies, err := pc.policyRepository.ListPolicies(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	pc.mu.Lock()
+	if pc.generation == generation {
+		pc.cached = policies
+		pc.cachedAt = pc.now()
+	}
+	pc.mu.Unlock()
+
+	return policies, nil
+}
+
+func (pc *PolicyController) DeletePolicy(ctx context.Context, id int) error {
+	if err := pc.policyRepository.DeletePolicy(ctx, id); err != nil {
+		return err
+	}
+
+	pc.invalidate()
+
+	return nil
+}
+
+// invalidate drops the cached policies after a change
+func (pc *PolicyController) invalidate() {
+	pc.mu.Lock()
+	pc.generation++
+	pc.cached = nil
+	pc.mu.Unlock()
+}
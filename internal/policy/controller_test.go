@@ -0,0 +1,58 @@
+package policy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/database/databasetest"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/database/querier"
+)
+
+// As cotações leem as políticas do cache; mudanças feitas pelo controller o
+// invalidam na hora e as feitas por outra instância valem após cacheTTL
+func TestListPolicies_CachesUntilChanged(t *testing.T) {
+	ctx := context.Background()
+	pc := NewPolicyController(NewPolicyRepository(querier.New(databasetest.Migrated(t))))
+
+	now := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+	pc.now = func() time.Time { return now }
+
+	list := func(want int) {
+		t.Helper()
+
+		policies, err := pc.ListPolicies(ctx)
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+
+		if len(policies) != want {
+			t.Errorf("Expected %d policies, got: %d", want, len(policies))
+		}
+	}
+
+	list(0)
+
+	created, err := pc.CreatePolicy(ctx, PolicyRequest{Name: "norte", Action: ActionHide, Carrier: "JADLOG", UF: "am"})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	list(1)
+
+	// Outra instância cria uma política sem passar por este controller
+	if _, err := pc.policyRepository.CreatePolicy(ctx, Policy{Name: "sul", Action: ActionHide, Carrier: "CORREIOS"}); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	list(1)
+
+	now = now.Add(cacheTTL)
+	list(2)
+
+	if err := pc.DeletePolicy(ctx, created.ID); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	list(1)
+}
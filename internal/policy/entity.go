@@ -0,0 +1,99 @@
+package policy
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+type Action string
+
+const (
+	// ActionHide removes matching offers from quotes.
+	ActionHide Action = "hide"
+	// ActionDeprioritize moves matching offers after all the others.
+	ActionDeprioritize Action = "deprioritize"
+	// ActionAllow exempts matching offers from hide and deprioritize
+	// policies, such as one service of a hidden carrier.
+	ActionAllow Action = "allow"
+)
+
+// PolicyRequest targets a carrier, a service or both. Empty conditions match
+// every destination and product.
+type PolicyRequest struct {
+	Name         string `json:"name" validate:"required,max=255"`
+	Action       Action `json:"action" validate:"required,oneof=hide deprioritize allow"`
+	Carrier      string `json:"carrier" validate:"required_without=Service,max=255"`
+	Service      string `json:"service" validate:"max=255"`
+	UF           string `json:"uf" validate:"omitempty,len=2"`
+	ZipCodeStart int    `json:"zipcode_start" validate:"omitempty,min=1000000,max=99999999"`
+	ZipCodeEnd   int    `json:"zipcode_end" validate:"omitempty,min=1000000,max=99999999"`
+	Category     int    `json:"category" validate:"omitempty,min=1"`
+}
+
+type Policy struct {
+	ID           int       `json:"id"`
+	Name         string    `json:"name"`
+	Action       Action    `json:"action"`
+	Carrier      string    `json:"carrier,omitempty"`
+	Service      string    `json:"service,omitempty"`
+	UF           string    `json:"uf,omitempty"`
+	ZipCodeStart int       `json:"zipcode_start,omitempty"`
+	ZipCodeEnd   int       `json:"zipcode_end,omitempty"`
+	Category     int       `json:"category,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// Target is one offer of a quote and where it is going.
+type Target struct {
+	Carrier    string
+	Service    string
+	ZipCode    int
+	UF         string
+	Categories []int
+}
+
+// Matches reports whether every condition of the policy holds for t.
+// Carriers, services and UFs are compared case-insensitively.
+func (p Policy) Matches(t Target) bool {
+	switch {
+	case p.Carrier != "" && !strings.EqualFold(p.Carrier, t.Carrier):
+		return false
+	case p.Service != "" && !strings.EqualFold(p.Service, t.Service):
+		return false
+	case p.UF != "" && !strings.EqualFold(p.UF, t.UF):
+		return false
+	case p.ZipCodeStart != 0 && t.ZipCode < p.ZipCodeStart:
+		return false
+	case p.ZipCodeEnd != 0 && t.ZipCode > p.ZipCodeEnd:
+		return false
+	case p.Category != 0 && !slices.Contains(t.Categories, p.Category):
+		return false
+	}
+
+	return true
+}
+
+// Decide returns the action policies take on t, or an empty action when
+// none applies. Allow wins over hide, which wins over deprioritize.
+func Decide(policies []Policy, t Target) Action {
+	var decision Action
+	for _, p := range policies {
+		if !p.Matches(t) {
+			continue
+		}
+
+		switch p.Action {
+		case ActionAllow:
+			return ""
+		case ActionHide:
+			decision = ActionHide
+		case ActionDeprioritize:
+			if decision == "" {
+				decision = ActionDeprioritize
+			}
+		}
+	}
+
+	return decision
+}
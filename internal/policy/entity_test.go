@@ -0,0 +1,66 @@
+package policy_test
+
+import (
+	"testing"
+
+	"github.com/jeancarloshp/desafio-frete-rapido/internal/policy"
+)
+
+func TestDecide(t *testing.T) {
+	policies := []policy.Policy{
+		{Name: "jadlog-norte", Action: policy.ActionHide, Carrier: "JADLOG", UF: "AM"},
+		{Name: "jadlog-expresso-norte", Action: policy.ActionAllow, Carrier: "jadlog", Service: "Expresso", UF: "AM"},
+		{Name: "correios-interior-sp", Action: policy.ActionDeprioritize, Carrier: "CORREIOS", ZipCodeStart: 12000000, ZipCodeEnd: 19999999},
+		{Name: "frageis", Action: policy.ActionHide, Service: "PAC", Category: 7},
+	}
+
+	tests := []struct {
+		name   string
+		target policy.Target
+		want   policy.Action
+	}{
+		{
+			name:   "hidden carrier in the state",
+			target: policy.Target{Carrier: "JADLOG", Service: ".Package", ZipCode: 69005000, UF: "AM"},
+			want:   policy.ActionHide,
+		},
+		{
+			name:   "same carrier in another state",
+			target: policy.Target{Carrier: "JADLOG", Service: ".Package", ZipCode: 1311000, UF: "SP"},
+			want:   "",
+		},
+		{
+			name:   "allowed service wins over hide",
+			target: policy.Target{Carrier: "JADLOG", Service: "Expresso", ZipCode: 69005000, UF: "AM"},
+			want:   "",
+		},
+		{
+			name:   "deprioritized in the CEP range",
+			target: policy.Target{Carrier: "CORREIOS", Service: "SEDEX", ZipCode: 13000000, UF: "SP"},
+			want:   policy.ActionDeprioritize,
+		},
+		{
+			name:   "outside the CEP range",
+			target: policy.Target{Carrier: "CORREIOS", Service: "SEDEX", ZipCode: 1311000, UF: "SP"},
+			want:   "",
+		},
+		{
+			name:   "hide wins over deprioritize",
+			target: policy.Target{Carrier: "CORREIOS", Service: "PAC", ZipCode: 13000000, UF: "SP", Categories: []int{1, 7}},
+			want:   policy.ActionHide,
+		},
+		{
+			name:   "other product category",
+			target: policy.Target{Carrier: "LOGGI", Service: "PAC", ZipCode: 1311000, UF: "SP", Categories: []int{1}},
+			want:   "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := policy.Decide(policies, tt.target); got != tt.want {
+				t.Errorf("Expected %q, got: %q", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package policy
+
+import (
+	"errors"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type PolicyHandler struct {
+	policyController *PolicyController
+}
+
+func NewPolicyHandler(policyController *PolicyController) *PolicyHandler {
+	handler := &PolicyHandler{
+		policyController: policyController,
+	}
+
+	return handler
+}
+
+func (ph *PolicyHandler) CreatePolicyHandler(c *fiber.Ctx) error {
+	var req PolicyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	v := validator.New()
+	if err := v.Struct(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "validation failed",
+			"details": err.Error(),
+		})
+	}
+
+	policy, err := ph.policyController.CreatePolicy(c.UserContext(), req)
+	if errors.Is(err, ErrInvalidUF) || errors.Is(err, ErrInvalidZipCodeRange) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "validation failed",
+			"details": err.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "failed to create carrier policy",
+			"details": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(policy)
+}
+
+func (ph *PolicyHandler) ListPoliciesHandler(c *fiber.Ctx) error {
+	policies, err := ph.policyController.ListPolicies(c.UserContext())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "failed to list carrier policies",
+			"details": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(policies)
+}
+
+func (ph *PolicyHandler) DeletePolicyHandler(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "id must be a positive integer",
+		})
+	}
+
+	err = ph.policyController.DeletePolicy(c.UserContext(), id)
+	if errors.Is(err, ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "carrier policy not found",
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "failed to delete carrier policy",
+			"details": err.Error(),
+		})
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
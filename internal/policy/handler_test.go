@@ -0,0 +1,43 @@
+package policy_test
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jeancarloshp/desafio-frete-rapido/internal/policy"
+)
+
+func TestCreatePolicyHandler_RejectsInvalidPolicies(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{name: "missing name", body: `{"action":"hide","carrier":"JADLOG"}`},
+		{name: "unknown action", body: `{"name":"x","action":"block","carrier":"JADLOG"}`},
+		{name: "no carrier or service", body: `{"name":"x","action":"hide","uf":"AM"}`},
+		{name: "unknown state", body: `{"name":"x","action":"hide","carrier":"JADLOG","uf":"XX"}`},
+		{name: "invalid CEP", body: `{"name":"x","action":"hide","carrier":"JADLOG","zipcode_start":123}`},
+		{name: "inverted CEP range", body: `{"name":"x","action":"hide","carrier":"JADLOG","zipcode_start":20000000,"zipcode_end":10000000}`},
+	}
+
+	app := fiber.New()
+	app.Post("/admin/carrier-policies", policy.NewPolicyHandler(policy.NewPolicyController(nil)).CreatePolicyHandler)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/admin/carrier-policies", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			if resp.StatusCode != fiber.StatusBadRequest {
+				t.Errorf("Expected status 400, got: %d", resp.StatusCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/database/querier"
+)
+
+var ErrNotFound = errors.New("carrier policy not found")
+
+type PolicyRepository struct {
+	conn *querier.Queries
+}
+
+func NewPolicyRepository(conn *querier.Queries) *PolicyRepository {
+	return &PolicyRepository{
+		conn: conn,
+	}
+}
+
+func (r *PolicyRepository) CreatePolicy(ctx context.Context, policy Policy) (Policy, error) {
+	created, err := r.conn.CreateCarrierPolicy(ctx, querier.CreateCarrierPolicyParams{
+		Name:         policy.Name,
+		Action:       string(policy.Action),
+		Carrier:      policy.Carrier,
+		Service:      policy.Service,
+		Uf:           policy.UF,
+		ZipcodeStart: policy.ZipCodeStart,
+		ZipcodeEnd:   policy.ZipCodeEnd,
+		Category:     policy.Category,
+	})
+	if err != nil {
+		return Policy{}, fmt.Errorf("failed to create carrier policy: %w", err)
+	}
+
+	return toPolicy(created), nil
+}
+
+func (r *PolicyRepository) ListPolicies(ctx context.Context) ([]Policy, error) {
+	rows, err := r.conn.ListCarrierPolicies(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list carrier policies: %w", err)
+	}
+
+	policies := make([]Policy, len(rows))
+	for i, row := range rows {
+		policies[i] = toPolicy(row)
+	}
+
+	return policies, nil
+}
+
+func (r *PolicyRepository) DeletePolicy(ctx context.Context, id int) error {
+	rows, err := r.conn.DeleteCarrierPolicy(ctx, int32(id))
+	if err != nil {
+		return fmt.Errorf("failed to delete carrier policy: %w", err)
+	}
+
+	if rows == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func toPolicy(p querier.CarrierPolicy) Policy {
+	return Policy{
+		ID:           int(p.ID),
+		Name:         p.Name,
+		Action:       Action(p.Action),
+		Carrier:      p.Carrier,
+		Service:      p.Service,
+		UF:           p.Uf,
+		ZipCodeStart: p.ZipcodeStart,
+		ZipCodeEnd:   p.ZipcodeEnd,
+		Category:     p.Category,
+		CreatedAt:    p.CreatedAt.Time,
+	}
+}
@@ -1,102 +1,467 @@
 package quote
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
 	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
 
+	"github.com/jeancarloshp/desafio-frete-rapido/internal/policy"
+	"github.com/jeancarloshp/desafio-frete-rapido/internal/tenant"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/calendar"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/cep"
 	"github.com/jeancarloshp/desafio-frete-rapido/pkg/config"
-	fastdeliveryapi "github.com/jeancarloshp/desafio-frete-rapido/pkg/fastdelivery_api"
 	"github.com/jeancarloshp/desafio-frete-rapido/pkg/fastdelivery_api/models"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/freightmath"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/money"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/pricing"
 )
 
+// FastDeliveryClient simulates quotes on the Frete Rápido API. It is
+// implemented by *fastdeliveryapi.FastDeliveryAPI.
+type FastDeliveryClient interface {
+	SimulateQuote(ctx context.Context, quoteRequest models.QuoteRequest) (*models.QuoteResponse, error)
+}
+
+// EventPublisher is notified about every quote outcome. Publish must not
+// block the request for long, slow work belongs in the background.
+type EventPublisher interface {
+	Publish(ctx context.Context, event Event)
+}
+
+// PolicySource lists the carrier policies applied to every quote. It is
+// implemented by *policy.PolicyController.
+type PolicySource interface {
+	ListPolicies(ctx context.Context) ([]policy.Policy, error)
+}
+
 type QuoteController struct {
 	cfg             *config.Config
-	quoteRepository *QuoteRepository
-	api             *fastdeliveryapi.FastDeliveryAPI
+	quoteRepository QuoteRepository
+	api             FastDeliveryClient
+	fallback        FastDeliveryClient
+	rules           atomic.Pointer[pricing.Engine]
+	policies        PolicySource
+	calendar        *calendar.Calendar
+	publisher       EventPublisher
+	cubageFactors   freightmath.Factors
 }
 
-func NewQuoteController(cfg *config.Config, quoteRepository *QuoteRepository, api *fastdeliveryapi.FastDeliveryAPI) *QuoteController {
-	return &QuoteController{
+// QuoteOptions are the optional dependencies of a QuoteController. Nil
+// fields are left out.
+type QuoteOptions struct {
+	// Fallback answers quotes whenever the Frete Rápido API fails, and its
+	// offers are marked as estimated.
+	Fallback FastDeliveryClient
+	// Rules adjusts every price. It can be replaced with SetPricingRules.
+	Rules *pricing.Engine
+	// Policies hide or reorder offers.
+	Policies PolicySource
+	// Calendar turns deadlines into delivery dates.
+	Calendar *calendar.Calendar
+	// Publisher is told about every quote, created or failed.
+	Publisher EventPublisher
+}
+
+// NewQuoteController builds the controller. It fails when the cubage factors
+// of cfg cannot be parsed.
+func NewQuoteController(cfg *config.Config, quoteRepository QuoteRepository, api FastDeliveryClient, opts QuoteOptions) (*QuoteController, error) {
+	cubageFactors, err := cfg.CubageFactors()
+	if err != nil {
+		return nil, fmt.Errorf("invalid cubage factors: %w", err)
+	}
+
+	qc := &QuoteController{
 		cfg:             cfg,
 		quoteRepository: quoteRepository,
 		api:             api,
+		fallback:        opts.Fallback,
+		policies:        opts.Policies,
+		calendar:        opts.Calendar,
+		publisher:       opts.Publisher,
+		cubageFactors:   cubageFactors,
 	}
+	qc.rules.Store(opts.Rules)
+
+	return qc, nil
+}
+
+// SetPricingRules replaces the pricing rules used by the following quotes.
+// A quote being priced keeps the rules it started with, so its carriers are
+// never priced by different rule sets.
+func (qc *QuoteController) SetPricingRules(rules *pricing.Engine) {
+	qc.rules.Store(rules)
 }
 
-func (qc *QuoteController) SimulateQuote(quoteRequest QuoteRequest) (*QuoteResponse, error) {
+func (qc *QuoteController) SimulateQuote(ctx context.Context, quoteRequest QuoteRequest) (*QuoteResponse, error) {
+	response, err := qc.simulateQuote(ctx, quoteRequest)
+
+	if qc.publisher != nil {
+		event := Event{
+			Type:       EventQuoteCreated,
+			OccurredAt: time.Now().UTC(),
+			Request:    quoteRequest,
+			Response:   response,
+		}
+
+		if t, ok := tenant.FromContext(ctx); ok {
+			event.TenantID = &t.ID
+		}
+
+		if err != nil {
+			event.Type = EventQuoteFailed
+			event.Error = err.Error()
+		}
+
+		qc.publisher.Publish(ctx, event)
+	}
+
+	return response, err
+}
+
+func (qc *QuoteController) simulateQuote(ctx context.Context, quoteRequest QuoteRequest) (*QuoteResponse, error) {
 	zipcode, err := strconv.Atoi(quoteRequest.Recipient.Address.ZipCode)
 	if err != nil {
 		return nil, err
 	}
 
-	volumes := make([]models.Volume, len(quoteRequest.Volumes))
-	for i, v := range quoteRequest.Volumes {
-		category := strconv.Itoa(v.Category)
+	shipper, ok := tenant.FromContext(ctx)
+	if !ok {
+		shipper = tenant.Default(qc.cfg)
+	}
 
-		volumes[i] = models.Volume{
-			Category:      category,
+	fastDeliveryQuoteRequest := NewFastDeliveryRequest(shipper, shipper.OriginZipCode, zipcode, quoteRequest.Volumes)
+
+	estimated := false
+	quoteResponse, err := qc.api.SimulateQuote(ctx, fastDeliveryQuoteRequest)
+	if err != nil {
+		quoteResponse, estimated = qc.simulateFallback(ctx, fastDeliveryQuoteRequest, err)
+		if !estimated {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+
+	quoteResponse, expired := DropExpiredOffers(quoteResponse, now)
+	quoteResponse, hidden := qc.applyPolicies(ctx, quoteResponse, zipcode, quoteRequest.Volumes)
+
+	response := NewQuoteResponse(quoteResponse, quoteRequest.Volumes, qc.cubageFactors)
+	response.MarkUnavailable(expired, ReasonExpired)
+	response.MarkUnavailable(hidden, ReasonHidden)
+
+	cartValue := CartValue(quoteRequest.Volumes)
+	rules := qc.rules.Load()
+
+	for i := range response.Carriers {
+		response.Carriers[i].Estimated = estimated
+		response.Carriers[i].DeliveryDate = qc.deliveryDate(now, shipper.OriginZipCode, zipcode, response.Carriers[i].Deadline)
+		applyPricing(rules, &response.Carriers[i], cartValue)
+
+		err := qc.quoteRepository.SaveQuote(ctx, response.Carriers[i])
+		if err != nil {
+			return nil, fmt.Errorf("failed to save quote: %w", err)
+		}
+
+		if !quoteRequest.IncludeComposition {
+			response.Carriers[i].Composition = nil
+		}
+	}
+
+	return response, nil
+}
+
+// deliveryDate returns the day a deadline of days business days ends for an
+// order placed at now and shipped between the origin and destination CEPs.
+// It is empty without a calendar. CEPs outside every state only skip the
+// national holidays.
+func (qc *QuoteController) deliveryDate(now time.Time, origin, destination, days int) string {
+	if qc.calendar == nil {
+		return ""
+	}
+
+	originUF, _ := cep.UF(origin)
+	destinationUF, _ := cep.UF(destination)
+
+	return qc.calendar.DeliveryDate(now, originUF, destinationUF, days).Format(calendar.DateLayout)
+}
+
+// simulateFallback quotes request on the fallback provider after the Frete
+// Rápido API failed with upstreamErr. It reports false when there is no
+// fallback or it has no offers, so the upstream error is returned instead.
+func (qc *QuoteController) simulateFallback(ctx context.Context, request models.QuoteRequest, upstreamErr error) (*models.QuoteResponse, bool) {
+	if qc.fallback == nil {
+		return nil, false
+	}
+
+	response, err := qc.fallback.SimulateQuote(ctx, request)
+	if err != nil {
+		slog.Warn("fallback quote failed", "upstream_error", upstreamErr, "error", err)
+		return nil, false
+	}
+
+	for _, d := range response.Dispatchers {
+		if len(d.Offers) > 0 {
+			slog.Warn("frete rápido unavailable, answering with estimated offers", "error", upstreamErr)
+			return response, true
+		}
+	}
+
+	return nil, false
+}
+
+// applyPolicies drops the upstream offers hidden by the carrier policies and
+// moves the deprioritized ones after the others, keeping their order. It
+// returns a copy of upstream and the dispatchers left without offers by the
+// policies. Quotes go on without policies when they cannot be listed.
+func (qc *QuoteController) applyPolicies(ctx context.Context, upstream *models.QuoteResponse, zipcode int, volumes []Volume) (*models.QuoteResponse, map[string]bool) {
+	if qc.policies == nil {
+		return upstream, nil
+	}
+
+	policies, err := qc.policies.ListPolicies(ctx)
+	if err != nil {
+		slog.Error("failed to list carrier policies, quoting without them", "error", err)
+		return upstream, nil
+	}
+	if len(policies) == 0 {
+		return upstream, nil
+	}
+
+	target := policy.Target{ZipCode: zipcode}
+	target.UF, _ = cep.UF(zipcode)
+	for _, v := range volumes {
+		target.Categories = append(target.Categories, v.Category)
+	}
+
+	filtered := &models.QuoteResponse{Dispatchers: make([]models.DispatcherResponse, len(upstream.Dispatchers))}
+	hidden := make(map[string]bool)
+
+	for i, d := range upstream.Dispatchers {
+		var offers, deprioritized []models.Offer
+		for _, o := range d.Offers {
+			target.Carrier, target.Service = o.Carrier.Name, o.Service
+
+			switch policy.Decide(policies, target) {
+			case policy.ActionHide:
+			case policy.ActionDeprioritize:
+				deprioritized = append(deprioritized, o)
+			default:
+				offers = append(offers, o)
+			}
+		}
+
+		d.Offers = append(offers, deprioritized...)
+		if len(d.Offers) == 0 && len(upstream.Dispatchers[i].Offers) > 0 {
+			hidden[d.ID] = true
+		}
+		filtered.Dispatchers[i] = d
+	}
+
+	return filtered, hidden
+}
+
+// DropExpiredOffers returns a copy of upstream without the offers that expired
+// before now, and the dispatchers left without offers by them. Offers without
+// an expiration, such as the rate table ones, never expire.
+func DropExpiredOffers(upstream *models.QuoteResponse, now time.Time) (*models.QuoteResponse, map[string]bool) {
+	filtered := &models.QuoteResponse{Dispatchers: make([]models.DispatcherResponse, len(upstream.Dispatchers))}
+	expired := make(map[string]bool)
+
+	for i, d := range upstream.Dispatchers {
+		var offers []models.Offer
+		for _, o := range d.Offers {
+			if o.Expiration.IsZero() || o.Expiration.After(now) {
+				offers = append(offers, o)
+			}
+		}
+
+		d.Offers = offers
+		if len(offers) == 0 && len(upstream.Dispatchers[i].Offers) > 0 {
+			expired[d.ID] = true
+		}
+		filtered.Dispatchers[i] = d
+	}
+
+	return filtered, expired
+}
+
+// applyPricing runs the pricing rules over carrier. When any of them fires,
+// the upstream price is kept in Pricing next to the rules.
+func applyPricing(engine *pricing.Engine, carrier *Carrier, cartValue money.Money) {
+	result := engine.Apply(pricing.Offer{
+		Carrier:   carrier.Name,
+		Service:   carrier.Service,
+		Price:     carrier.Price,
+		CartValue: cartValue,
+	})
+	if len(result.Applied) == 0 {
+		return
+	}
+
+	rules := make([]AppliedRule, len(result.Applied))
+	for i, applied := range result.Applied {
+		rules[i] = AppliedRule{Name: applied.Rule, Price: applied.Price}
+	}
+
+	carrier.Pricing = &Pricing{OriginalPrice: carrier.Price, Rules: rules}
+	carrier.Price = result.Price
+}
+
+// CartValue is the total value of the goods in volumes. Amounts below one
+// count as a single unit, as in the weights.
+func CartValue(volumes []Volume) money.Money {
+	var total money.Money
+	for _, v := range volumes {
+		total = total.Add(v.Price.Mul(int64(max(v.Amount, 1))))
+	}
+
+	return total
+}
+
+// NewQuoteResponse flattens the offers of every dispatcher in an upstream
+// response, tagging each one with the dispatcher and request it came from.
+// When volumes are given, each carrier also gets the weight it should bill
+// according to cubageFactors.
+func NewQuoteResponse(upstream *models.QuoteResponse, volumes []Volume, cubageFactors freightmath.Factors) *QuoteResponse {
+	response := &QuoteResponse{Carriers: []Carrier{}}
+
+	if len(upstream.Dispatchers) == 0 {
+		response.Unavailable = append(response.Unavailable, Unavailable{Reason: ReasonNoDispatchers})
+		return response
+	}
+
+	for _, d := range upstream.Dispatchers {
+		if len(d.Offers) == 0 {
+			response.Unavailable = append(response.Unavailable, Unavailable{
+				DispatcherID: d.ID,
+				RequestID:    d.RequestID,
+				Reason:       ReasonNoOffers,
+			})
+			continue
+		}
+
+		for _, o := range d.Offers {
+			response.Carriers = append(response.Carriers, Carrier{
+				Name:                 o.Carrier.Name,
+				Price:                o.FinalPrice,
+				Service:              o.Service,
+				Deadline:             o.DeliveryTime.Days,
+				UpstreamDeliveryDate: o.DeliveryTime.EstimatedDate,
+				DispatcherID:         d.ID,
+				RequestID:            d.RequestID,
+				Composition:          NewComposition(o.Composition),
+				Weights:              newWeights(volumes, cubageFactors.For(o.Carrier.Name, o.Modal), o.Weights),
+			})
+		}
+	}
+
+	return response
+}
+
+func newWeights(volumes []Volume, cubageFactor float64, upstream models.Weights) *Weights {
+	if len(volumes) == 0 {
+		return nil
+	}
+
+	items := make([]freightmath.Item, len(volumes))
+	for i, v := range volumes {
+		items[i] = freightmath.Item{
 			Amount:        v.Amount,
 			UnitaryWeight: v.UnitaryWeight,
-			UnitaryPrice:  v.Price,
-			SKU:           v.SKU,
 			Height:        v.Height,
 			Width:         v.Width,
 			Length:        v.Length,
 		}
 	}
 
-	fastDeliveryQuoteRequest := models.QuoteRequest{
-		Shipper: models.Shipper{
-			RegisteredNumber: qc.cfg.FastDeliveryAPISenderCNPJ,
-			Token:            qc.cfg.FastDeliveryAPIToken,
-			PlatformCode:     qc.cfg.FastDeliveryAPIPlatformCode,
-		},
-		Recipient: models.Recipient{
-			Type:    0,
-			Country: "BRA",
-			Zipcode: zipcode,
-		},
-		Dispatchers: []models.Dispatcher{
-			{
-				RegisteredNumber: qc.cfg.FastDeliveryAPISenderCNPJ,
-				Zipcode:          qc.cfg.FastDeliveryAPIZipCode,
-				Volumes:          volumes,
-			},
-		},
-		SimulationType: []int{0},
+	local := freightmath.Compute(items, cubageFactor)
+
+	return &Weights{
+		Real:           local.Real,
+		Cubed:          local.Cubed,
+		Billed:         local.Billed,
+		CubageFactor:   local.CubageFactor,
+		UpstreamBilled: upstream.Used,
+		Discrepancy:    freightmath.Differs(local.Billed, upstream.Used),
 	}
+}
 
-	quoteResponse, err := qc.api.SimulateQuote(fastDeliveryQuoteRequest)
-	if err != nil {
-		return nil, err
+// NewComposition lists the charges of an upstream composition that are not
+// zero, in the order the Frete Rápido API documents them.
+func NewComposition(c models.Composition) []Fee {
+	charges := []Fee{
+		{"freight_weight", c.FreightWeight},
+		{"freight_weight_excess", c.FreightWeightExcess},
+		{"freight_weight_volume", c.FreightWeightVolume},
+		{"freight_volume", c.FreightVolume},
+		{"freight_minimum", c.FreightMinimum},
+		{"freight_invoice", c.FreightInvoice},
+		{"daily", c.SubTotal1.Daily},
+		{"collect", c.SubTotal1.Collect},
+		{"dispatch", c.SubTotal1.Dispatch},
+		{"delivery", c.SubTotal1.Delivery},
+		{"ferry", c.SubTotal1.Ferry},
+		{"suframa", c.SubTotal1.Suframa},
+		{"tas", c.SubTotal1.Tas},
+		{"sec_cat", c.SubTotal1.SecCat},
+		{"dat", c.SubTotal1.Dat},
+		{"ad_valorem", c.SubTotal1.AdValorem},
+		{"ademe", c.SubTotal1.Ademe},
+		{"gris", c.SubTotal1.Gris},
+		{"emex", c.SubTotal1.Emex},
+		{"interior", c.SubTotal1.Interior},
+		{"capatazia", c.SubTotal1.Capatazia},
+		{"river", c.SubTotal1.River},
+		{"river_insurance", c.SubTotal1.RiverInsurance},
+		{"toll", c.SubTotal1.Toll},
+		{"other", c.SubTotal1.Other},
+		{"other_per_product", c.SubTotal1.OtherPerProduct},
+		{"trt", c.SubTotal2.Trt},
+		{"tda", c.SubTotal2.Tda},
+		{"tde", c.SubTotal2.Tde},
+		{"scheduling", c.SubTotal2.Scheduling},
+		{"icms", c.SubTotal3.ICMS},
 	}
 
-	carriers := make([]Carrier, len(quoteResponse.Dispatchers[0].Offers))
-	for i, o := range quoteResponse.Dispatchers[0].Offers {
-		carriers[i] = Carrier{
-			Name:     o.Carrier.Name,
-			Price:    o.FinalPrice,
-			Service:  o.Service,
-			Deadline: o.DeliveryTime.Days,
+	var fees []Fee
+	for _, fee := range charges {
+		if !fee.Amount.IsZero() {
+			fees = append(fees, fee)
 		}
 	}
 
-	for _, c := range carriers {
-		err := qc.quoteRepository.SaveQuote(c)
-		if err != nil {
-			return nil, fmt.Errorf("failed to save quote: %w", err)
+	return fees
+}
+
+// MarkUnavailable gives reason to the unavailable dispatchers in
+// dispatchers, which had offers upstream that were filtered out.
+func (r *QuoteResponse) MarkUnavailable(dispatchers map[string]bool, reason string) {
+	for i, u := range r.Unavailable {
+		if dispatchers[u.DispatcherID] {
+			r.Unavailable[i].Reason = reason
 		}
 	}
+}
 
-	response := &QuoteResponse{
-		Carriers: carriers,
+// Reasons joins why dispatchers had no offers, for logs and error messages.
+func (r *QuoteResponse) Reasons() string {
+	reasons := make([]string, len(r.Unavailable))
+	for i, u := range r.Unavailable {
+		reasons[i] = u.Reason
+		if u.DispatcherID != "" {
+			reasons[i] = fmt.Sprintf("dispatcher %s: %s", u.DispatcherID, u.Reason)
+		}
 	}
 
-	return response, nil
+	return strings.Join(reasons, "; ")
 }
 
-func (qc *QuoteController) QuoteMetrics(lastQuotes int) (QuoteMetrics, error) {
-	quotes, err := qc.quoteRepository.FindQuotesByLastQuote(lastQuotes)
+func (qc *QuoteController) QuoteMetrics(ctx context.Context, lastQuotes int) (QuoteMetrics, error) {
+	quotes, err := qc.quoteRepository.FindQuotesByLastQuote(ctx, lastQuotes)
 	if err != nil {
 		return QuoteMetrics{}, fmt.Errorf("failed to find last quotes: %w", err)
 	}
@@ -105,11 +470,11 @@ func (qc *QuoteController) QuoteMetrics(lastQuotes int) (QuoteMetrics, error) {
 		return QuoteMetrics{}, nil
 	}
 
-	var totalPrice float64
+	var totalPrice money.Money
 	carrierQuotesMap := make(map[string]*CarrierQuotes)
 
 	for _, quote := range quotes {
-		totalPrice += quote.Price
+		totalPrice = totalPrice.Add(quote.Price)
 
 		if _, exists := carrierQuotesMap[quote.Name]; !exists {
 			carrierQuotesMap[quote.Name] = &CarrierQuotes{
@@ -119,13 +484,13 @@ func (qc *QuoteController) QuoteMetrics(lastQuotes int) (QuoteMetrics, error) {
 
 		carrierQuote := carrierQuotesMap[quote.Name]
 		carrierQuote.TotalQuotes++
-		carrierQuote.TotalPrice += quote.Price
+		carrierQuote.TotalPrice = carrierQuote.TotalPrice.Add(quote.Price)
 	}
 
-	var cheapestShipping, highestShipping float64
+	var cheapestShipping, highestShipping money.Money
 	for _, cq := range carrierQuotesMap {
-		cq.AveragePrice = cq.TotalPrice / float64(cq.TotalQuotes)
-		if cheapestShipping == 0 || cq.AveragePrice < cheapestShipping {
+		cq.AveragePrice = cq.TotalPrice.Div(int64(cq.TotalQuotes))
+		if cheapestShipping.IsZero() || cq.AveragePrice < cheapestShipping {
 			cheapestShipping = cq.AveragePrice
 		}
 		if cq.AveragePrice > highestShipping {
@@ -144,3 +509,41 @@ func (qc *QuoteController) QuoteMetrics(lastQuotes int) (QuoteMetrics, error) {
 		HighestShipping:  highestShipping,
 	}, nil
 }
+
+// NewFastDeliveryRequest builds the upstream simulation request for volumes
+// dispatched by shipper from originZipCode.
+func NewFastDeliveryRequest(shipper tenant.Tenant, originZipCode, recipientZipCode int, volumes []Volume) models.QuoteRequest {
+	dispatcherVolumes := make([]models.Volume, len(volumes))
+	for i, v := range volumes {
+		category := strconv.Itoa(v.Category)
+
+		dispatcherVolumes[i] = models.Volume{
+			Category:      category,
+			Amount:        v.Amount,
+			UnitaryWeight: v.UnitaryWeight,
+			UnitaryPrice:  v.Price,
+			SKU:           v.SKU,
+			Height:        v.Height,
+			Width:         v.Width,
+			Length:        v.Length,
+		}
+	}
+
+	return models.QuoteRequest{
+		Shipper: shipper.Shipper(),
+		Recipient: models.Recipient{
+			Type:    0,
+			Country: "BRA",
+			Zipcode: recipientZipCode,
+		},
+		Dispatchers: []models.Dispatcher{
+			{
+				RegisteredNumber: shipper.ShipperCNPJ,
+				Zipcode:          originZipCode,
+				Volumes:          dispatcherVolumes,
+			},
+		},
+		SimulationType: []int{0},
+		Returns:        models.Returns{Composition: true},
+	}
+}
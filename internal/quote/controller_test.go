@@ -1,832 +1,1002 @@
 package quote_test
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"strconv"
+	"net/http/httptest"
+	"reflect"
 	"testing"
+	"time"
 
+	"github.com/jeancarloshp/desafio-frete-rapido/internal/policy"
 	"github.com/jeancarloshp/desafio-frete-rapido/internal/quote"
+	"github.com/jeancarloshp/desafio-frete-rapido/internal/quote/quotetest"
+	"github.com/jeancarloshp/desafio-frete-rapido/internal/tenant"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/calendar"
 	"github.com/jeancarloshp/desafio-frete-rapido/pkg/config"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/fakefastdelivery"
+	fastdeliveryapi "github.com/jeancarloshp/desafio-frete-rapido/pkg/fastdelivery_api"
 	"github.com/jeancarloshp/desafio-frete-rapido/pkg/fastdelivery_api/models"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/pricing"
 )
 
-// Interface para repository
-type QuoteRepositoryInterface interface {
-	SaveQuote(carrier quote.Carrier) error
-	FindQuotesByLastQuote(lastQuotes int) ([]quote.Carrier, error)
-}
-
-// Interface para API
-type FastDeliveryAPIInterface interface {
-	SimulateQuote(request models.QuoteRequest) (*models.QuoteResponse, error)
+func testConfig() *config.Config {
+	return &config.Config{
+		FastDeliveryAPIToken:        "test-token-12345678901234567890",
+		FastDeliveryAPIPlatformCode: "platform-123",
+		FastDeliveryAPISenderCNPJ:   "12345678901234",
+		FastDeliveryAPIZipCode:      12345678,
+	}
 }
 
-// MockQuoteRepository implementa um mock do repository
-type MockQuoteRepository struct {
-	saveQuoteFunc             func(carrier quote.Carrier) error
-	findQuotesByLastQuoteFunc func(lastQuotes int) ([]quote.Carrier, error)
-}
+// volumeWeights são os pesos calculados para o volume padrão de quoteRequest
+var volumeWeights = &quote.Weights{Real: 1, Cubed: 0.3, Billed: 1, CubageFactor: 300}
 
-func (m *MockQuoteRepository) SaveQuote(carrier quote.Carrier) error {
-	if m.saveQuoteFunc != nil {
-		return m.saveQuoteFunc(carrier)
+func quoteRequest(zipcode string, volumes ...quote.Volume) quote.QuoteRequest {
+	if len(volumes) == 0 {
+		volumes = []quote.Volume{
+			{Category: 1, Amount: 1, UnitaryWeight: 1.0, Price: quotetest.Price("50.00"), SKU: "PROD123", Height: 0.1, Width: 0.1, Length: 0.1},
+		}
 	}
-	return nil
-}
 
-func (m *MockQuoteRepository) FindQuotesByLastQuote(lastQuotes int) ([]quote.Carrier, error) {
-	if m.findQuotesByLastQuoteFunc != nil {
-		return m.findQuotesByLastQuoteFunc(lastQuotes)
+	return quote.QuoteRequest{
+		Recipient: quote.Recipient{Address: quote.Address{ZipCode: zipcode}},
+		Volumes:   volumes,
 	}
-	return []quote.Carrier{}, nil
 }
 
-// MockFastDeliveryAPI implementa um mock da API externa
-type MockFastDeliveryAPI struct {
-	simulateQuoteFunc func(request models.QuoteRequest) (*models.QuoteResponse, error)
+func expiredOffer(o models.Offer) models.Offer {
+	o.Expiration = time.Now().Add(-time.Hour)
+	return o
 }
 
-func (m *MockFastDeliveryAPI) SimulateQuote(request models.QuoteRequest) (*models.QuoteResponse, error) {
-	if m.simulateQuoteFunc != nil {
-		return m.simulateQuoteFunc(request)
+func TestSimulateQuote(t *testing.T) {
+	errUpstream := errors.New("API connection error")
+	errDatabase := errors.New("database save error")
+
+	tests := []struct {
+		name            string
+		request         quote.QuoteRequest
+		client          *quotetest.Client
+		repository      *quotetest.Repository
+		wantErr         error
+		wantErrText     string
+		wantCarriers    []quote.Carrier
+		wantUnavailable []quote.Unavailable
+		wantCalls       int
+	}{
+		{
+			name:    "maps offers to carriers",
+			request: quoteRequest("12345678"),
+			client: &quotetest.Client{Response: quotetest.Response(
+				quotetest.Offer("Transportadora A", "Expresso", "25.50", 3),
+				quotetest.Offer("Transportadora B", "Normal", "15.75", 7),
+			)},
+			repository: &quotetest.Repository{},
+			wantCarriers: []quote.Carrier{
+				{Name: "Transportadora A", Service: "Expresso", Price: quotetest.Price("25.50"), Deadline: 3, Weights: volumeWeights},
+				{Name: "Transportadora B", Service: "Normal", Price: quotetest.Price("15.75"), Deadline: 7, Weights: volumeWeights},
+			},
+			wantCalls: 1,
+		},
+		{
+			name:    "offers from every dispatcher",
+			request: quoteRequest("12345678"),
+			client: &quotetest.Client{Response: &models.QuoteResponse{
+				Dispatchers: []models.DispatcherResponse{
+					{ID: "d1", RequestID: "r1", Offers: []models.Offer{quotetest.Offer("Transportadora A", "Expresso", "25.50", 3)}},
+					{ID: "d2", RequestID: "r1", Offers: []models.Offer{quotetest.Offer("Transportadora B", "Normal", "15.75", 7)}},
+				},
+			}},
+			repository: &quotetest.Repository{},
+			wantCarriers: []quote.Carrier{
+				{Name: "Transportadora A", Service: "Expresso", Price: quotetest.Price("25.50"), Deadline: 3, DispatcherID: "d1", RequestID: "r1", Weights: volumeWeights},
+				{Name: "Transportadora B", Service: "Normal", Price: quotetest.Price("15.75"), Deadline: 7, DispatcherID: "d2", RequestID: "r1", Weights: volumeWeights},
+			},
+			wantCalls: 1,
+		},
+		{
+			name:    "dispatcher without offers",
+			request: quoteRequest("12345678"),
+			client: &quotetest.Client{Response: &models.QuoteResponse{
+				Dispatchers: []models.DispatcherResponse{
+					{ID: "d1", RequestID: "r1", Offers: []models.Offer{quotetest.Offer("Transportadora A", "Expresso", "25.50", 3)}},
+					{ID: "d2", RequestID: "r1"},
+				},
+			}},
+			repository: &quotetest.Repository{},
+			wantCarriers: []quote.Carrier{
+				{Name: "Transportadora A", Service: "Expresso", Price: quotetest.Price("25.50"), Deadline: 3, DispatcherID: "d1", RequestID: "r1", Weights: volumeWeights},
+			},
+			wantUnavailable: []quote.Unavailable{
+				{DispatcherID: "d2", RequestID: "r1", Reason: quote.ReasonNoOffers},
+			},
+			wantCalls: 1,
+		},
+		{
+			name:    "expired offers",
+			request: quoteRequest("12345678"),
+			client: &quotetest.Client{Response: &models.QuoteResponse{
+				Dispatchers: []models.DispatcherResponse{
+					{ID: "d1", RequestID: "r1", Offers: []models.Offer{
+						expiredOffer(quotetest.Offer("Transportadora A", "Expresso", "25.50", 3)),
+						quotetest.Offer("Transportadora B", "Normal", "15.75", 7),
+					}},
+					{ID: "d2", RequestID: "r1", Offers: []models.Offer{expiredOffer(quotetest.Offer("Transportadora C", "Normal", "12.00", 9))}},
+				},
+			}},
+			repository: &quotetest.Repository{},
+			wantCarriers: []quote.Carrier{
+				{Name: "Transportadora B", Service: "Normal", Price: quotetest.Price("15.75"), Deadline: 7, DispatcherID: "d1", RequestID: "r1", Weights: volumeWeights},
+			},
+			wantUnavailable: []quote.Unavailable{
+				{DispatcherID: "d2", RequestID: "r1", Reason: quote.ReasonExpired},
+			},
+			wantCalls: 1,
+		},
+		{
+			name:            "no dispatchers",
+			request:         quoteRequest("12345678"),
+			client:          &quotetest.Client{Response: &models.QuoteResponse{}},
+			repository:      &quotetest.Repository{},
+			wantCarriers:    []quote.Carrier{},
+			wantUnavailable: []quote.Unavailable{{Reason: quote.ReasonNoDispatchers}},
+			wantCalls:       1,
+		},
+		{
+			name:        "invalid zipcode",
+			request:     quoteRequest("invalid"),
+			client:      &quotetest.Client{},
+			repository:  &quotetest.Repository{},
+			wantErrText: `strconv.Atoi: parsing "invalid": invalid syntax`,
+			wantCalls:   0,
+		},
+		{
+			name:       "upstream error",
+			request:    quoteRequest("12345678"),
+			client:     &quotetest.Client{Err: errUpstream},
+			repository: &quotetest.Repository{},
+			wantErr:    errUpstream,
+			wantCalls:  1,
+		},
+		{
+			name:        "save error",
+			request:     quoteRequest("12345678"),
+			client:      &quotetest.Client{Response: quotetest.Response(quotetest.Offer("Transportadora A", "Expresso", "25.50", 3))},
+			repository:  &quotetest.Repository{SaveErr: errDatabase},
+			wantErr:     errDatabase,
+			wantErrText: "failed to save quote: database save error",
+			wantCalls:   1,
+		},
 	}
-	return &models.QuoteResponse{}, nil
-}
 
-// TestableQuoteController expõe métodos testáveis implementando a lógica diretamente
-type TestableQuoteController struct {
-	cfg        *config.Config
-	repository QuoteRepositoryInterface
-	api        FastDeliveryAPIInterface
-}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			controller, err := quote.NewQuoteController(testConfig(), tt.repository, tt.client, quote.QuoteOptions{})
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
 
-func NewTestableQuoteController(cfg *config.Config, repository QuoteRepositoryInterface, api FastDeliveryAPIInterface) *TestableQuoteController {
-	return &TestableQuoteController{
-		cfg:        cfg,
-		repository: repository,
-		api:        api,
-	}
-}
+			response, err := controller.SimulateQuote(context.Background(), tt.request)
 
-func (qc *TestableQuoteController) SimulateQuote(quoteRequest quote.QuoteRequest) (*quote.QuoteResponse, error) {
-	zipcode, err := strconv.Atoi(quoteRequest.Recipient.Address.ZipCode)
-	if err != nil {
-		return nil, err
-	}
-
-	volumes := make([]models.Volume, len(quoteRequest.Volumes))
-	for i, v := range quoteRequest.Volumes {
-		category := strconv.Itoa(v.Category)
-
-		volumes[i] = models.Volume{
-			Category:      category,
-			Amount:        v.Amount,
-			UnitaryWeight: v.UnitaryWeight,
-			UnitaryPrice:  v.Price,
-			SKU:           v.SKU,
-			Height:        v.Height,
-			Width:         v.Width,
-			Length:        v.Length,
-		}
+			if len(tt.client.Requests) != tt.wantCalls {
+				t.Errorf("Expected %d upstream calls, got: %d", tt.wantCalls, len(tt.client.Requests))
+			}
+
+			if tt.wantErr != nil || tt.wantErrText != "" {
+				if err == nil {
+					t.Fatal("Expected error, got nil")
+				}
+				if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+					t.Errorf("Expected error %v, got: %v", tt.wantErr, err)
+				}
+				if tt.wantErrText != "" && err.Error() != tt.wantErrText {
+					t.Errorf("Expected error message '%s', got: '%s'", tt.wantErrText, err.Error())
+				}
+				if response != nil {
+					t.Errorf("Expected nil response on error, got: %+v", response)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			if !reflect.DeepEqual(response.Carriers, tt.wantCarriers) {
+				t.Errorf("Expected carriers %+v, got: %+v", tt.wantCarriers, response.Carriers)
+			}
+
+			if !reflect.DeepEqual(response.Unavailable, tt.wantUnavailable) {
+				t.Errorf("Expected unavailable %+v, got: %+v", tt.wantUnavailable, response.Unavailable)
+			}
+
+			// Todas as ofertas devolvidas ao cliente entram nas métricas
+			if saved := append([]quote.Carrier{}, tt.repository.Saved...); !reflect.DeepEqual(saved, tt.wantCarriers) {
+				t.Errorf("Expected saved quotes %+v, got: %+v", tt.wantCarriers, tt.repository.Saved)
+			}
+		})
 	}
+}
 
-	fastDeliveryQuoteRequest := models.QuoteRequest{
-		Shipper: models.Shipper{
-			RegisteredNumber: qc.cfg.FastDeliveryAPISenderCNPJ,
-			Token:            qc.cfg.FastDeliveryAPIToken,
-			PlatformCode:     qc.cfg.FastDeliveryAPIPlatformCode,
+// Quando o Frete Rápido falha, as ofertas das tabelas locais são devolvidas e
+// salvas como estimadas
+func TestSimulateQuote_Fallback(t *testing.T) {
+	errUpstream := errors.New("API connection error")
+	offer := quotetest.Offer("CORREIOS", "PAC", "25.90", 6)
+
+	tests := []struct {
+		name          string
+		client        *quotetest.Client
+		fallback      *quotetest.Client
+		wantErr       error
+		wantEstimated bool
+		wantFallback  int
+	}{
+		{
+			name:         "upstream answers",
+			client:       &quotetest.Client{Response: quotetest.Response(offer)},
+			fallback:     &quotetest.Client{Response: quotetest.Response(offer)},
+			wantFallback: 0,
 		},
-		Recipient: models.Recipient{
-			Type:    0,
-			Country: "BRA",
-			Zipcode: zipcode,
+		{
+			name:          "upstream fails",
+			client:        &quotetest.Client{Err: errUpstream},
+			fallback:      &quotetest.Client{Response: quotetest.Response(offer)},
+			wantEstimated: true,
+			wantFallback:  1,
 		},
-		Dispatchers: []models.Dispatcher{
-			{
-				RegisteredNumber: qc.cfg.FastDeliveryAPISenderCNPJ,
-				Zipcode:          qc.cfg.FastDeliveryAPIZipCode,
-				Volumes:          volumes,
-			},
+		{
+			name:         "fallback has no offers",
+			client:       &quotetest.Client{Err: errUpstream},
+			fallback:     &quotetest.Client{Response: quotetest.Response()},
+			wantErr:      errUpstream,
+			wantFallback: 1,
+		},
+		{
+			name:         "fallback fails",
+			client:       &quotetest.Client{Err: errUpstream},
+			fallback:     &quotetest.Client{Err: errors.New("no table")},
+			wantErr:      errUpstream,
+			wantFallback: 1,
 		},
-		SimulationType: []int{0},
 	}
 
-	quoteResponse, err := qc.api.SimulateQuote(fastDeliveryQuoteRequest)
-	if err != nil {
-		return nil, err
-	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repository := &quotetest.Repository{}
+			controller, err := quote.NewQuoteController(testConfig(), repository, tt.client, quote.QuoteOptions{Fallback: tt.fallback})
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
 
-	carriers := make([]quote.Carrier, len(quoteResponse.Dispatchers[0].Offers))
-	for i, o := range quoteResponse.Dispatchers[0].Offers {
-		carriers[i] = quote.Carrier{
-			Name:     o.Carrier.Name,
-			Price:    o.FinalPrice,
-			Service:  o.Service,
-			Deadline: o.DeliveryTime.Days,
-		}
-	}
+			response, err := controller.SimulateQuote(context.Background(), quoteRequest("01311000"))
 
-	for _, c := range carriers {
-		err := qc.repository.SaveQuote(c)
-		if err != nil {
-			return nil, fmt.Errorf("failed to save quote: %w", err)
-		}
-	}
+			if len(tt.fallback.Requests) != tt.wantFallback {
+				t.Errorf("Expected %d fallback calls, got: %d", tt.wantFallback, len(tt.fallback.Requests))
+			}
 
-	response := &quote.QuoteResponse{
-		Carriers: carriers,
-	}
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Expected error %v, got: %v", tt.wantErr, err)
+				}
+				if len(repository.Saved) != 0 {
+					t.Errorf("Expected nothing saved, got: %+v", repository.Saved)
+				}
+				return
+			}
 
-	return response, nil
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			want := []quote.Carrier{
+				{Name: "CORREIOS", Service: "PAC", Price: quotetest.Price("25.90"), Deadline: 6, Estimated: tt.wantEstimated, Weights: volumeWeights},
+			}
+			if !reflect.DeepEqual(response.Carriers, want) {
+				t.Errorf("Expected carriers %+v, got: %+v", want, response.Carriers)
+			}
+			if !reflect.DeepEqual(repository.Saved, want) {
+				t.Errorf("Expected saved quotes %+v, got: %+v", want, repository.Saved)
+			}
+		})
+	}
 }
 
-func (qc *TestableQuoteController) QuoteMetrics(lastQuotes int) (quote.QuoteMetrics, error) {
-	quotes, err := qc.repository.FindQuotesByLastQuote(lastQuotes)
+// As regras comerciais ajustam o preço, guardando o original e as regras aplicadas
+func TestSimulateQuote_Pricing(t *testing.T) {
+	rules, err := pricing.New([]pricing.Rule{
+		{Name: "jadlog-margin", When: pricing.Condition{Carriers: []string{"JADLOG"}}, Then: pricing.Action{Type: pricing.ActionMarkup, Percent: 10}},
+		{Name: "free-above-200", When: pricing.Condition{CartValueMin: quotetest.Price("200.00")}, Then: pricing.Action{Type: pricing.ActionFreeShipping}},
+		{Name: "ending", Then: pricing.Action{Type: pricing.ActionRoundTo, Amount: quotetest.Price("0.90")}},
+	})
 	if err != nil {
-		return quote.QuoteMetrics{}, fmt.Errorf("failed to find last quotes: %w", err)
+		t.Fatalf("Expected no error, got: %v", err)
 	}
 
-	if len(quotes) == 0 {
-		return quote.QuoteMetrics{}, nil
+	tests := []struct {
+		name         string
+		volume       quote.Volume
+		wantCarriers []quote.Carrier
+	}{
+		{
+			name:   "markup and ending",
+			volume: quote.Volume{Category: 1, Amount: 1, UnitaryWeight: 1, Price: quotetest.Price("50.00"), SKU: "PROD123", Height: 0.1, Width: 0.1, Length: 0.1},
+			wantCarriers: []quote.Carrier{
+				{Name: "JADLOG", Service: ".Package", Price: quotetest.Price("22.90"), Deadline: 5, Weights: volumeWeights, Pricing: &quote.Pricing{
+					OriginalPrice: quotetest.Price("20.00"),
+					Rules:         []quote.AppliedRule{{Name: "jadlog-margin", Price: quotetest.Price("22.00")}, {Name: "ending", Price: quotetest.Price("22.90")}},
+				}},
+				{Name: "CORREIOS", Service: "PAC", Price: quotetest.Price("15.90"), Deadline: 7, Weights: volumeWeights, Pricing: &quote.Pricing{
+					OriginalPrice: quotetest.Price("15.90"),
+					Rules:         []quote.AppliedRule{{Name: "ending", Price: quotetest.Price("15.90")}},
+				}},
+			},
+		},
+		{
+			// 4 unidades de 50,00 somam 200,00 no carrinho
+			name:   "free shipping by cart value",
+			volume: quote.Volume{Category: 1, Amount: 4, UnitaryWeight: 0.25, Price: quotetest.Price("50.00"), SKU: "PROD123", Height: 0.05, Width: 0.05, Length: 0.05},
+			wantCarriers: []quote.Carrier{
+				{Name: "JADLOG", Service: ".Package", Price: 0, Deadline: 5, Weights: &quote.Weights{Real: 1, Cubed: 0.15, Billed: 1, CubageFactor: 300}, Pricing: &quote.Pricing{
+					OriginalPrice: quotetest.Price("20.00"),
+					Rules:         []quote.AppliedRule{{Name: "jadlog-margin", Price: quotetest.Price("22.00")}, {Name: "free-above-200", Price: 0}},
+				}},
+				{Name: "CORREIOS", Service: "PAC", Price: 0, Deadline: 7, Weights: &quote.Weights{Real: 1, Cubed: 0.15, Billed: 1, CubageFactor: 300}, Pricing: &quote.Pricing{
+					OriginalPrice: quotetest.Price("15.90"),
+					Rules:         []quote.AppliedRule{{Name: "free-above-200", Price: 0}},
+				}},
+			},
+		},
 	}
 
-	var totalPrice float64
-	carrierQuotesMap := make(map[string]*quote.CarrierQuotes)
-
-	for _, quoteItem := range quotes {
-		totalPrice += quoteItem.Price
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &quotetest.Client{Response: quotetest.Response(
+				quotetest.Offer("JADLOG", ".Package", "20.00", 5),
+				quotetest.Offer("CORREIOS", "PAC", "15.90", 7),
+			)}
+			repository := &quotetest.Repository{}
+			controller, err := quote.NewQuoteController(testConfig(), repository, client, quote.QuoteOptions{Rules: rules})
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
 
-		if _, exists := carrierQuotesMap[quoteItem.Name]; !exists {
-			carrierQuotesMap[quoteItem.Name] = &quote.CarrierQuotes{
-				CarrierName: quoteItem.Name,
+			response, err := controller.SimulateQuote(context.Background(), quoteRequest("12345678", tt.volume))
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
 			}
-		}
 
-		carrierQuote := carrierQuotesMap[quoteItem.Name]
-		carrierQuote.TotalQuotes++
-		carrierQuote.TotalPrice += quoteItem.Price
+			if !reflect.DeepEqual(response.Carriers, tt.wantCarriers) {
+				t.Errorf("Expected carriers %+v, got: %+v", tt.wantCarriers, response.Carriers)
+			}
+			if !reflect.DeepEqual(repository.Saved, tt.wantCarriers) {
+				t.Errorf("Expected saved quotes %+v, got: %+v", tt.wantCarriers, repository.Saved)
+			}
+		})
 	}
+}
 
-	var cheapestShipping, highestShipping float64
-	for _, cq := range carrierQuotesMap {
-		cq.AveragePrice = cq.TotalPrice / float64(cq.TotalQuotes)
-		if cheapestShipping == 0 || cq.AveragePrice < cheapestShipping {
-			cheapestShipping = cq.AveragePrice
-		}
-		if cq.AveragePrice > highestShipping {
-			highestShipping = cq.AveragePrice
-		}
+// Regras recarregadas valem a partir da cotação seguinte, e nil desliga o ajuste
+func TestSetPricingRules(t *testing.T) {
+	markup, err := pricing.New([]pricing.Rule{
+		{Name: "margin", Then: pricing.Action{Type: pricing.ActionMarkup, Percent: 10}},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
 	}
 
-	carrierQuotesSlice := make([]quote.CarrierQuotes, 0, len(carrierQuotesMap))
-	for _, cq := range carrierQuotesMap {
-		carrierQuotesSlice = append(carrierQuotesSlice, *cq)
+	client := &quotetest.Client{Response: quotetest.Response(quotetest.Offer("CORREIOS", "PAC", "20.00", 7))}
+	controller, err := quote.NewQuoteController(testConfig(), &quotetest.Repository{}, client, quote.QuoteOptions{})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
 	}
 
-	return quote.QuoteMetrics{
-		CarrierQuotes:    carrierQuotesSlice,
-		CheapestShipping: cheapestShipping,
-		HighestShipping:  highestShipping,
-	}, nil
-}
+	for _, step := range []struct {
+		rules *pricing.Engine
+		want  string
+	}{
+		{rules: nil, want: "20.00"},
+		{rules: markup, want: "22.00"},
+		{rules: nil, want: "20.00"},
+	} {
+		controller.SetPricingRules(step.rules)
 
-func setupController(mockRepo *MockQuoteRepository, mockAPI *MockFastDeliveryAPI) *TestableQuoteController {
-	cfg := &config.Config{
-		FastDeliveryAPISenderCNPJ:   "12345678901234",
-		FastDeliveryAPIToken:        "test-token-12345678901234567890",
-		FastDeliveryAPIPlatformCode: "platform-123",
-		FastDeliveryAPIZipCode:      12345678,
-	}
+		response, err := controller.SimulateQuote(context.Background(), quoteRequest("12345678", quote.Volume{Category: 1, Amount: 1, UnitaryWeight: 1, Price: quotetest.Price("50.00"), SKU: "PROD123", Height: 0.1, Width: 0.1, Length: 0.1}))
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
 
-	return NewTestableQuoteController(cfg, mockRepo, mockAPI)
+		if got := response.Carriers[0].Price; got != quotetest.Price(step.want) {
+			t.Errorf("Expected price %s with %d rules, got: %s", step.want, step.rules.Len(), got)
+		}
+	}
 }
 
-// Testes para SimulateQuote
-func TestSimulateQuote_Success(t *testing.T) {
-	mockRepo := &MockQuoteRepository{}
-	mockAPI := &MockFastDeliveryAPI{
-		simulateQuoteFunc: func(request models.QuoteRequest) (*models.QuoteResponse, error) {
-			return &models.QuoteResponse{
-				Dispatchers: []models.DispatcherResponse{
-					{
-						Offers: []models.Offer{
-							{
-								Carrier: models.Carrier{
-									Name: "Transportadora A",
-								},
-								Service:    "Expresso",
-								FinalPrice: 25.50,
-								DeliveryTime: models.DeliveryTime{
-									Days: 3,
-								},
-							},
-							{
-								Carrier: models.Carrier{
-									Name: "Transportadora B",
-								},
-								Service:    "Normal",
-								FinalPrice: 15.75,
-								DeliveryTime: models.DeliveryTime{
-									Days: 7,
-								},
-							},
-						},
-					},
-				},
-			}, nil
-		},
+// As datas de entrega contam dias úteis a partir do despacho, de SP para o RJ
+func TestSimulateQuote_DeliveryDate(t *testing.T) {
+	holidays, err := calendar.Embedded()
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
 	}
-
-	controller := setupController(mockRepo, mockAPI)
-
-	request := quote.QuoteRequest{
-		Recipient: quote.Recipient{
-			Address: quote.Address{
-				ZipCode: "12345678",
-			},
-		},
-		Volumes: []quote.Volume{
-			{
-				Category:      1,
-				Amount:        2,
-				UnitaryWeight: 1.5,
-				Price:         100.0,
-				SKU:           "PROD123",
-				Height:        10.0,
-				Width:         15.0,
-				Length:        20.0,
-			},
-		},
+	location, _ := time.LoadLocation("America/Sao_Paulo")
+	deliveryCalendar, err := calendar.New(holidays, location, 14*time.Hour)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
 	}
 
-	response, err := controller.SimulateQuote(request)
-
+	offer := quotetest.Offer("JADLOG", ".Package", "20.00", 5)
+	offer.DeliveryTime.EstimatedDate = "2025-03-17"
+	client := &quotetest.Client{Response: quotetest.Response(offer, quotetest.Offer("CORREIOS", "PAC", "15.90", 7))}
+	repository := &quotetest.Repository{}
+	controller, err := quote.NewQuoteController(testConfig(), repository, client, quote.QuoteOptions{Calendar: deliveryCalendar})
 	if err != nil {
 		t.Fatalf("Expected no error, got: %v", err)
 	}
 
-	if response == nil {
-		t.Fatal("Expected response, got nil")
+	before := time.Now()
+	response, err := controller.SimulateQuote(context.Background(), quoteRequest("20040002"))
+	after := time.Now()
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
 	}
 
 	if len(response.Carriers) != 2 {
-		t.Fatalf("Expected 2 carriers, got: %d", len(response.Carriers))
-	}
-
-	expectedCarriers := []quote.Carrier{
-		{Name: "Transportadora A", Service: "Expresso", Price: 25.50, Deadline: 3},
-		{Name: "Transportadora B", Service: "Normal", Price: 15.75, Deadline: 7},
+		t.Fatalf("Expected 2 carriers, got: %+v", response.Carriers)
 	}
 
-	for i, carrier := range response.Carriers {
-		if carrier.Name != expectedCarriers[i].Name {
-			t.Errorf("Expected carrier name %s, got: %s", expectedCarriers[i].Name, carrier.Name)
+	for _, carrier := range response.Carriers {
+		// A cotação pode cruzar o horário de corte, então as duas datas são aceitas
+		want := []string{
+			deliveryCalendar.DeliveryDate(before, "SP", "RJ", carrier.Deadline).Format(calendar.DateLayout),
+			deliveryCalendar.DeliveryDate(after, "SP", "RJ", carrier.Deadline).Format(calendar.DateLayout),
 		}
-		if carrier.Price != expectedCarriers[i].Price {
-			t.Errorf("Expected price %.2f, got: %.2f", expectedCarriers[i].Price, carrier.Price)
+		if carrier.DeliveryDate != want[0] && carrier.DeliveryDate != want[1] {
+			t.Errorf("Expected %s to be delivered on %s, got: %s", carrier.Name, want[0], carrier.DeliveryDate)
 		}
 	}
-}
-
-func TestSimulateQuote_InvalidZipCode(t *testing.T) {
-	mockRepo := &MockQuoteRepository{}
-	mockAPI := &MockFastDeliveryAPI{}
-
-	controller := setupController(mockRepo, mockAPI)
-
-	request := quote.QuoteRequest{
-		Recipient: quote.Recipient{
-			Address: quote.Address{
-				ZipCode: "invalid",
-			},
-		},
-		Volumes: []quote.Volume{
-			{
-				Category:      1,
-				Amount:        1,
-				UnitaryWeight: 1.0,
-				Price:         50.0,
-				SKU:           "PROD123",
-				Height:        10.0,
-				Width:         10.0,
-				Length:        10.0,
-			},
-		},
-	}
-
-	response, err := controller.SimulateQuote(request)
 
-	if err == nil {
-		t.Fatal("Expected error for invalid zipcode, got nil")
+	if response.Carriers[0].UpstreamDeliveryDate != "2025-03-17" || response.Carriers[1].UpstreamDeliveryDate != "" {
+		t.Errorf("Expected the upstream dates to be kept, got: %+v", response.Carriers)
 	}
 
-	if response != nil {
-		t.Fatal("Expected nil response for invalid zipcode")
+	if repository.Saved[0].DeliveryDate != response.Carriers[0].DeliveryDate {
+		t.Errorf("Expected the saved quote to carry the delivery date, got: %+v", repository.Saved[0])
 	}
 }
 
-func TestSimulateQuote_APIError(t *testing.T) {
-	mockRepo := &MockQuoteRepository{}
-	mockAPI := &MockFastDeliveryAPI{
-		simulateQuoteFunc: func(request models.QuoteRequest) (*models.QuoteResponse, error) {
-			return nil, errors.New("API connection error")
+// As políticas escondem ou rebaixam transportadoras pelo destino e pela categoria
+func TestSimulateQuote_Policies(t *testing.T) {
+	offers := []models.Offer{
+		quotetest.Offer("JADLOG", ".Package", "18.00", 5),
+		quotetest.Offer("CORREIOS", "PAC", "15.90", 7),
+		quotetest.Offer("LOGGI", "Expresso", "21.00", 2),
+	}
+
+	tests := []struct {
+		name            string
+		zipcode         string
+		policies        *quotetest.Policies
+		wantCarriers    []string
+		wantUnavailable []quote.Unavailable
+	}{
+		{
+			name:         "no policies",
+			zipcode:      "69005000",
+			policies:     &quotetest.Policies{},
+			wantCarriers: []string{"JADLOG", "CORREIOS", "LOGGI"},
 		},
-	}
-
-	controller := setupController(mockRepo, mockAPI)
-
-	request := quote.QuoteRequest{
-		Recipient: quote.Recipient{
-			Address: quote.Address{
-				ZipCode: "12345678",
-			},
+		{
+			name:    "hidden and deprioritized in the state",
+			zipcode: "69005000",
+			policies: &quotetest.Policies{Policies: []policy.Policy{
+				{Name: "jadlog-am", Action: policy.ActionHide, Carrier: "JADLOG", UF: "AM"},
+				{Name: "correios-am", Action: policy.ActionDeprioritize, Carrier: "CORREIOS", UF: "AM"},
+			}},
+			wantCarriers: []string{"LOGGI", "CORREIOS"},
 		},
-		Volumes: []quote.Volume{
-			{
-				Category:      1,
-				Amount:        1,
-				UnitaryWeight: 1.0,
-				Price:         50.0,
-				SKU:           "PROD123",
-				Height:        10.0,
-				Width:         10.0,
-				Length:        10.0,
-			},
+		{
+			name:    "policies for another state",
+			zipcode: "01311000",
+			policies: &quotetest.Policies{Policies: []policy.Policy{
+				{Name: "jadlog-am", Action: policy.ActionHide, Carrier: "JADLOG", UF: "AM"},
+			}},
+			wantCarriers: []string{"JADLOG", "CORREIOS", "LOGGI"},
+		},
+		{
+			name:    "by product category",
+			zipcode: "01311000",
+			policies: &quotetest.Policies{Policies: []policy.Policy{
+				{Name: "loggi-categoria-1", Action: policy.ActionHide, Carrier: "LOGGI", Category: 1},
+			}},
+			wantCarriers: []string{"JADLOG", "CORREIOS"},
+		},
+		{
+			name:    "every offer hidden",
+			zipcode: "01311000",
+			policies: &quotetest.Policies{Policies: []policy.Policy{
+				{Name: "tudo-sp", Action: policy.ActionHide, Service: ".Package", UF: "SP"},
+				{Name: "correios", Action: policy.ActionHide, Carrier: "CORREIOS"},
+				{Name: "loggi", Action: policy.ActionHide, Carrier: "LOGGI"},
+			}},
+			wantCarriers:    []string{},
+			wantUnavailable: []quote.Unavailable{{Reason: quote.ReasonHidden}},
+		},
+		{
+			// Uma falha ao listar as políticas não pode derrubar o checkout
+			name:         "policies unavailable",
+			zipcode:      "69005000",
+			policies:     &quotetest.Policies{Err: errors.New("database down")},
+			wantCarriers: []string{"JADLOG", "CORREIOS", "LOGGI"},
 		},
 	}
 
-	response, err := controller.SimulateQuote(request)
-
-	if err == nil {
-		t.Fatal("Expected API error, got nil")
-	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &quotetest.Client{Response: quotetest.Response(offers...)}
+			repository := &quotetest.Repository{}
+			controller, err := quote.NewQuoteController(testConfig(), repository, client, quote.QuoteOptions{Policies: tt.policies})
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
 
-	if response != nil {
-		t.Fatal("Expected nil response for API error")
-	}
+			response, err := controller.SimulateQuote(context.Background(), quoteRequest(tt.zipcode))
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
 
-	expectedError := "API connection error"
-	if err.Error() != expectedError {
-		t.Errorf("Expected error message '%s', got: '%s'", expectedError, err.Error())
-	}
-}
+			carriers := []string{}
+			for _, c := range response.Carriers {
+				carriers = append(carriers, c.Name)
+			}
+			if !reflect.DeepEqual(carriers, tt.wantCarriers) {
+				t.Errorf("Expected carriers %v, got: %v", tt.wantCarriers, carriers)
+			}
 
-// Testes para QuoteMetrics
-func TestQuoteMetrics_Success(t *testing.T) {
-	mockRepo := &MockQuoteRepository{
-		findQuotesByLastQuoteFunc: func(lastQuotes int) ([]quote.Carrier, error) {
-			return []quote.Carrier{
-				{Name: "Transportadora A", Price: 20.0},
-				{Name: "Transportadora A", Price: 30.0},
-				{Name: "Transportadora B", Price: 15.0},
-				{Name: "Transportadora B", Price: 25.0},
-				{Name: "Transportadora B", Price: 35.0},
-			}, nil
-		},
-	}
-	mockAPI := &MockFastDeliveryAPI{}
+			if !reflect.DeepEqual(response.Unavailable, tt.wantUnavailable) {
+				t.Errorf("Expected unavailable %+v, got: %+v", tt.wantUnavailable, response.Unavailable)
+			}
 
-	controller := setupController(mockRepo, mockAPI)
+			// Ofertas escondidas não entram nas métricas
+			if len(repository.Saved) != len(tt.wantCarriers) {
+				t.Errorf("Expected %d saved quotes, got: %d", len(tt.wantCarriers), len(repository.Saved))
+			}
 
-	metrics, err := controller.QuoteMetrics(10)
+			// A resposta do Frete Rápido não é alterada
+			if len(client.Response.Dispatchers[0].Offers) != len(offers) {
+				t.Errorf("Expected upstream response to be left untouched, got: %+v", client.Response)
+			}
+		})
+	}
+}
 
+// Sem tenant no contexto, o embarcador vem da configuração
+func TestSimulateQuote_ShipperFromConfig(t *testing.T) {
+	client := &quotetest.Client{Response: quotetest.Response(quotetest.Offer("Transportadora Teste", "Teste", "10.00", 5))}
+	controller, err := quote.NewQuoteController(testConfig(), &quotetest.Repository{}, client, quote.QuoteOptions{})
 	if err != nil {
 		t.Fatalf("Expected no error, got: %v", err)
 	}
 
-	if len(metrics.CarrierQuotes) != 2 {
-		t.Fatalf("Expected 2 carrier quotes, got: %d", len(metrics.CarrierQuotes))
+	if _, err := controller.SimulateQuote(context.Background(), quoteRequest("11111111")); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
 	}
 
-	// Verificar se as métricas estão corretas
-	if metrics.CheapestShipping != 25.0 { // (15+25+35)/3 = 25.0
-		t.Errorf("Expected cheapest shipping 25.0, got: %.2f", metrics.CheapestShipping)
-	}
+	request := client.LastRequest()
 
-	if metrics.HighestShipping != 25.0 { // (20+30)/2 = 25.0
-		t.Errorf("Expected highest shipping 25.0, got: %.2f", metrics.HighestShipping)
+	expectedShipper := models.Shipper{
+		RegisteredNumber: "12345678901234",
+		Token:            "test-token-12345678901234567890",
+		PlatformCode:     "platform-123",
+	}
+	if request.Shipper != expectedShipper {
+		t.Errorf("Expected shipper %+v, got: %+v", expectedShipper, request.Shipper)
 	}
 
-	// Encontrar e verificar transportadora A
-	var transportadoraA *quote.CarrierQuotes
-	for _, cq := range metrics.CarrierQuotes {
-		if cq.CarrierName == "Transportadora A" {
-			transportadoraA = &cq
-			break
-		}
+	expectedRecipient := models.Recipient{Type: 0, Country: "BRA", Zipcode: 11111111}
+	if request.Recipient != expectedRecipient {
+		t.Errorf("Expected recipient %+v, got: %+v", expectedRecipient, request.Recipient)
 	}
 
-	if transportadoraA == nil {
-		t.Fatal("Transportadora A not found in metrics")
+	if len(request.Dispatchers) != 1 {
+		t.Fatalf("Expected 1 dispatcher, got: %d", len(request.Dispatchers))
 	}
 
-	if transportadoraA.TotalQuotes != 2 {
-		t.Errorf("Expected 2 quotes for Transportadora A, got: %d", transportadoraA.TotalQuotes)
+	if request.Dispatchers[0].RegisteredNumber != "12345678901234" || request.Dispatchers[0].Zipcode != 12345678 {
+		t.Errorf("Expected dispatcher from configuration, got: %+v", request.Dispatchers[0])
 	}
 
-	if transportadoraA.AveragePrice != 25.0 {
-		t.Errorf("Expected average price 25.0 for Transportadora A, got: %.2f", transportadoraA.AveragePrice)
+	if len(request.SimulationType) != 1 || request.SimulationType[0] != 0 {
+		t.Errorf("Expected simulation type [0], got: %v", request.SimulationType)
 	}
 }
 
-func TestQuoteMetrics_EmptyQuotes(t *testing.T) {
-	mockRepo := &MockQuoteRepository{
-		findQuotesByLastQuoteFunc: func(lastQuotes int) ([]quote.Carrier, error) {
-			return []quote.Carrier{}, nil
-		},
+// Com tenant autenticado, as credenciais e a origem são as do tenant
+func TestSimulateQuote_ShipperFromTenant(t *testing.T) {
+	client := &quotetest.Client{Response: quotetest.Response(quotetest.Offer("Transportadora Teste", "Teste", "10.00", 5))}
+	controller, err := quote.NewQuoteController(testConfig(), &quotetest.Repository{}, client, quote.QuoteOptions{})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
 	}
-	mockAPI := &MockFastDeliveryAPI{}
 
-	controller := setupController(mockRepo, mockAPI)
+	ctx := tenant.NewContext(context.Background(), tenant.Tenant{
+		ID:            7,
+		Name:          "loja",
+		ShipperCNPJ:   "98765432109876",
+		ShipperToken:  "tenant-token-1234567890123456789",
+		PlatformCode:  "tenant-platform",
+		OriginZipCode: 87654321,
+	})
 
-	metrics, err := controller.QuoteMetrics(10)
-
-	if err != nil {
+	if _, err := controller.SimulateQuote(ctx, quoteRequest("11111111")); err != nil {
 		t.Fatalf("Expected no error, got: %v", err)
 	}
 
-	if len(metrics.CarrierQuotes) != 0 {
-		t.Errorf("Expected 0 carrier quotes for empty data, got: %d", len(metrics.CarrierQuotes))
-	}
+	request := client.LastRequest()
 
-	if metrics.CheapestShipping != 0 {
-		t.Errorf("Expected cheapest shipping 0 for empty data, got: %.2f", metrics.CheapestShipping)
+	if request.Shipper.RegisteredNumber != "98765432109876" || request.Shipper.Token != "tenant-token-1234567890123456789" {
+		t.Errorf("Expected tenant credentials, got: %+v", request.Shipper)
 	}
 
-	if metrics.HighestShipping != 0 {
-		t.Errorf("Expected highest shipping 0 for empty data, got: %.2f", metrics.HighestShipping)
+	if request.Dispatchers[0].Zipcode != 87654321 {
+		t.Errorf("Expected tenant origin zipcode 87654321, got: %d", request.Dispatchers[0].Zipcode)
 	}
 }
 
-func TestQuoteMetrics_RepositoryError(t *testing.T) {
-	mockRepo := &MockQuoteRepository{
-		findQuotesByLastQuoteFunc: func(lastQuotes int) ([]quote.Carrier, error) {
-			return nil, errors.New("database connection error")
-		},
+// Os volumes são repassados um a um, com a categoria convertida para texto
+func TestSimulateQuote_MapsVolumes(t *testing.T) {
+	client := &quotetest.Client{Response: quotetest.Response(quotetest.Offer("Transportadora X", "Premium", "99.99", 1))}
+	controller, err := quote.NewQuoteController(testConfig(), &quotetest.Repository{}, client, quote.QuoteOptions{})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
 	}
-	mockAPI := &MockFastDeliveryAPI{}
-
-	controller := setupController(mockRepo, mockAPI)
 
-	metrics, err := controller.QuoteMetrics(10)
+	request := quoteRequest("87654321",
+		quote.Volume{Category: 1, Amount: 5, UnitaryWeight: 2.5, Price: quotetest.Price("200.00"), SKU: "PROD001", Height: 15.0, Width: 25.0, Length: 30.0},
+		quote.Volume{Category: 2, Amount: 3, UnitaryWeight: 1.8, Price: quotetest.Price("150.00"), SKU: "PROD002", Height: 12.0, Width: 20.0, Length: 25.0},
+		quote.Volume{Category: 3, Amount: 1, UnitaryWeight: 5.0, Price: quotetest.Price("500.00"), SKU: "PROD003", Height: 20.0, Width: 30.0, Length: 40.0},
+	)
 
-	if err == nil {
-		t.Fatal("Expected repository error, got nil")
+	if _, err := controller.SimulateQuote(context.Background(), request); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
 	}
 
-	expectedError := "failed to find last quotes: database connection error"
-	if err.Error() != expectedError {
-		t.Errorf("Expected error message '%s', got: '%s'", expectedError, err.Error())
+	expected := []models.Volume{
+		{Category: "1", Amount: 5, UnitaryWeight: 2.5, UnitaryPrice: quotetest.Price("200.00"), SKU: "PROD001", Height: 15.0, Width: 25.0, Length: 30.0},
+		{Category: "2", Amount: 3, UnitaryWeight: 1.8, UnitaryPrice: quotetest.Price("150.00"), SKU: "PROD002", Height: 12.0, Width: 20.0, Length: 25.0},
+		{Category: "3", Amount: 1, UnitaryWeight: 5.0, UnitaryPrice: quotetest.Price("500.00"), SKU: "PROD003", Height: 20.0, Width: 30.0, Length: 40.0},
 	}
 
-	// Verificar se retorna estrutura vazia em caso de erro
-	if len(metrics.CarrierQuotes) != 0 {
-		t.Errorf("Expected empty carrier quotes on error, got: %d", len(metrics.CarrierQuotes))
+	if volumes := client.LastRequest().Dispatchers[0].Volumes; !reflect.DeepEqual(volumes, expected) {
+		t.Errorf("Expected volumes %+v, got: %+v", expected, volumes)
 	}
 }
 
-// Teste adicional para SimulateQuote - erro ao salvar no repository
-func TestSimulateQuote_SaveQuoteError(t *testing.T) {
-	mockRepo := &MockQuoteRepository{
-		saveQuoteFunc: func(carrier quote.Carrier) error {
-			return errors.New("database save error")
-		},
-	}
-	mockAPI := &MockFastDeliveryAPI{
-		simulateQuoteFunc: func(request models.QuoteRequest) (*models.QuoteResponse, error) {
-			return &models.QuoteResponse{
-				Dispatchers: []models.DispatcherResponse{
-					{
-						Offers: []models.Offer{
-							{
-								Carrier: models.Carrier{
-									Name: "Transportadora A",
-								},
-								Service:    "Expresso",
-								FinalPrice: 25.50,
-								DeliveryTime: models.DeliveryTime{
-									Days: 3,
-								},
-							},
-						},
-					},
-				},
-			}, nil
-		},
-	}
-
-	controller := setupController(mockRepo, mockAPI)
+// A composição é sempre pedida e salva, mas só é devolvida quando o cliente pede
+func TestSimulateQuote_Composition(t *testing.T) {
+	offer := quotetest.Offer("Transportadora A", "Expresso", "25.50", 3)
+	offer.Composition = models.Composition{
+		FreightWeight: quotetest.Price("20.00"),
+		SubTotal1:     models.SubTotal1{Gris: quotetest.Price("0.45"), AdValorem: quotetest.Price("1.05")},
+		SubTotal3:     models.SubTotal3{ICMS: quotetest.Price("4.00")},
+	}
+
+	wantFees := []quote.Fee{
+		{Name: "freight_weight", Amount: quotetest.Price("20.00")},
+		{Name: "ad_valorem", Amount: quotetest.Price("1.05")},
+		{Name: "gris", Amount: quotetest.Price("0.45")},
+		{Name: "icms", Amount: quotetest.Price("4.00")},
+	}
+
+	for _, include := range []bool{false, true} {
+		t.Run(fmt.Sprintf("include %t", include), func(t *testing.T) {
+			client := &quotetest.Client{Response: quotetest.Response(offer)}
+			repository := &quotetest.Repository{}
+			controller, err := quote.NewQuoteController(testConfig(), repository, client, quote.QuoteOptions{})
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
 
-	request := quote.QuoteRequest{
-		Recipient: quote.Recipient{
-			Address: quote.Address{
-				ZipCode: "12345678",
-			},
-		},
-		Volumes: []quote.Volume{
-			{
-				Category:      1,
-				Amount:        1,
-				UnitaryWeight: 1.0,
-				Price:         50.0,
-				SKU:           "PROD123",
-				Height:        10.0,
-				Width:         10.0,
-				Length:        10.0,
-			},
-		},
-	}
+			request := quoteRequest("12345678")
+			request.IncludeComposition = include
 
-	response, err := controller.SimulateQuote(request)
+			response, err := controller.SimulateQuote(context.Background(), request)
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
 
-	if err == nil {
-		t.Fatal("Expected save error, got nil")
-	}
+			if !client.LastRequest().Returns.Composition {
+				t.Error("Expected the composition to be requested upstream")
+			}
 
-	if response != nil {
-		t.Fatal("Expected nil response for save error")
-	}
+			if len(repository.Saved) != 1 || !reflect.DeepEqual(repository.Saved[0].Composition, wantFees) {
+				t.Errorf("Expected saved fees %+v, got: %+v", wantFees, repository.Saved)
+			}
 
-	expectedError := "failed to save quote: database save error"
-	if err.Error() != expectedError {
-		t.Errorf("Expected error message '%s', got: '%s'", expectedError, err.Error())
+			got := response.Carriers[0].Composition
+			if include && !reflect.DeepEqual(got, wantFees) {
+				t.Errorf("Expected fees %+v, got: %+v", wantFees, got)
+			}
+			if !include && got != nil {
+				t.Errorf("Expected no fees in the response, got: %+v", got)
+			}
+		})
 	}
 }
 
-// Teste adicional para QuoteMetrics - apenas uma transportadora
-func TestQuoteMetrics_SingleCarrier(t *testing.T) {
-	mockRepo := &MockQuoteRepository{
-		findQuotesByLastQuoteFunc: func(lastQuotes int) ([]quote.Carrier, error) {
-			return []quote.Carrier{
-				{Name: "Transportadora Única", Price: 30.0},
-				{Name: "Transportadora Única", Price: 40.0},
-				{Name: "Transportadora Única", Price: 50.0},
-			}, nil
-		},
-	}
-	mockAPI := &MockFastDeliveryAPI{}
+// O peso taxado usa o fator de cubagem do modal e é comparado com o da transportadora
+func TestSimulateQuote_Weights(t *testing.T) {
+	air := quotetest.Offer("Transportadora Aérea", "Expresso", "80.00", 1)
+	air.Modal = "Aéreo"
+	air.Weights = models.Weights{Real: 2, Cubed: 6, Used: 6}
 
-	controller := setupController(mockRepo, mockAPI)
+	road := quotetest.Offer("Transportadora Rodoviária", "Normal", "30.00", 5)
+	road.Modal = "Rodoviário"
+	road.Weights = models.Weights{Real: 2, Cubed: 10.8, Used: 10.8}
 
-	metrics, err := controller.QuoteMetrics(10)
+	cfg := testConfig()
+	cfg.FreightCubageFactorsModal = "Aéreo=166.67"
 
+	client := &quotetest.Client{Response: quotetest.Response(air, road)}
+	controller, err := quote.NewQuoteController(cfg, &quotetest.Repository{}, client, quote.QuoteOptions{})
 	if err != nil {
 		t.Fatalf("Expected no error, got: %v", err)
 	}
 
-	if len(metrics.CarrierQuotes) != 1 {
-		t.Fatalf("Expected 1 carrier quote, got: %d", len(metrics.CarrierQuotes))
-	}
-
-	carrier := metrics.CarrierQuotes[0]
-	expectedAverage := 40.0 // (30+40+50)/3
+	// 0,6 x 0,3 x 0,2 = 0,036 m³
+	volume := quote.Volume{Category: 1, Amount: 1, UnitaryWeight: 2, Price: quotetest.Price("100.00"), SKU: "PROD1", Height: 0.6, Width: 0.3, Length: 0.2}
 
-	if carrier.CarrierName != "Transportadora Única" {
-		t.Errorf("Expected carrier name 'Transportadora Única', got: %s", carrier.CarrierName)
+	response, err := controller.SimulateQuote(context.Background(), quoteRequest("12345678", volume))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
 	}
 
-	if carrier.TotalQuotes != 3 {
-		t.Errorf("Expected 3 total quotes, got: %d", carrier.TotalQuotes)
+	want := []*quote.Weights{
+		{Real: 2, Cubed: 6, Billed: 6, CubageFactor: 166.67, UpstreamBilled: 6},
+		{Real: 2, Cubed: 10.8, Billed: 10.8, CubageFactor: 300, UpstreamBilled: 10.8},
 	}
 
-	if carrier.AveragePrice != expectedAverage {
-		t.Errorf("Expected average price %.2f, got: %.2f", expectedAverage, carrier.AveragePrice)
+	for i, carrier := range response.Carriers {
+		if !reflect.DeepEqual(carrier.Weights, want[i]) {
+			t.Errorf("Expected weights %+v for %s, got: %+v", want[i], carrier.Name, carrier.Weights)
+		}
 	}
 
-	// Quando há apenas uma transportadora, cheapest e highest devem ser iguais
-	if metrics.CheapestShipping != expectedAverage {
-		t.Errorf("Expected cheapest shipping %.2f, got: %.2f", expectedAverage, metrics.CheapestShipping)
-	}
+	// A transportadora cobra por um peso diferente do calculado
+	road.Weights.Used = 15
+	client.Response = quotetest.Response(road)
 
-	if metrics.HighestShipping != expectedAverage {
-		t.Errorf("Expected highest shipping %.2f, got: %.2f", expectedAverage, metrics.HighestShipping)
+	response, err = controller.SimulateQuote(context.Background(), quoteRequest("12345678", volume))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
 	}
-}
-
-// Teste adicional para SimulateQuote - múltiplos volumes
-func TestSimulateQuote_MultipleVolumes(t *testing.T) {
-	mockRepo := &MockQuoteRepository{}
-	mockAPI := &MockFastDeliveryAPI{
-		simulateQuoteFunc: func(request models.QuoteRequest) (*models.QuoteResponse, error) {
-			// Verificar se os volumes foram convertidos corretamente
-			if len(request.Dispatchers) != 1 {
-				t.Errorf("Expected 1 dispatcher, got: %d", len(request.Dispatchers))
-			}
 
-			if len(request.Dispatchers[0].Volumes) != 3 {
-				t.Errorf("Expected 3 volumes, got: %d", len(request.Dispatchers[0].Volumes))
-			}
-
-			// Verificar conversão de categoria para string
-			expectedCategories := []string{"1", "2", "3"}
-			for i, volume := range request.Dispatchers[0].Volumes {
-				if volume.Category != expectedCategories[i] {
-					t.Errorf("Expected category '%s', got: '%s'", expectedCategories[i], volume.Category)
-				}
-			}
-
-			return &models.QuoteResponse{
-				Dispatchers: []models.DispatcherResponse{
-					{
-						Offers: []models.Offer{
-							{
-								Carrier: models.Carrier{
-									Name: "Transportadora X",
-								},
-								Service:    "Premium",
-								FinalPrice: 99.99,
-								DeliveryTime: models.DeliveryTime{
-									Days: 1,
-								},
-							},
-						},
-					},
-				},
-			}, nil
-		},
+	if w := response.Carriers[0].Weights; !w.Discrepancy || w.UpstreamBilled != 15 {
+		t.Errorf("Expected a weight discrepancy, got: %+v", w)
 	}
+}
 
-	controller := setupController(mockRepo, mockAPI)
-
-	request := quote.QuoteRequest{
-		Recipient: quote.Recipient{
-			Address: quote.Address{
-				ZipCode: "87654321",
-			},
-		},
-		Volumes: []quote.Volume{
-			{
-				Category:      1,
-				Amount:        5,
-				UnitaryWeight: 2.5,
-				Price:         200.0,
-				SKU:           "PROD001",
-				Height:        15.0,
-				Width:         25.0,
-				Length:        30.0,
-			},
-			{
-				Category:      2,
-				Amount:        3,
-				UnitaryWeight: 1.8,
-				Price:         150.0,
-				SKU:           "PROD002",
-				Height:        12.0,
-				Width:         20.0,
-				Length:        25.0,
-			},
-			{
-				Category:      3,
-				Amount:        1,
-				UnitaryWeight: 5.0,
-				Price:         500.0,
-				SKU:           "PROD003",
-				Height:        20.0,
-				Width:         30.0,
-				Length:        40.0,
-			},
-		},
+// Cada cotação publica um evento de sucesso ou de falha
+func TestSimulateQuote_PublishesEvents(t *testing.T) {
+	client := &quotetest.Client{Response: quotetest.Response(quotetest.Offer("Transportadora A", "Expresso", "25.50", 3))}
+	publisher := &quotetest.Publisher{}
+	controller, err := quote.NewQuoteController(testConfig(), &quotetest.Repository{}, client, quote.QuoteOptions{Publisher: publisher})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
 	}
 
-	response, err := controller.SimulateQuote(request)
+	ctx := tenant.NewContext(context.Background(), tenant.Tenant{ID: 7, Name: "loja"})
 
-	if err != nil {
+	if _, err := controller.SimulateQuote(ctx, quoteRequest("12345678")); err != nil {
 		t.Fatalf("Expected no error, got: %v", err)
 	}
 
-	if response == nil {
-		t.Fatal("Expected response, got nil")
+	client.Err = errors.New("API connection error")
+	if _, err := controller.SimulateQuote(ctx, quoteRequest("12345678")); err == nil {
+		t.Fatal("Expected error, got nil")
 	}
 
-	if len(response.Carriers) != 1 {
-		t.Fatalf("Expected 1 carrier, got: %d", len(response.Carriers))
+	if len(publisher.Events) != 2 {
+		t.Fatalf("Expected 2 events, got: %d", len(publisher.Events))
 	}
 
-	carrier := response.Carriers[0]
-	if carrier.Name != "Transportadora X" {
-		t.Errorf("Expected carrier name 'Transportadora X', got: %s", carrier.Name)
-	}
+	created, failed := publisher.Events[0], publisher.Events[1]
 
-	if carrier.Service != "Premium" {
-		t.Errorf("Expected service 'Premium', got: %s", carrier.Service)
+	if created.Type != quote.EventQuoteCreated || created.Response == nil || created.Error != "" {
+		t.Errorf("Expected a quote.created event with response, got: %+v", created)
 	}
 
-	if carrier.Price != 99.99 {
-		t.Errorf("Expected price 99.99, got: %.2f", carrier.Price)
+	if failed.Type != quote.EventQuoteFailed || failed.Response != nil || failed.Error != "API connection error" {
+		t.Errorf("Expected a quote.failed event with error, got: %+v", failed)
 	}
 
-	if carrier.Deadline != 1 {
-		t.Errorf("Expected deadline 1 day, got: %d", carrier.Deadline)
+	for _, event := range publisher.Events {
+		if event.TenantID == nil || *event.TenantID != 7 {
+			t.Errorf("Expected tenant 7 on event, got: %v", event.TenantID)
+		}
 	}
 }
 
-// Teste para verificar se os dados de configuração são aplicados corretamente
-func TestSimulateQuote_ConfigurationMapping(t *testing.T) {
-	mockRepo := &MockQuoteRepository{}
-
-	// Mock que verifica se a configuração foi aplicada corretamente
-	mockAPI := &MockFastDeliveryAPI{
-		simulateQuoteFunc: func(request models.QuoteRequest) (*models.QuoteResponse, error) {
-			// Verificar se os dados do shipper estão corretos
-			if request.Shipper.RegisteredNumber != "12345678901234" {
-				t.Errorf("Expected shipper registered number '12345678901234', got: '%s'", request.Shipper.RegisteredNumber)
-			}
-
-			if request.Shipper.Token != "test-token-12345678901234567890" {
-				t.Errorf("Expected shipper token 'test-token-12345678901234567890', got: '%s'", request.Shipper.Token)
-			}
+func TestQuoteMetrics(t *testing.T) {
+	tests := []struct {
+		name         string
+		saved        []quote.Carrier
+		findErr      error
+		lastQuotes   int
+		wantErrText  string
+		wantCheapest string
+		wantHighest  string
+		wantCarriers map[string]quote.CarrierQuotes
+	}{
+		{
+			name: "averages per carrier",
+			saved: []quote.Carrier{
+				{Name: "Transportadora A", Price: quotetest.Price("20.00")},
+				{Name: "Transportadora A", Price: quotetest.Price("30.00")},
+				{Name: "Transportadora B", Price: quotetest.Price("15.00")},
+				{Name: "Transportadora B", Price: quotetest.Price("25.00")},
+				{Name: "Transportadora B", Price: quotetest.Price("35.00")},
+			},
+			lastQuotes:   10,
+			wantCheapest: "25.00", // (15+25+35)/3
+			wantHighest:  "25.00", // (20+30)/2
+			wantCarriers: map[string]quote.CarrierQuotes{
+				"Transportadora A": {CarrierName: "Transportadora A", TotalQuotes: 2, TotalPrice: quotetest.Price("50.00"), AveragePrice: quotetest.Price("25.00")},
+				"Transportadora B": {CarrierName: "Transportadora B", TotalQuotes: 3, TotalPrice: quotetest.Price("75.00"), AveragePrice: quotetest.Price("25.00")},
+			},
+		},
+		{
+			name: "single carrier",
+			saved: []quote.Carrier{
+				{Name: "Transportadora Única", Price: quotetest.Price("30.00")},
+				{Name: "Transportadora Única", Price: quotetest.Price("40.00")},
+				{Name: "Transportadora Única", Price: quotetest.Price("50.00")},
+			},
+			lastQuotes:   10,
+			wantCheapest: "40.00",
+			wantHighest:  "40.00",
+			wantCarriers: map[string]quote.CarrierQuotes{
+				"Transportadora Única": {CarrierName: "Transportadora Única", TotalQuotes: 3, TotalPrice: quotetest.Price("120.00"), AveragePrice: quotetest.Price("40.00")},
+			},
+		},
+		{
+			name: "only the last quotes",
+			saved: []quote.Carrier{
+				{Name: "Transportadora A", Price: quotetest.Price("100.00")},
+				{Name: "Transportadora B", Price: quotetest.Price("10.00")},
+				{Name: "Transportadora B", Price: quotetest.Price("20.00")},
+			},
+			lastQuotes:   2,
+			wantCheapest: "15.00",
+			wantHighest:  "15.00",
+			wantCarriers: map[string]quote.CarrierQuotes{
+				"Transportadora B": {CarrierName: "Transportadora B", TotalQuotes: 2, TotalPrice: quotetest.Price("30.00"), AveragePrice: quotetest.Price("15.00")},
+			},
+		},
+		{
+			name: "centavos are exact",
+			saved: []quote.Carrier{
+				{Name: "Transportadora A", Price: quotetest.Price("0.10")},
+				{Name: "Transportadora A", Price: quotetest.Price("0.20")},
+				{Name: "Transportadora B", Price: quotetest.Price("10.00")},
+				{Name: "Transportadora B", Price: quotetest.Price("10.00")},
+				{Name: "Transportadora B", Price: quotetest.Price("10.01")},
+			},
+			lastQuotes:   10,
+			wantCheapest: "0.15",
+			wantHighest:  "10.00", // 30.01/3 = 10.0033 arredonda para 10.00
+			wantCarriers: map[string]quote.CarrierQuotes{
+				"Transportadora A": {CarrierName: "Transportadora A", TotalQuotes: 2, TotalPrice: quotetest.Price("0.30"), AveragePrice: quotetest.Price("0.15")},
+				"Transportadora B": {CarrierName: "Transportadora B", TotalQuotes: 3, TotalPrice: quotetest.Price("30.01"), AveragePrice: quotetest.Price("10.00")},
+			},
+		},
+		{
+			name:         "no quotes",
+			wantCheapest: "0.00",
+			wantHighest:  "0.00",
+			lastQuotes:   10,
+			wantCarriers: map[string]quote.CarrierQuotes{},
+		},
+		{
+			name:        "repository error",
+			findErr:     errors.New("database connection error"),
+			lastQuotes:  10,
+			wantErrText: "failed to find last quotes: database connection error",
+		},
+	}
 
-			if request.Shipper.PlatformCode != "platform-123" {
-				t.Errorf("Expected platform code 'platform-123', got: '%s'", request.Shipper.PlatformCode)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repository := &quotetest.Repository{Saved: tt.saved, FindErr: tt.findErr}
+			controller, err := quote.NewQuoteController(testConfig(), repository, &quotetest.Client{}, quote.QuoteOptions{})
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
 			}
 
-			// Verificar dados do recipient
-			if request.Recipient.Type != 0 {
-				t.Errorf("Expected recipient type 0, got: %d", request.Recipient.Type)
-			}
+			metrics, err := controller.QuoteMetrics(context.Background(), tt.lastQuotes)
 
-			if request.Recipient.Country != "BRA" {
-				t.Errorf("Expected recipient country 'BRA', got: '%s'", request.Recipient.Country)
+			if tt.wantErrText != "" {
+				if err == nil || err.Error() != tt.wantErrText {
+					t.Errorf("Expected error message '%s', got: %v", tt.wantErrText, err)
+				}
+				if len(metrics.CarrierQuotes) != 0 {
+					t.Errorf("Expected empty metrics on error, got: %+v", metrics)
+				}
+				return
 			}
 
-			if request.Recipient.Zipcode != 11111111 {
-				t.Errorf("Expected recipient zipcode 11111111, got: %d", request.Recipient.Zipcode)
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
 			}
 
-			// Verificar dispatcher
-			if len(request.Dispatchers) != 1 {
-				t.Fatalf("Expected 1 dispatcher, got: %d", len(request.Dispatchers))
+			if metrics.CheapestShipping.String() != tt.wantCheapest {
+				t.Errorf("Expected cheapest shipping %s, got: %s", tt.wantCheapest, metrics.CheapestShipping)
 			}
 
-			dispatcher := request.Dispatchers[0]
-			if dispatcher.RegisteredNumber != "12345678901234" {
-				t.Errorf("Expected dispatcher registered number '12345678901234', got: '%s'", dispatcher.RegisteredNumber)
+			if metrics.HighestShipping.String() != tt.wantHighest {
+				t.Errorf("Expected highest shipping %s, got: %s", tt.wantHighest, metrics.HighestShipping)
 			}
 
-			if dispatcher.Zipcode != 12345678 {
-				t.Errorf("Expected dispatcher zipcode 12345678, got: %d", dispatcher.Zipcode)
+			if len(metrics.CarrierQuotes) != len(tt.wantCarriers) {
+				t.Fatalf("Expected %d carrier quotes, got: %d", len(tt.wantCarriers), len(metrics.CarrierQuotes))
 			}
 
-			// Verificar simulation type
-			if len(request.SimulationType) != 1 || request.SimulationType[0] != 0 {
-				t.Errorf("Expected simulation type [0], got: %v", request.SimulationType)
+			for _, cq := range metrics.CarrierQuotes {
+				if want := tt.wantCarriers[cq.CarrierName]; cq != want {
+					t.Errorf("Expected %+v, got: %+v", want, cq)
+				}
 			}
-
-			return &models.QuoteResponse{
-				Dispatchers: []models.DispatcherResponse{
-					{
-						Offers: []models.Offer{
-							{
-								Carrier: models.Carrier{
-									Name: "Transportadora Teste",
-								},
-								Service:    "Teste",
-								FinalPrice: 10.00,
-								DeliveryTime: models.DeliveryTime{
-									Days: 5,
-								},
-							},
-						},
-					},
-				},
-			}, nil
-		},
+		})
 	}
+}
 
-	controller := setupController(mockRepo, mockAPI)
+// Fatores de cubagem inválidos impedem a criação do controller em vez de
+// serem ignorados
+func TestNewQuoteController_RejectsInvalidCubageFactors(t *testing.T) {
+	cfg := testConfig()
+	cfg.FreightCubageFactorsCarriers = "JADLOG"
 
-	request := quote.QuoteRequest{
-		Recipient: quote.Recipient{
-			Address: quote.Address{
-				ZipCode: "11111111", // CEP diferente para testar conversão
-			},
-		},
-		Volumes: []quote.Volume{
-			{
-				Category:      7,
-				Amount:        1,
-				UnitaryWeight: 2.0,
-				Price:         75.0,
-				SKU:           "TEST123",
-				Height:        8.0,
-				Width:         12.0,
-				Length:        16.0,
-			},
-		},
+	if _, err := quote.NewQuoteController(cfg, &quotetest.Repository{}, &quotetest.Client{}, quote.QuoteOptions{}); err == nil {
+		t.Error("Expected an error for invalid cubage factors")
 	}
+}
 
-	response, err := controller.SimulateQuote(request)
+// As ofertas vencidas do cenário expired do Frete Rápido simulado não são
+// devolvidas nem salvas
+func TestSimulateQuote_DropsExpiredOffers(t *testing.T) {
+	srv := httptest.NewServer(fakefastdelivery.New(fakefastdelivery.Config{Scenario: fakefastdelivery.ScenarioExpired}))
+	defer srv.Close()
+
+	api := fastdeliveryapi.New(&config.Config{
+		FastDeliveryAPIBaseURL:         srv.URL + "/api/v3",
+		FastDeliveryAPITimeout:         time.Second,
+		FastDeliveryAPIBreakerFailures: 5,
+		FastDeliveryAPIBreakerCooldown: time.Minute,
+	})
+
+	repository := &quotetest.Repository{}
+	controller, err := quote.NewQuoteController(testConfig(), repository, api, quote.QuoteOptions{})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
 
+	response, err := controller.SimulateQuote(context.Background(), quoteRequest("01311000"))
 	if err != nil {
 		t.Fatalf("Expected no error, got: %v", err)
 	}
 
-	if response == nil {
-		t.Fatal("Expected response, got nil")
+	if len(response.Carriers) != 0 || len(repository.Saved) != 0 {
+		t.Errorf("Expected no carriers returned or saved, got: %+v and %+v", response.Carriers, repository.Saved)
+	}
+
+	if len(response.Unavailable) == 0 {
+		t.Fatal("Expected the dispatchers to be unavailable")
 	}
 
-	if len(response.Carriers) != 1 {
-		t.Fatalf("Expected 1 carrier, got: %d", len(response.Carriers))
+	for _, u := range response.Unavailable {
+		if u.Reason != quote.ReasonExpired {
+			t.Errorf("Expected reason %q, got: %+v", quote.ReasonExpired, u)
+		}
 	}
 }
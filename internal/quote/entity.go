@@ -1,8 +1,29 @@
 package quote
 
+import (
+	"time"
+
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/money"
+)
+
+const (
+	EventQuoteCreated = "quote.created"
+	EventQuoteFailed  = "quote.failed"
+)
+
+const (
+	ReasonNoDispatchers = "upstream returned no dispatchers"
+	ReasonNoOffers      = "no offers for this dispatcher"
+	ReasonHidden        = "all offers hidden by carrier policies"
+	ReasonExpired       = "all offers expired"
+)
+
 type QuoteRequest struct {
 	Recipient Recipient `json:"recipient" validate:"required"`
 	Volumes   []Volume  `json:"volumes" validate:"required,dive,required"`
+	// IncludeComposition adds the itemised fees to each carrier. They are
+	// always stored, whether or not the client asks for them.
+	IncludeComposition bool `json:"include_composition,omitempty"`
 }
 
 type Recipient struct {
@@ -14,36 +35,102 @@ type Address struct {
 }
 
 type Volume struct {
-	Category      int     `json:"category" validate:"required"`
-	Amount        int     `json:"amount" validate:"required"`
-	UnitaryWeight float64 `json:"unitary_weight" validate:"required"`
-	Price         float64 `json:"price" validate:"required"`
-	SKU           string  `json:"sku" validate:"required"`
-	Height        float64 `json:"height" validate:"required"`
-	Width         float64 `json:"width" validate:"required"`
-	Length        float64 `json:"length" validate:"required"`
+	Category      int         `json:"category" validate:"required"`
+	Amount        int         `json:"amount" validate:"required"`
+	UnitaryWeight float64     `json:"unitary_weight" validate:"required"`
+	Price         money.Money `json:"price" validate:"required"`
+	SKU           string      `json:"sku" validate:"required"`
+	Height        float64     `json:"height" validate:"required"`
+	Width         float64     `json:"width" validate:"required"`
+	Length        float64     `json:"length" validate:"required"`
 }
 
+// QuoteResponse lists the offers of every upstream dispatcher. When some or
+// all dispatchers have nothing to offer, Unavailable says why.
 type QuoteResponse struct {
-	Carriers []Carrier `json:"carriers"`
+	Carriers    []Carrier     `json:"carriers"`
+	Unavailable []Unavailable `json:"unavailable,omitempty"`
 }
 
 type Carrier struct {
-	Name     string  `json:"name"`
-	Service  string  `json:"service"`
-	Deadline int     `json:"deadline"`
-	Price    float64 `json:"price"`
+	Name                 string      `json:"name"`
+	Service              string      `json:"service"`
+	Deadline             int         `json:"deadline"`
+	DeliveryDate         string      `json:"delivery_date,omitempty"`
+	UpstreamDeliveryDate string      `json:"upstream_delivery_date,omitempty"`
+	Price                money.Money `json:"price"`
+	DispatcherID         string      `json:"dispatcher_id,omitempty"`
+	RequestID            string      `json:"request_id,omitempty"`
+	Estimated            bool        `json:"estimated,omitempty"`
+	Composition          []Fee       `json:"composition,omitempty"`
+	Weights              *Weights    `json:"weights,omitempty"`
+	Pricing              *Pricing    `json:"pricing,omitempty"`
+}
+
+// OriginalPrice is the price before our pricing rules changed it.
+func (c Carrier) OriginalPrice() money.Money {
+	if c.Pricing == nil {
+		return c.Price
+	}
+
+	return c.Pricing.OriginalPrice
+}
+
+// Weights is the weight we expect the carrier to bill for the volumes, next
+// to the one the upstream reported. Discrepancy flags when they disagree.
+type Weights struct {
+	Real           float64 `json:"real"`
+	Cubed          float64 `json:"cubed"`
+	Billed         float64 `json:"billed"`
+	CubageFactor   float64 `json:"cubage_factor"`
+	UpstreamBilled float64 `json:"upstream_billed"`
+	Discrepancy    bool    `json:"discrepancy"`
+}
+
+// Fee is one charge of the upstream freight composition, named after its
+// field in the Frete Rápido API (e.g. "gris", "ad_valorem", "icms"). Only
+// charges that were actually applied are listed.
+type Fee struct {
+	Name   string      `json:"name"`
+	Amount money.Money `json:"amount"`
+}
+
+// Pricing is set when pricing rules fired for a carrier. Rules lists them in
+// the order they ran, each with the price it left.
+type Pricing struct {
+	OriginalPrice money.Money   `json:"original_price"`
+	Rules         []AppliedRule `json:"rules"`
+}
+
+type AppliedRule struct {
+	Name  string      `json:"name"`
+	Price money.Money `json:"price"`
+}
+
+type Unavailable struct {
+	DispatcherID string `json:"dispatcher_id,omitempty"`
+	RequestID    string `json:"request_id,omitempty"`
+	Reason       string `json:"reason"`
 }
 
 type QuoteMetrics struct {
 	CarrierQuotes    []CarrierQuotes `json:"carrier_quotes"`
-	CheapestShipping float64         `json:"cheapest_shipping"`
-	HighestShipping  float64         `json:"highest_shipping"`
+	CheapestShipping money.Money     `json:"cheapest_shipping"`
+	HighestShipping  money.Money     `json:"highest_shipping"`
 }
 
 type CarrierQuotes struct {
-	CarrierName  string  `json:"carrier_name"`
-	TotalQuotes  int     `json:"total_quotes"`
-	TotalPrice   float64 `json:"total_price"`
-	AveragePrice float64 `json:"average_price"`
+	CarrierName  string      `json:"carrier_name"`
+	TotalQuotes  int         `json:"total_quotes"`
+	TotalPrice   money.Money `json:"total_price"`
+	AveragePrice money.Money `json:"average_price"`
+}
+
+type Event struct {
+	Type       string         `json:"type"`
+	TenantID   *int           `json:"tenant_id,omitempty"`
+	OccurredAt time.Time      `json:"occurred_at"`
+	Request    QuoteRequest   `json:"request"`
+	Response   *QuoteResponse `json:"response,omitempty"`
+	Error      string         `json:"error,omitempty"`
 }
@@ -1,8 +1,12 @@
 package quote
 
 import (
+	"context"
+	"errors"
+
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
+	fastdeliveryapi "github.com/jeancarloshp/desafio-frete-rapido/pkg/fastdelivery_api"
 )
 
 type QuoteHandler struct {
@@ -33,7 +37,16 @@ func (qh *QuoteHandler) QuoteSimulationHandler(c *fiber.Ctx) error {
 		})
 	}
 
-	quoteResponse, err := qh.quoteController.SimulateQuote(quoteRequest)
+	quoteResponse, err := qh.quoteController.SimulateQuote(c.UserContext(), quoteRequest)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fiber.ErrRequestTimeout
+	}
+	if errors.Is(err, fastdeliveryapi.ErrCircuitOpen) {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"error":   "quote provider temporarily unavailable",
+			"details": err.Error(),
+		})
+	}
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error":   "failed to process quote request",
@@ -53,7 +66,10 @@ func (qh *QuoteHandler) QuoteMetricsHandler(c *fiber.Ctx) error {
 		})
 	}
 
-	metrics, err := qh.quoteController.QuoteMetrics(lastQuotes)
+	metrics, err := qh.quoteController.QuoteMetrics(c.UserContext(), lastQuotes)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fiber.ErrRequestTimeout
+	}
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error":   "failed to retrieve quote metrics",
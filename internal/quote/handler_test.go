@@ -0,0 +1,200 @@
+package quote_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jeancarloshp/desafio-frete-rapido/internal/quote"
+	"github.com/jeancarloshp/desafio-frete-rapido/internal/quote/quotetest"
+	fastdeliveryapi "github.com/jeancarloshp/desafio-frete-rapido/pkg/fastdelivery_api"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/fastdelivery_api/models"
+)
+
+const quoteRequestJSON = `{"recipient":{"address":{"zipcode":"01311000"}},"volumes":[{"category":7,"amount":1,"unitary_weight":5,"price":349,"sku":"abc-teste-123","height":0.2,"width":0.2,"length":0.2}]}`
+
+func newTestApp(t *testing.T, repository *quotetest.Repository, client *quotetest.Client) *fiber.App {
+	t.Helper()
+
+	controller, err := quote.NewQuoteController(testConfig(), repository, client, quote.QuoteOptions{})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	handler := quote.NewQuoteHandler(controller)
+
+	app := fiber.New()
+	app.Post("/v1/quote", handler.QuoteSimulationHandler)
+	app.Get("/v1/metrics", handler.QuoteMetricsHandler)
+
+	return app
+}
+
+func TestQuoteSimulationHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		client     *quotetest.Client
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "success",
+			body:       quoteRequestJSON,
+			client:     &quotetest.Client{Response: quotetest.Response(quotetest.Offer("CORREIOS", "PAC", "25.50", 5))},
+			wantStatus: fiber.StatusOK,
+			wantBody:   `{"carriers":[{"name":"CORREIOS","service":"PAC","deadline":5,"price":25.5,"weights":{"real":5,"cubed":2.4,"billed":5,"cubage_factor":300,"upstream_billed":0,"discrepancy":false}}]}`,
+		},
+		{
+			name:       "no offers available",
+			body:       quoteRequestJSON,
+			client:     &quotetest.Client{Response: &models.QuoteResponse{}},
+			wantStatus: fiber.StatusOK,
+			wantBody:   `{"carriers":[],"unavailable":[{"reason":"upstream returned no dispatchers"}]}`,
+		},
+		{
+			name:       "invalid json",
+			body:       `{"recipient":`,
+			client:     &quotetest.Client{},
+			wantStatus: fiber.StatusBadRequest,
+		},
+		{
+			name:       "invalid zipcode",
+			body:       strings.Replace(quoteRequestJSON, "01311000", "123", 1),
+			client:     &quotetest.Client{},
+			wantStatus: fiber.StatusBadRequest,
+		},
+		{
+			name:       "missing volumes",
+			body:       `{"recipient":{"address":{"zipcode":"01311000"}}}`,
+			client:     &quotetest.Client{},
+			wantStatus: fiber.StatusBadRequest,
+		},
+		{
+			name:       "circuit open",
+			body:       quoteRequestJSON,
+			client:     &quotetest.Client{Err: fastdeliveryapi.ErrCircuitOpen},
+			wantStatus: fiber.StatusServiceUnavailable,
+		},
+		{
+			name:       "upstream timeout",
+			body:       quoteRequestJSON,
+			client:     &quotetest.Client{Err: fmt.Errorf("request failed: %w", context.DeadlineExceeded)},
+			wantStatus: fiber.StatusRequestTimeout,
+		},
+		{
+			name:       "upstream error",
+			body:       quoteRequestJSON,
+			client:     &quotetest.Client{Err: errors.New("unexpected status code: 500")},
+			wantStatus: fiber.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApp(t, &quotetest.Repository{}, tt.client)
+
+			req := httptest.NewRequest("POST", "/v1/quote", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("Expected status %d, got: %d", tt.wantStatus, resp.StatusCode)
+			}
+
+			// Requisições inválidas não devem chegar à API externa
+			if tt.wantStatus == fiber.StatusBadRequest && len(tt.client.Requests) != 0 {
+				t.Errorf("Expected no upstream calls, got: %d", len(tt.client.Requests))
+			}
+
+			if tt.wantBody != "" {
+				var got, want any
+				if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+					t.Fatalf("Expected JSON body, got: %v", err)
+				}
+				_ = json.Unmarshal([]byte(tt.wantBody), &want)
+
+				gotJSON, _ := json.Marshal(got)
+				wantJSON, _ := json.Marshal(want)
+				if string(gotJSON) != string(wantJSON) {
+					t.Errorf("Expected body %s, got: %s", wantJSON, gotJSON)
+				}
+			}
+		})
+	}
+}
+
+func TestQuoteMetricsHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		repository *quotetest.Repository
+		wantStatus int
+		wantTotal  int
+	}{
+		{
+			name:       "default limit",
+			repository: &quotetest.Repository{Saved: make([]quote.Carrier, 15)},
+			wantStatus: fiber.StatusOK,
+			wantTotal:  10,
+		},
+		{
+			name:       "explicit limit",
+			query:      "?last_quotes=3",
+			repository: &quotetest.Repository{Saved: make([]quote.Carrier, 15)},
+			wantStatus: fiber.StatusOK,
+			wantTotal:  3,
+		},
+		{
+			name:       "negative limit",
+			query:      "?last_quotes=-1",
+			repository: &quotetest.Repository{},
+			wantStatus: fiber.StatusBadRequest,
+		},
+		{
+			name:       "repository error",
+			repository: &quotetest.Repository{FindErr: errors.New("database connection error")},
+			wantStatus: fiber.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := range tt.repository.Saved {
+				tt.repository.Saved[i] = quote.Carrier{Name: "CORREIOS", Price: quotetest.Price("10.00")}
+			}
+
+			app := newTestApp(t, tt.repository, &quotetest.Client{})
+
+			resp, err := app.Test(httptest.NewRequest("GET", "/v1/metrics"+tt.query, nil))
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("Expected status %d, got: %d", tt.wantStatus, resp.StatusCode)
+			}
+
+			if tt.wantStatus != fiber.StatusOK {
+				return
+			}
+
+			var metrics quote.QuoteMetrics
+			if err := json.NewDecoder(resp.Body).Decode(&metrics); err != nil {
+				t.Fatalf("Expected JSON body, got: %v", err)
+			}
+
+			if len(metrics.CarrierQuotes) != 1 || metrics.CarrierQuotes[0].TotalQuotes != tt.wantTotal {
+				t.Errorf("Expected %d quotes in metrics, got: %+v", tt.wantTotal, metrics.CarrierQuotes)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package quote
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryQuoteRepository keeps quotes in process memory. Everything is lost
+// on restart, so it is meant for demos, local development and tests.
+type MemoryQuoteRepository struct {
+	mu     sync.RWMutex
+	quotes []Carrier
+}
+
+func NewMemoryQuoteRepository() *MemoryQuoteRepository {
+	return &MemoryQuoteRepository{}
+}
+
+func (r *MemoryQuoteRepository) SaveQuote(ctx context.Context, carrier Carrier) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.quotes = append(r.quotes, carrier)
+
+	return nil
+}
+
+func (r *MemoryQuoteRepository) FindQuotesByLastQuote(ctx context.Context, lastQuote int) ([]Carrier, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	n := min(max(lastQuote, 0), len(r.quotes))
+	carriers := make([]Carrier, n)
+	for i := range n {
+		carriers[i] = r.quotes[len(r.quotes)-1-i]
+	}
+
+	return carriers, nil
+}
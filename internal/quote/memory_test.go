@@ -0,0 +1,88 @@
+package quote_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jeancarloshp/desafio-frete-rapido/internal/quote"
+	"github.com/jeancarloshp/desafio-frete-rapido/internal/quote/quotetest"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/config"
+)
+
+// As cotações mais recentes devem vir primeiro, limitadas a lastQuote
+func TestMemoryQuoteRepository_FindQuotesByLastQuote(t *testing.T) {
+	repo := quote.NewMemoryQuoteRepository()
+	ctx := context.Background()
+
+	for _, price := range []string{"10.00", "20.00", "30.00"} {
+		if err := repo.SaveQuote(ctx, quote.Carrier{Name: "CORREIOS", Price: quotetest.Price(price)}); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+	}
+
+	quotes, err := repo.FindQuotesByLastQuote(ctx, 2)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if len(quotes) != 2 || quotes[0].Price != quotetest.Price("30.00") || quotes[1].Price != quotetest.Price("20.00") {
+		t.Errorf("Expected the 2 newest quotes, got: %+v", quotes)
+	}
+
+	all, _ := repo.FindQuotesByLastQuote(ctx, 100)
+	if len(all) != 3 {
+		t.Errorf("Expected 3 quotes, got: %d", len(all))
+	}
+
+	none, _ := repo.FindQuotesByLastQuote(ctx, 0)
+	if len(none) != 0 {
+		t.Errorf("Expected no quotes, got: %d", len(none))
+	}
+}
+
+// As métricas sobre o backend em memória seguem a mesma regra do Postgres
+func TestQuoteMetrics_WithMemoryRepository(t *testing.T) {
+	repo := quote.NewMemoryQuoteRepository()
+	ctx := context.Background()
+
+	for _, c := range []quote.Carrier{
+		{Name: "JADLOG", Price: quotetest.Price("100.00")},
+		{Name: "CORREIOS", Price: quotetest.Price("20.00")},
+		{Name: "CORREIOS", Price: quotetest.Price("40.00")},
+		{Name: "JADLOG", Price: quotetest.Price("60.00")},
+	} {
+		if err := repo.SaveQuote(ctx, c); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+	}
+
+	controller, err := quote.NewQuoteController(&config.Config{}, repo, nil, quote.QuoteOptions{})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	// Apenas as 3 últimas cotações entram no cálculo
+	metrics, err := controller.QuoteMetrics(ctx, 3)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if metrics.CheapestShipping != quotetest.Price("30.00") || metrics.HighestShipping != quotetest.Price("60.00") {
+		t.Errorf("Expected cheapest 30 and highest 60, got: %v and %v", metrics.CheapestShipping, metrics.HighestShipping)
+	}
+
+	for _, cq := range metrics.CarrierQuotes {
+		switch cq.CarrierName {
+		case "CORREIOS":
+			if cq.TotalQuotes != 2 || cq.TotalPrice != quotetest.Price("60.00") {
+				t.Errorf("Expected 2 CORREIOS quotes totaling 60, got: %+v", cq)
+			}
+		case "JADLOG":
+			if cq.TotalQuotes != 1 || cq.TotalPrice != quotetest.Price("60.00") {
+				t.Errorf("Expected 1 JADLOG quote totaling 60, got: %+v", cq)
+			}
+		default:
+			t.Errorf("Unexpected carrier: %s", cq.CarrierName)
+		}
+	}
+}
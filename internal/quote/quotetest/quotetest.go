@@ -0,0 +1,146 @@
+// Package quotetest provides test doubles for the dependencies of
+// quote.QuoteController.
+package quotetest
+
+import (
+	"context"
+	"sync"
+
+	"github.com/jeancarloshp/desafio-frete-rapido/internal/policy"
+	"github.com/jeancarloshp/desafio-frete-rapido/internal/quote"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/fastdelivery_api/models"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/money"
+)
+
+// Repository records saved quotes in memory. SaveErr and FindErr, when set,
+// are returned instead of touching the stored quotes.
+type Repository struct {
+	mu      sync.Mutex
+	Saved   []quote.Carrier
+	SaveErr error
+	FindErr error
+}
+
+func (r *Repository) SaveQuote(ctx context.Context, carrier quote.Carrier) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.SaveErr != nil {
+		return r.SaveErr
+	}
+
+	r.Saved = append(r.Saved, carrier)
+
+	return nil
+}
+
+func (r *Repository) FindQuotesByLastQuote(ctx context.Context, lastQuote int) ([]quote.Carrier, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.FindErr != nil {
+		return nil, r.FindErr
+	}
+
+	n := min(max(lastQuote, 0), len(r.Saved))
+	carriers := make([]quote.Carrier, n)
+	for i := range n {
+		carriers[i] = r.Saved[len(r.Saved)-1-i]
+	}
+
+	return carriers, nil
+}
+
+// Client answers simulations with Response and Err and records every
+// request it receives.
+type Client struct {
+	mu       sync.Mutex
+	Requests []models.QuoteRequest
+	Response *models.QuoteResponse
+	Err      error
+}
+
+func (c *Client) SimulateQuote(ctx context.Context, quoteRequest models.QuoteRequest) (*models.QuoteResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.Requests = append(c.Requests, quoteRequest)
+
+	if c.Err != nil {
+		return nil, c.Err
+	}
+
+	if c.Response == nil {
+		return &models.QuoteResponse{}, nil
+	}
+
+	return c.Response, nil
+}
+
+// LastRequest returns the most recent simulation request.
+func (c *Client) LastRequest() models.QuoteRequest {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.Requests) == 0 {
+		return models.QuoteRequest{}
+	}
+
+	return c.Requests[len(c.Requests)-1]
+}
+
+// Publisher records published events.
+type Publisher struct {
+	mu     sync.Mutex
+	Events []quote.Event
+}
+
+func (p *Publisher) Publish(ctx context.Context, event quote.Event) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.Events = append(p.Events, event)
+}
+
+// Policies lists Policies, or fails with Err when it is set.
+type Policies struct {
+	Policies []policy.Policy
+	Err      error
+}
+
+func (p *Policies) ListPolicies(ctx context.Context) ([]policy.Policy, error) {
+	if p.Err != nil {
+		return nil, p.Err
+	}
+
+	return p.Policies, nil
+}
+
+// Offer builds an upstream offer with the fields the controller reads. The
+// price is given in reais, e.g. "25.50".
+func Offer(carrier, service, price string, days int) models.Offer {
+	return models.Offer{
+		Carrier:      models.Carrier{Name: carrier},
+		Service:      service,
+		FinalPrice:   Price(price),
+		DeliveryTime: models.DeliveryTime{Days: days},
+	}
+}
+
+// Price parses an amount in reais and panics on malformed test input.
+func Price(value string) money.Money {
+	m, err := money.Parse(value)
+	if err != nil {
+		panic(err)
+	}
+
+	return m
+}
+
+// Response wraps offers in a single dispatcher, like the upstream does for
+// one origin.
+func Response(offers ...models.Offer) *models.QuoteResponse {
+	return &models.QuoteResponse{
+		Dispatchers: []models.DispatcherResponse{{Offers: offers}},
+	}
+}
@@ -4,35 +4,84 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/jeancarloshp/desafio-frete-rapido/pkg/database/querier"
 )
 
-type QuoteRepository struct {
+// QuoteRepository stores every carrier offer returned to a client, so that
+// metrics can be computed over the most recent ones.
+type QuoteRepository interface {
+	SaveQuote(ctx context.Context, carrier Carrier) error
+	// FindQuotesByLastQuote returns up to lastQuote offers, newest first.
+	FindQuotesByLastQuote(ctx context.Context, lastQuote int) ([]Carrier, error)
+}
+
+type PostgresQuoteRepository struct {
+	db   *pgxpool.Pool
 	conn *querier.Queries
 }
 
-func NewQuoteRepository(conn *querier.Queries) *QuoteRepository {
-	return &QuoteRepository{
-		conn: conn,
+func NewPostgresQuoteRepository(db *pgxpool.Pool) *PostgresQuoteRepository {
+	return &PostgresQuoteRepository{
+		db:   db,
+		conn: querier.New(db),
 	}
 }
 
-func (r *QuoteRepository) SaveQuote(carrier Carrier) error {
-	_, err := r.conn.CreateQuote(context.Background(), querier.CreateQuoteParams{
-		CarrierName: carrier.Name,
-		Service:     carrier.Service,
-		Price:       carrier.Price,
-		Deadline:    carrier.Deadline,
+// SaveQuote stores the quote with its fees and pricing rules in one
+// transaction, so a quote is never left without its breakdown.
+func (r *PostgresQuoteRepository) SaveQuote(ctx context.Context, carrier Carrier) error {
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		return saveQuote(ctx, r.conn.WithTx(tx), carrier)
+	})
+}
+
+func saveQuote(ctx context.Context, conn *querier.Queries, carrier Carrier) error {
+	quote, err := conn.CreateQuote(ctx, querier.CreateQuoteParams{
+		CarrierName:   carrier.Name,
+		Service:       carrier.Service,
+		Price:         carrier.Price,
+		Deadline:      carrier.Deadline,
+		Estimated:     carrier.Estimated,
+		OriginalPrice: carrier.OriginalPrice(),
 	})
 	if err != nil {
 		return fmt.Errorf("failed to save quote: %w", err)
 	}
 
+	for _, fee := range carrier.Composition {
+		err := conn.CreateQuoteFee(ctx, querier.CreateQuoteFeeParams{
+			QuoteID: int(quote.ID),
+			Fee:     fee.Name,
+			Amount:  fee.Amount,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to save quote fee %s: %w", fee.Name, err)
+		}
+	}
+
+	if carrier.Pricing == nil {
+		return nil
+	}
+
+	for i, rule := range carrier.Pricing.Rules {
+		err := conn.CreateQuotePricingRule(ctx, querier.CreateQuotePricingRuleParams{
+			QuoteID: int(quote.ID),
+			Step:    i + 1,
+			Rule:    rule.Name,
+			Price:   rule.Price,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to save quote pricing rule %s: %w", rule.Name, err)
+		}
+	}
+
 	return nil
 }
 
-func (r *QuoteRepository) FindQuotesByLastQuote(lastQuote int) ([]Carrier, error) {
-	quotes, err := r.conn.FindLastQuotes(context.Background(), lastQuote)
+func (r *PostgresQuoteRepository) FindQuotesByLastQuote(ctx context.Context, lastQuote int) ([]Carrier, error) {
+	quotes, err := r.conn.FindLastQuotes(ctx, lastQuote)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find quotes: %w", err)
 	}
@@ -40,10 +89,11 @@ func (r *QuoteRepository) FindQuotesByLastQuote(lastQuote int) ([]Carrier, error
 	carriers := make([]Carrier, len(quotes))
 	for i, q := range quotes {
 		carriers[i] = Carrier{
-			Name:     q.CarrierName,
-			Service:  q.Service,
-			Price:    q.Price,
-			Deadline: q.Deadline,
+			Name:      q.CarrierName,
+			Service:   q.Service,
+			Price:     q.Price,
+			Deadline:  q.Deadline,
+			Estimated: q.Estimated,
 		}
 	}
 
@@ -0,0 +1,79 @@
+package quote_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jeancarloshp/desafio-frete-rapido/internal/quote"
+	"github.com/jeancarloshp/desafio-frete-rapido/internal/quote/quotetest"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/database/databasetest"
+)
+
+// pricedCarrier tem taxas e regras de preço, para que SaveQuote grave nas três
+// tabelas
+func pricedCarrier() quote.Carrier {
+	return quote.Carrier{
+		Name:        "JADLOG",
+		Service:     ".Package",
+		Price:       quotetest.Price("22.90"),
+		Deadline:    5,
+		Composition: []quote.Fee{{Name: "gris", Amount: quotetest.Price("1.50")}},
+		Pricing: &quote.Pricing{
+			OriginalPrice: quotetest.Price("20.00"),
+			Rules:         []quote.AppliedRule{{Name: "ending", Price: quotetest.Price("22.90")}},
+		},
+	}
+}
+
+func TestPostgresQuoteRepository_SaveQuoteIsAtomic(t *testing.T) {
+	ctx := context.Background()
+	pool := databasetest.Migrated(t)
+	repo := quote.NewPostgresQuoteRepository(pool)
+
+	if err := repo.SaveQuote(ctx, pricedCarrier()); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if _, err := pool.Exec(ctx, "DROP TABLE quote_pricing_rules"); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if err := repo.SaveQuote(ctx, pricedCarrier()); err == nil {
+		t.Fatal("Expected an error saving the pricing rules")
+	}
+
+	// Só a primeira cotação, completa, continua no banco
+	for _, table := range []string{"quotes", "quote_fees"} {
+		var count int
+		if err := pool.QueryRow(ctx, "SELECT COUNT(*) FROM "+table).Scan(&count); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+
+		if count != 1 {
+			t.Errorf("Expected 1 row in %s, got: %d", table, count)
+		}
+	}
+}
+
+// Valores monetários voltam do NUMERIC do Postgres sem perda
+func TestPostgresQuoteRepository_StoresExactMoney(t *testing.T) {
+	ctx := context.Background()
+	repo := quote.NewPostgresQuoteRepository(databasetest.Migrated(t))
+
+	for _, price := range []string{"0.30", "99999999.99"} {
+		carrier := pricedCarrier()
+		carrier.Price = quotetest.Price(price)
+		if err := repo.SaveQuote(ctx, carrier); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+
+		quotes, err := repo.FindQuotesByLastQuote(ctx, 1)
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+
+		if len(quotes) != 1 || quotes[0].Price != quotetest.Price(price) {
+			t.Errorf("Expected price %s, got: %+v", price, quotes)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package quote
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	sqlitequerier "github.com/jeancarloshp/desafio-frete-rapido/pkg/database/sqlite/querier"
+)
+
+type SQLiteQuoteRepository struct {
+	db   *sql.DB
+	conn *sqlitequerier.Queries
+}
+
+func NewSQLiteQuoteRepository(db *sql.DB) *SQLiteQuoteRepository {
+	return &SQLiteQuoteRepository{
+		db:   db,
+		conn: sqlitequerier.New(db),
+	}
+}
+
+// SaveQuote stores the quote with its fees and pricing rules in one
+// transaction, like the Postgres repository.
+func (r *SQLiteQuoteRepository) SaveQuote(ctx context.Context, carrier Carrier) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin quote transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := saveSQLiteQuote(ctx, r.conn.WithTx(tx), carrier); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit quote: %w", err)
+	}
+
+	return nil
+}
+
+func saveSQLiteQuote(ctx context.Context, conn *sqlitequerier.Queries, carrier Carrier) error {
+	quote, err := conn.CreateQuote(ctx, sqlitequerier.CreateQuoteParams{
+		CarrierName:   carrier.Name,
+		Service:       carrier.Service,
+		Price:         carrier.Price,
+		Deadline:      carrier.Deadline,
+		Estimated:     carrier.Estimated,
+		OriginalPrice: carrier.OriginalPrice(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save quote: %w", err)
+	}
+
+	for _, fee := range carrier.Composition {
+		err := conn.CreateQuoteFee(ctx, sqlitequerier.CreateQuoteFeeParams{
+			QuoteID: quote.ID,
+			Fee:     fee.Name,
+			Amount:  fee.Amount,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to save quote fee %s: %w", fee.Name, err)
+		}
+	}
+
+	if carrier.Pricing == nil {
+		return nil
+	}
+
+	for i, rule := range carrier.Pricing.Rules {
+		err := conn.CreateQuotePricingRule(ctx, sqlitequerier.CreateQuotePricingRuleParams{
+			QuoteID: quote.ID,
+			Step:    i + 1,
+			Rule:    rule.Name,
+			Price:   rule.Price,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to save quote pricing rule %s: %w", rule.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func (r *SQLiteQuoteRepository) FindQuotesByLastQuote(ctx context.Context, lastQuote int) ([]Carrier, error) {
+	quotes, err := r.conn.FindLastQuotes(ctx, int64(lastQuote))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find quotes: %w", err)
+	}
+
+	carriers := make([]Carrier, len(quotes))
+	for i, q := range quotes {
+		carriers[i] = Carrier{
+			Name:      q.CarrierName,
+			Service:   q.Service,
+			Price:     q.Price,
+			Deadline:  q.Deadline,
+			Estimated: q.Estimated,
+		}
+	}
+
+	return carriers, nil
+}
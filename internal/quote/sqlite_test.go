@@ -0,0 +1,273 @@
+package quote_test
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jeancarloshp/desafio-frete-rapido/internal/quote"
+	"github.com/jeancarloshp/desafio-frete-rapido/internal/quote/quotetest"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/config"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/database"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/money"
+)
+
+func newSQLiteRepository(t *testing.T) (*quote.SQLiteQuoteRepository, *sql.DB) {
+	t.Helper()
+
+	db, err := database.NewSQLiteConnection(&config.Config{SQLitePath: filepath.Join(t.TempDir(), "quotes.db")})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if err := database.MigrateSQLite(context.Background(), db); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	return quote.NewSQLiteQuoteRepository(db), db
+}
+
+// O SQLite deve devolver as cotações mais recentes primeiro, como o Postgres
+func TestSQLiteQuoteRepository_FindQuotesByLastQuote(t *testing.T) {
+	repo, _ := newSQLiteRepository(t)
+	ctx := context.Background()
+
+	for _, price := range []string{"10.50", "20.25", "30.00"} {
+		carrier := quote.Carrier{Name: "CORREIOS", Service: "PAC", Price: quotetest.Price(price), Deadline: 3, Estimated: price == "30.00"}
+		if err := repo.SaveQuote(ctx, carrier); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+	}
+
+	quotes, err := repo.FindQuotesByLastQuote(ctx, 2)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if len(quotes) != 2 || quotes[0].Price != quotetest.Price("30.00") || quotes[1].Price != quotetest.Price("20.25") {
+		t.Errorf("Expected the 2 newest quotes, got: %+v", quotes)
+	}
+
+	if quotes[0].Name != "CORREIOS" || quotes[0].Service != "PAC" || quotes[0].Deadline != 3 || !quotes[0].Estimated || quotes[1].Estimated {
+		t.Errorf("Expected all fields to round-trip, got: %+v", quotes[0])
+	}
+
+	none, err := repo.FindQuotesByLastQuote(ctx, 0)
+	if err != nil || len(none) != 0 {
+		t.Errorf("Expected no quotes, got: %d (%v)", len(none), err)
+	}
+}
+
+// As taxas da composição são salvas junto com a cotação, para análise financeira
+func TestSQLiteQuoteRepository_SavesFees(t *testing.T) {
+	repo, db := newSQLiteRepository(t)
+	ctx := context.Background()
+
+	carrier := quote.Carrier{
+		Name:     "CORREIOS",
+		Service:  "PAC",
+		Price:    quotetest.Price("25.50"),
+		Deadline: 3,
+		Composition: []quote.Fee{
+			{Name: "freight_weight", Amount: quotetest.Price("24.00")},
+			{Name: "gris", Amount: quotetest.Price("1.50")},
+		},
+	}
+	if err := repo.SaveQuote(ctx, carrier); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	rows, err := db.QueryContext(ctx, "SELECT fee, amount FROM quote_fees ORDER BY fee")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	defer rows.Close()
+
+	var fees []quote.Fee
+	for rows.Next() {
+		var fee quote.Fee
+		if err := rows.Scan(&fee.Name, &fee.Amount); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		fees = append(fees, fee)
+	}
+
+	if !reflect.DeepEqual(fees, carrier.Composition) {
+		t.Errorf("Expected fees %+v, got: %+v", carrier.Composition, fees)
+	}
+}
+
+// Métricas calculadas sobre o SQLite devem bater com as do backend em memória
+func TestQuoteMetrics_SQLiteMatchesMemory(t *testing.T) {
+	sqliteRepo, _ := newSQLiteRepository(t)
+	memoryRepo := quote.NewMemoryQuoteRepository()
+	ctx := context.Background()
+
+	for _, c := range []quote.Carrier{
+		{Name: "JADLOG", Price: quotetest.Price("100.00")},
+		{Name: "CORREIOS", Price: quotetest.Price("20.00")},
+		{Name: "CORREIOS", Price: quotetest.Price("40.00")},
+		{Name: "JADLOG", Price: quotetest.Price("60.00")},
+	} {
+		if err := sqliteRepo.SaveQuote(ctx, c); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		_ = memoryRepo.SaveQuote(ctx, c)
+	}
+
+	sqliteController, err := quote.NewQuoteController(&config.Config{}, sqliteRepo, nil, quote.QuoteOptions{})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	fromSQLite, err := sqliteController.QuoteMetrics(ctx, 3)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	memoryController, _ := quote.NewQuoteController(&config.Config{}, memoryRepo, nil, quote.QuoteOptions{})
+	fromMemory, _ := memoryController.QuoteMetrics(ctx, 3)
+
+	if fromSQLite.CheapestShipping != fromMemory.CheapestShipping || fromSQLite.HighestShipping != fromMemory.HighestShipping {
+		t.Errorf("Expected matching metrics, got: %+v and %+v", fromSQLite, fromMemory)
+	}
+
+	if len(fromSQLite.CarrierQuotes) != len(fromMemory.CarrierQuotes) {
+		t.Errorf("Expected %d carriers, got: %d", len(fromMemory.CarrierQuotes), len(fromSQLite.CarrierQuotes))
+	}
+}
+
+// O preço original e as regras de preço aplicadas são salvos com a cotação
+func TestSQLiteQuoteRepository_SavesPricing(t *testing.T) {
+	repo, db := newSQLiteRepository(t)
+	ctx := context.Background()
+
+	carrier := quote.Carrier{
+		Name:     "JADLOG",
+		Service:  ".Package",
+		Price:    quotetest.Price("22.90"),
+		Deadline: 5,
+		Pricing: &quote.Pricing{
+			OriginalPrice: quotetest.Price("20.00"),
+			Rules: []quote.AppliedRule{
+				{Name: "jadlog-margin", Price: quotetest.Price("22.00")},
+				{Name: "ending", Price: quotetest.Price("22.90")},
+			},
+		},
+	}
+	if err := repo.SaveQuote(ctx, carrier); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if err := repo.SaveQuote(ctx, quote.Carrier{Name: "CORREIOS", Service: "PAC", Price: quotetest.Price("15.90"), Deadline: 7}); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	rows, err := db.QueryContext(ctx, "SELECT price, original_price FROM quotes ORDER BY id")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	defer rows.Close()
+
+	var prices [][2]string
+	for rows.Next() {
+		var price, original money.Money
+		if err := rows.Scan(&price, &original); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		prices = append(prices, [2]string{price.String(), original.String()})
+	}
+
+	// Sem regras aplicadas, o preço original é o próprio preço
+	if want := [][2]string{{"22.90", "20.00"}, {"15.90", "15.90"}}; !reflect.DeepEqual(prices, want) {
+		t.Errorf("Expected prices %v, got: %v", want, prices)
+	}
+
+	ruleRows, err := db.QueryContext(ctx, "SELECT rule, price FROM quote_pricing_rules ORDER BY step")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	defer ruleRows.Close()
+
+	var rules []quote.AppliedRule
+	for ruleRows.Next() {
+		var rule quote.AppliedRule
+		if err := ruleRows.Scan(&rule.Name, &rule.Price); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		rules = append(rules, rule)
+	}
+
+	if !reflect.DeepEqual(rules, carrier.Pricing.Rules) {
+		t.Errorf("Expected rules %+v, got: %+v", carrier.Pricing.Rules, rules)
+	}
+}
+
+// Se as regras de preço não puderem ser salvas, a cotação e as taxas também
+// não ficam no banco
+func TestSQLiteQuoteRepository_SaveQuoteIsAtomic(t *testing.T) {
+	repo, db := newSQLiteRepository(t)
+	ctx := context.Background()
+
+	if _, err := db.ExecContext(ctx, "DROP TABLE quote_pricing_rules"); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	err := repo.SaveQuote(ctx, pricedCarrier())
+	if err == nil {
+		t.Fatal("Expected an error saving the pricing rules")
+	}
+
+	for _, table := range []string{"quotes", "quote_fees"} {
+		var count int
+		if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+table).Scan(&count); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+
+		if count != 0 {
+			t.Errorf("Expected no rows in %s, got: %d", table, count)
+		}
+	}
+}
+
+// Valores monetários são gravados como texto decimal e lidos sem arredondamento
+// binário
+func TestSQLiteQuoteRepository_StoresExactMoney(t *testing.T) {
+	repo, db := newSQLiteRepository(t)
+	ctx := context.Background()
+
+	carrier := pricedCarrier()
+	carrier.Price = quotetest.Price("0.30")
+	carrier.Composition = []quote.Fee{{Name: "gris", Amount: quotetest.Price("0.10")}}
+	if err := repo.SaveQuote(ctx, carrier); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	for _, column := range []string{
+		"SELECT typeof(price) || ':' || price FROM quotes",
+		"SELECT typeof(original_price) || ':' || original_price FROM quotes",
+		"SELECT typeof(amount) || ':' || amount FROM quote_fees",
+		"SELECT typeof(price) || ':' || price FROM quote_pricing_rules",
+	} {
+		var stored string
+		if err := db.QueryRowContext(ctx, column).Scan(&stored); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+
+		if !strings.HasPrefix(stored, "text:") {
+			t.Errorf("Expected %q to be stored as text, got: %s", column, stored)
+		}
+	}
+
+	quotes, err := repo.FindQuotesByLastQuote(ctx, 1)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if len(quotes) != 1 || quotes[0].Price != quotetest.Price("0.30") {
+		t.Errorf("Expected price 0.30, got: %+v", quotes)
+	}
+}
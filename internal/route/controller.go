@@ -0,0 +1,173 @@
+package route
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/jeancarloshp/desafio-frete-rapido/internal/quote"
+	"github.com/jeancarloshp/desafio-frete-rapido/internal/tenant"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/config"
+	fastdeliveryapi "github.com/jeancarloshp/desafio-frete-rapido/pkg/fastdelivery_api"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/freightmath"
+)
+
+// routesPerRun bounds how many due routes one scheduler tick re-quotes.
+const routesPerRun = 20
+
+var ErrInvalidInterval = fmt.Errorf("interval must be a duration of at least %s", MinInterval)
+
+type RouteController struct {
+	cfg              *config.Config
+	routeRepository  *RouteRepository
+	api              *fastdeliveryapi.FastDeliveryAPI
+	tenantController *tenant.TenantController
+}
+
+func NewRouteController(cfg *config.Config, routeRepository *RouteRepository, api *fastdeliveryapi.FastDeliveryAPI, tenantController *tenant.TenantController) *RouteController {
+	return &RouteController{
+		cfg:              cfg,
+		routeRepository:  routeRepository,
+		api:              api,
+		tenantController: tenantController,
+	}
+}
+
+func (rc *RouteController) CreateRoute(ctx context.Context, req RouteRequest) (Route, error) {
+	interval := rc.cfg.RouteWatchInterval
+	if req.Interval != "" {
+		parsed, err := time.ParseDuration(req.Interval)
+		if err != nil {
+			return Route{}, ErrInvalidInterval
+		}
+		interval = parsed
+	}
+
+	if interval < MinInterval {
+		return Route{}, ErrInvalidInterval
+	}
+
+	shipper, ok := tenant.FromContext(ctx)
+	if !ok {
+		shipper = tenant.Default(rc.cfg)
+	}
+
+	route := Route{
+		TenantID:           tenantID(ctx),
+		Name:               req.Name,
+		OriginZipCode:      req.OriginZipCode,
+		DestinationZipCode: req.DestinationZipCode,
+		Volumes:            req.Volumes,
+		Interval:           Duration(interval),
+	}
+
+	if route.OriginZipCode == 0 {
+		route.OriginZipCode = shipper.OriginZipCode
+	}
+
+	return rc.routeRepository.CreateRoute(ctx, route)
+}
+
+func (rc *RouteController) ListRoutes(ctx context.Context) ([]Route, error) {
+	return rc.routeRepository.ListRoutes(ctx, tenantID(ctx))
+}
+
+func (rc *RouteController) DeleteRoute(ctx context.Context, id int) error {
+	return rc.routeRepository.DeleteRoute(ctx, tenantID(ctx), id)
+}
+
+func (rc *RouteController) History(ctx context.Context, id int, from, to time.Time) (History, error) {
+	route, err := rc.routeRepository.FindRoute(ctx, tenantID(ctx), id)
+	if err != nil {
+		return History{}, err
+	}
+
+	series, err := rc.routeRepository.ListHistory(ctx, route.ID, from, to)
+	if err != nil {
+		return History{}, err
+	}
+
+	return History{
+		Route:  route,
+		From:   from,
+		To:     to,
+		Series: series,
+	}, nil
+}
+
+// RunDue re-quotes the routes whose next run is due and returns how many were
+// processed. A failing route is recorded on the route and does not stop the
+// others.
+func (rc *RouteController) RunDue(ctx context.Context) (int, error) {
+	routes, err := rc.routeRepository.ClaimDueRoutes(ctx, routesPerRun)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, route := range routes {
+		quoteErr := rc.requote(ctx, route)
+		if quoteErr != nil {
+			slog.Warn("failed to re-quote watched route", "route_id", route.ID, "error", quoteErr)
+		}
+
+		if err := rc.routeRepository.SetRouteError(ctx, route.ID, quoteErr); err != nil {
+			return len(routes), err
+		}
+	}
+
+	return len(routes), nil
+}
+
+func (rc *RouteController) requote(ctx context.Context, route Route) error {
+	shipper := tenant.Default(rc.cfg)
+	if route.TenantID != nil {
+		t, err := rc.tenantController.FindTenant(ctx, *route.TenantID)
+		if err != nil {
+			return err
+		}
+		shipper = t
+		ctx = tenant.NewContext(ctx, t)
+	}
+
+	destination, err := strconv.Atoi(route.DestinationZipCode)
+	if err != nil {
+		return err
+	}
+
+	request := quote.NewFastDeliveryRequest(shipper, route.OriginZipCode, destination, route.Volumes)
+
+	usage, err := rc.tenantController.ConsumeQuote(ctx)
+	if err != nil {
+		return err
+	}
+
+	response, err := rc.api.SimulateQuote(ctx, request)
+	if err != nil {
+		if refundErr := rc.tenantController.RefundQuote(ctx, usage); refundErr != nil {
+			slog.Error("failed to refund quote quota", "tenant", usage.TenantName, "error", refundErr)
+		}
+
+		return err
+	}
+
+	response, expired := quote.DropExpiredOffers(response, time.Now())
+
+	// Only prices and deadlines are kept in the history, so skip the weights.
+	offers := quote.NewQuoteResponse(response, nil, freightmath.Factors{})
+	offers.MarkUnavailable(expired, quote.ReasonExpired)
+	if len(offers.Carriers) == 0 {
+		return fmt.Errorf("no carrier offers for this route: %s", offers.Reasons())
+	}
+
+	return rc.routeRepository.SavePrices(ctx, route.ID, offers.Carriers)
+}
+
+func tenantID(ctx context.Context) *int {
+	if t, ok := tenant.FromContext(ctx); ok {
+		return &t.ID
+	}
+
+	return nil
+}
@@ -0,0 +1,89 @@
+package route_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jeancarloshp/desafio-frete-rapido/internal/quote"
+	"github.com/jeancarloshp/desafio-frete-rapido/internal/route"
+	"github.com/jeancarloshp/desafio-frete-rapido/internal/tenant"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/config"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/database/databasetest"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/database/querier"
+	fastdeliveryapi "github.com/jeancarloshp/desafio-frete-rapido/pkg/fastdelivery_api"
+)
+
+// As recotações agendadas contam na cota mensal do tenant como qualquer outra
+// cotação
+func TestRunDue_ChargesTenantQuota(t *testing.T) {
+	ctx := context.Background()
+	q := querier.New(databasetest.Migrated(t))
+
+	var calls atomic.Int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer upstream.Close()
+
+	cfg := &config.Config{
+		RouteWatchInterval:             6 * time.Hour,
+		FastDeliveryAPIBaseURL:         upstream.URL,
+		FastDeliveryAPITimeout:         5 * time.Second,
+		FastDeliveryAPIBreakerFailures: 5,
+		FastDeliveryAPIBreakerCooldown: time.Minute,
+	}
+
+	tenantController := tenant.NewTenantController(tenant.NewTenantRepository(q))
+
+	quota := 1
+	loja, err := tenantController.CreateTenant(ctx, tenant.Tenant{
+		Name:          "loja-a",
+		ShipperCNPJ:   "25438296000158",
+		ShipperToken:  "1d52a9b6b78cf07b08586152459a5c90",
+		PlatformCode:  "5AKVkHqCn",
+		OriginZipCode: 29161376,
+		MonthlyQuota:  &quota,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	tenantCtx := tenant.NewContext(ctx, loja)
+	controller := route.NewRouteController(cfg, route.NewRouteRepository(q), fastdeliveryapi.New(cfg), tenantController)
+
+	if _, err := controller.CreateRoute(tenantCtx, route.RouteRequest{
+		Name:               "sp",
+		DestinationZipCode: "01311000",
+		Volumes:            []quote.Volume{{Category: 7, Amount: 1, UnitaryWeight: 5, Price: 349, SKU: "abc-teste-123", Height: 0.2, Width: 0.2, Length: 0.2}},
+	}); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	// A única cotação do mês já foi usada
+	if _, err := tenantController.ConsumeQuote(tenantCtx); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if _, err := controller.RunDue(ctx); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if calls.Load() != 0 {
+		t.Errorf("Expected no upstream call over quota, got: %d", calls.Load())
+	}
+
+	routes, err := controller.ListRoutes(tenantCtx)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if len(routes) != 1 || !strings.Contains(routes[0].LastError, tenant.ErrQuotaExceeded.Error()) {
+		t.Errorf("Expected the route to record the exceeded quota, got: %+v", routes)
+	}
+}
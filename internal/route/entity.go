@@ -0,0 +1,61 @@
+package route
+
+import (
+	"time"
+
+	"github.com/jeancarloshp/desafio-frete-rapido/internal/quote"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/config"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/money"
+)
+
+// MinInterval keeps scheduled re-quotes from becoming a load test of the
+// upstream API.
+const MinInterval = config.MinRouteInterval
+
+type RouteRequest struct {
+	Name               string         `json:"name" validate:"required,max=255"`
+	OriginZipCode      int            `json:"origin_zipcode" validate:"omitempty,min=1000000,max=99999999"`
+	DestinationZipCode string         `json:"destination_zipcode" validate:"required,len=8,numeric"`
+	Volumes            []quote.Volume `json:"volumes" validate:"required,min=1,dive"`
+	Interval           string         `json:"interval"`
+}
+
+type Route struct {
+	ID                 int            `json:"id"`
+	TenantID           *int           `json:"-"`
+	Name               string         `json:"name"`
+	OriginZipCode      int            `json:"origin_zipcode"`
+	DestinationZipCode string         `json:"destination_zipcode"`
+	Volumes            []quote.Volume `json:"volumes"`
+	Interval           Duration       `json:"interval"`
+	NextRunAt          time.Time      `json:"next_run_at"`
+	LastRunAt          *time.Time     `json:"last_run_at,omitempty"`
+	LastError          string         `json:"last_error,omitempty"`
+	CreatedAt          time.Time      `json:"created_at"`
+}
+
+// Duration is serialized as a Go duration string such as "6h0m0s".
+type Duration time.Duration
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+type PricePoint struct {
+	QuotedAt time.Time   `json:"quoted_at"`
+	Price    money.Money `json:"price"`
+	Deadline int         `json:"deadline"`
+}
+
+type Series struct {
+	Carrier string       `json:"carrier"`
+	Service string       `json:"service"`
+	Points  []PricePoint `json:"points"`
+}
+
+type History struct {
+	Route  Route     `json:"route"`
+	From   time.Time `json:"from"`
+	To     time.Time `json:"to"`
+	Series []Series  `json:"series"`
+}
@@ -0,0 +1,76 @@
+package route_test
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jeancarloshp/desafio-frete-rapido/internal/route"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/config"
+)
+
+const volumesJSON = `[{"category":7,"amount":1,"unitary_weight":5,"price":349,"sku":"abc-teste-123","height":0.2,"width":0.2,"length":0.2}]`
+
+func newTestApp() *fiber.App {
+	cfg := &config.Config{RouteWatchInterval: 6 * time.Hour}
+	handler := route.NewRouteHandler(route.NewRouteController(cfg, nil, nil, nil))
+
+	app := fiber.New()
+	app.Post("/v1/routes", handler.CreateRouteHandler)
+	app.Get("/v1/routes/:id/history", handler.HistoryHandler)
+
+	return app
+}
+
+func TestCreateRouteHandler_RejectsInvalidRoutes(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{name: "missing name", body: `{"destination_zipcode":"01311000","volumes":` + volumesJSON + `}`},
+		{name: "invalid destination", body: `{"name":"sp","destination_zipcode":"0131","volumes":` + volumesJSON + `}`},
+		{name: "no volumes", body: `{"name":"sp","destination_zipcode":"01311000","volumes":[]}`},
+		{name: "interval too short", body: `{"name":"sp","destination_zipcode":"01311000","volumes":` + volumesJSON + `,"interval":"1m"}`},
+		{name: "invalid interval", body: `{"name":"sp","destination_zipcode":"01311000","volumes":` + volumesJSON + `,"interval":"daily"}`},
+	}
+
+	app := newTestApp()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/v1/routes", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			if resp.StatusCode != fiber.StatusBadRequest {
+				t.Errorf("Expected status 400, got: %d", resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestHistoryHandler_RejectsInvalidWindow(t *testing.T) {
+	app := newTestApp()
+
+	// O intervalo de datas é validado antes de qualquer consulta ao banco
+	for _, query := range []string{
+		"from=ontem",
+		"to=2026-01-01",
+		"from=2026-02-01T00:00:00Z&to=2026-01-01T00:00:00Z",
+	} {
+		resp, err := app.Test(httptest.NewRequest("GET", "/v1/routes/1/history?"+query, nil))
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+
+		if resp.StatusCode != fiber.StatusBadRequest {
+			t.Errorf("Expected status 400 for %q, got: %d", query, resp.StatusCode)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package route
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jeancarloshp/desafio-frete-rapido/internal/quote"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/database/querier"
+)
+
+var ErrNotFound = errors.New("watched route not found")
+
+type RouteRepository struct {
+	conn *querier.Queries
+}
+
+func NewRouteRepository(conn *querier.Queries) *RouteRepository {
+	return &RouteRepository{
+		conn: conn,
+	}
+}
+
+func (r *RouteRepository) CreateRoute(ctx context.Context, route Route) (Route, error) {
+	volumes, err := json.Marshal(route.Volumes)
+	if err != nil {
+		return Route{}, fmt.Errorf("failed to marshal route volumes: %w", err)
+	}
+
+	created, err := r.conn.CreateWatchedRoute(ctx, querier.CreateWatchedRouteParams{
+		TenantID:           route.TenantID,
+		Name:               route.Name,
+		OriginZipcode:      route.OriginZipCode,
+		DestinationZipcode: route.DestinationZipCode,
+		Volumes:            volumes,
+		IntervalSeconds:    int(time.Duration(route.Interval).Seconds()),
+	})
+	if err != nil {
+		return Route{}, fmt.Errorf("failed to create watched route: %w", err)
+	}
+
+	return toRoute(created)
+}
+
+func (r *RouteRepository) ListRoutes(ctx context.Context, tenantID *int) ([]Route, error) {
+	rows, err := r.conn.ListWatchedRoutes(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list watched routes: %w", err)
+	}
+
+	return toRoutes(rows)
+}
+
+func (r *RouteRepository) FindRoute(ctx context.Context, tenantID *int, id int) (Route, error) {
+	row, err := r.conn.FindWatchedRoute(ctx, querier.FindWatchedRouteParams{
+		ID:       int32(id),
+		TenantID: tenantID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Route{}, ErrNotFound
+	}
+	if err != nil {
+		return Route{}, fmt.Errorf("failed to find watched route: %w", err)
+	}
+
+	return toRoute(row)
+}
+
+func (r *RouteRepository) DeleteRoute(ctx context.Context, tenantID *int, id int) error {
+	rows, err := r.conn.DeleteWatchedRoute(ctx, querier.DeleteWatchedRouteParams{
+		ID:       int32(id),
+		TenantID: tenantID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete watched route: %w", err)
+	}
+
+	if rows == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// ClaimDueRoutes returns up to limit routes whose next run is due and moves
+// their next run one interval ahead, so other instances skip them.
+func (r *RouteRepository) ClaimDueRoutes(ctx context.Context, limit int) ([]Route, error) {
+	rows, err := r.conn.ClaimDueWatchedRoutes(ctx, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim due watched routes: %w", err)
+	}
+
+	return toRoutes(rows)
+}
+
+func (r *RouteRepository) SetRouteError(ctx context.Context, id int, routeErr error) error {
+	var message *string
+	if routeErr != nil {
+		m := routeErr.Error()
+		message = &m
+	}
+
+	if err := r.conn.SetWatchedRouteError(ctx, querier.SetWatchedRouteErrorParams{ID: int32(id), LastError: message}); err != nil {
+		return fmt.Errorf("failed to update watched route: %w", err)
+	}
+
+	return nil
+}
+
+func (r *RouteRepository) SavePrices(ctx context.Context, routeID int, carriers []quote.Carrier) error {
+	for _, c := range carriers {
+		err := r.conn.CreateRoutePrice(ctx, querier.CreateRoutePriceParams{
+			RouteID:     routeID,
+			CarrierName: c.Name,
+			Service:     c.Service,
+			Price:       c.Price,
+			Deadline:    c.Deadline,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to save route price: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (r *RouteRepository) ListHistory(ctx context.Context, routeID int, from, to time.Time) ([]Series, error) {
+	rows, err := r.conn.ListRoutePriceHistory(ctx, querier.ListRoutePriceHistoryParams{
+		RouteID:  routeID,
+		FromTime: pgtype.Timestamp{Time: from.UTC(), Valid: true},
+		ToTime:   pgtype.Timestamp{Time: to.UTC(), Valid: true},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list route price history: %w", err)
+	}
+
+	// Rows come ordered by carrier and service, so each series is contiguous.
+	series := []Series{}
+	for _, row := range rows {
+		last := len(series) - 1
+		if last < 0 || series[last].Carrier != row.CarrierName || series[last].Service != row.Service {
+			series = append(series, Series{Carrier: row.CarrierName, Service: row.Service})
+			last++
+		}
+
+		series[last].Points = append(series[last].Points, PricePoint{
+			QuotedAt: row.QuotedAt.Time,
+			Price:    row.Price,
+			Deadline: row.Deadline,
+		})
+	}
+
+	return series, nil
+}
+
+func toRoutes(rows []querier.WatchedRoute) ([]Route, error) {
+	routes := make([]Route, len(rows))
+	for i, row := range rows {
+		route, err := toRoute(row)
+		if err != nil {
+			return nil, err
+		}
+		routes[i] = route
+	}
+
+	return routes, nil
+}
+
+func toRoute(row querier.WatchedRoute) (Route, error) {
+	route := Route{
+		ID:                 int(row.ID),
+		TenantID:           row.TenantID,
+		Name:               row.Name,
+		OriginZipCode:      row.OriginZipcode,
+		DestinationZipCode: row.DestinationZipcode,
+		Interval:           Duration(time.Duration(row.IntervalSeconds) * time.Second),
+		NextRunAt:          row.NextRunAt.Time,
+		CreatedAt:          row.CreatedAt.Time,
+	}
+
+	if row.LastRunAt.Valid {
+		route.LastRunAt = &row.LastRunAt.Time
+	}
+
+	if row.LastError != nil {
+		route.LastError = *row.LastError
+	}
+
+	if err := json.Unmarshal(row.Volumes, &route.Volumes); err != nil {
+		return Route{}, fmt.Errorf("failed to unmarshal route volumes: %w", err)
+	}
+
+	return route, nil
+}
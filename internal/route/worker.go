@@ -0,0 +1,45 @@
+package route
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+type Scheduler struct {
+	routeController *RouteController
+	tick            time.Duration
+}
+
+func NewScheduler(routeController *RouteController, tick time.Duration) *Scheduler {
+	return &Scheduler{
+		routeController: routeController,
+		tick:            tick,
+	}
+}
+
+// Run re-quotes due routes every tick until ctx is cancelled.
+func (s *Scheduler) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.tick)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		for ctx.Err() == nil {
+			processed, err := s.routeController.RunDue(context.WithoutCancel(ctx))
+			if err != nil {
+				slog.Error("failed to run watched routes", "error", err)
+				break
+			}
+
+			if processed < routesPerRun {
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package spreadsheet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"github.com/jeancarloshp/desafio-frete-rapido/internal/quote"
+	"github.com/jeancarloshp/desafio-frete-rapido/internal/tenant"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/config"
+)
+
+var ErrTooManyCarts = errors.New("too many carts in spreadsheet")
+
+type SpreadsheetController struct {
+	cfg              *config.Config
+	quoteController  *quote.QuoteController
+	tenantController *tenant.TenantController
+}
+
+func NewSpreadsheetController(cfg *config.Config, quoteController *quote.QuoteController, tenantController *tenant.TenantController) *SpreadsheetController {
+	return &SpreadsheetController{
+		cfg:              cfg,
+		quoteController:  quoteController,
+		tenantController: tenantController,
+	}
+}
+
+// QuoteCSV quotes every cart of the CSV file and returns one result per
+// carrier offer, or a single result holding the error for carts that could
+// not be quoted.
+func (sc *SpreadsheetController) QuoteCSV(ctx context.Context, r io.Reader) ([]Result, error) {
+	carts, err := parseCSV(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(carts) > sc.cfg.BatchMaxItems {
+		return nil, fmt.Errorf("%w: got %d, the limit is %d", ErrTooManyCarts, len(carts), sc.cfg.BatchMaxItems)
+	}
+
+	results := make([][]Result, len(carts))
+	slots := make(chan struct{}, max(sc.cfg.FastDeliveryAPIMaxConcurrency, 1))
+
+	var wg sync.WaitGroup
+	for i, c := range carts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			results[i] = sc.quoteCart(ctx, c)
+		}()
+	}
+	wg.Wait()
+
+	var flattened []Result
+	for _, r := range results {
+		flattened = append(flattened, r...)
+	}
+
+	return flattened, nil
+}
+
+func (sc *SpreadsheetController) quoteCart(ctx context.Context, c *cart) []Result {
+	failed := func(message string) []Result {
+		return []Result{{
+			Reference: c.Reference,
+			ZipCode:   c.Request.Recipient.Address.ZipCode,
+			Error:     message,
+		}}
+	}
+
+	if len(c.Errors) > 0 {
+		return failed(strings.Join(c.Errors, "; "))
+	}
+
+	usage, err := sc.tenantController.ConsumeQuote(ctx)
+	if err != nil {
+		return failed(err.Error())
+	}
+
+	response, err := sc.quoteController.SimulateQuote(ctx, c.Request)
+	if err != nil {
+		if refundErr := sc.tenantController.RefundQuote(ctx, usage); refundErr != nil {
+			slog.Error("failed to refund quote quota", "tenant", usage.TenantName, "error", refundErr)
+		}
+
+		return failed(err.Error())
+	}
+
+	if len(response.Carriers) == 0 {
+		return failed("no carrier offers for this cart: " + response.Reasons())
+	}
+
+	results := make([]Result, len(response.Carriers))
+	for i, carrier := range response.Carriers {
+		results[i] = Result{
+			Reference: c.Reference,
+			ZipCode:   c.Request.Recipient.Address.ZipCode,
+			Carrier:   carrier.Name,
+			Service:   carrier.Service,
+			Deadline:  carrier.Deadline,
+			Price:     carrier.Price,
+			Estimated: carrier.Estimated,
+		}
+	}
+
+	return results
+}
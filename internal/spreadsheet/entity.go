@@ -0,0 +1,55 @@
+package spreadsheet
+
+import (
+	"github.com/jeancarloshp/desafio-frete-rapido/internal/quote"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/money"
+)
+
+type Format string
+
+const (
+	FormatCSV  Format = "csv"
+	FormatXLSX Format = "xlsx"
+)
+
+// Input columns. Rows sharing a reference are quoted together as one cart;
+// without a reference column every row is its own cart.
+const (
+	columnReference     = "reference"
+	columnZipCode       = "zipcode"
+	columnCategory      = "category"
+	columnAmount        = "amount"
+	columnUnitaryWeight = "unitary_weight"
+	columnPrice         = "price"
+	columnSKU           = "sku"
+	columnHeight        = "height"
+	columnWidth         = "width"
+	columnLength        = "length"
+)
+
+var requiredColumns = []string{
+	columnZipCode, columnCategory, columnAmount, columnUnitaryWeight,
+	columnPrice, columnSKU, columnHeight, columnWidth, columnLength,
+}
+
+// estimated marks prices from the local rate tables, quoted while Frete
+// Rápido was failing.
+var resultHeader = []string{"reference", "zipcode", "carrier", "service", "deadline", "price", "estimated", "error"}
+
+// cart is a group of input rows quoted as a single QuoteRequest.
+type cart struct {
+	Reference string
+	Request   quote.QuoteRequest
+	Errors    []string
+}
+
+type Result struct {
+	Reference string
+	ZipCode   string
+	Carrier   string
+	Service   string
+	Deadline  int
+	Price     money.Money
+	Estimated bool
+	Error     string
+}
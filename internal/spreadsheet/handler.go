@@ -0,0 +1,95 @@
+package spreadsheet
+
+import (
+	"bytes"
+	"errors"
+	"io"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type SpreadsheetHandler struct {
+	spreadsheetController *SpreadsheetController
+}
+
+func NewSpreadsheetHandler(spreadsheetController *SpreadsheetController) *SpreadsheetHandler {
+	handler := &SpreadsheetHandler{
+		spreadsheetController: spreadsheetController,
+	}
+
+	return handler
+}
+
+// QuoteImportHandler accepts the CSV either as a multipart "file" field or as
+// the raw request body.
+func (sh *SpreadsheetHandler) QuoteImportHandler(c *fiber.Ctx) error {
+	format, err := ParseFormat(c.Query("format"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	input, err := requestFile(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "invalid request body",
+			"details": err.Error(),
+		})
+	}
+
+	results, err := sh.spreadsheetController.QuoteCSV(c.UserContext(), input)
+	if errors.Is(err, ErrInvalidFile) || errors.Is(err, ErrTooManyCarts) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "validation failed",
+			"details": err.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "failed to process spreadsheet",
+			"details": err.Error(),
+		})
+	}
+
+	var output bytes.Buffer
+	if err := Write(&output, format, results); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "failed to write spreadsheet",
+			"details": err.Error(),
+		})
+	}
+
+	c.Set(fiber.HeaderContentType, format.ContentType())
+	c.Attachment("quotes." + string(format))
+
+	return c.Status(fiber.StatusOK).Send(output.Bytes())
+}
+
+func requestFile(c *fiber.Ctx) (io.Reader, error) {
+	form, err := c.MultipartForm()
+	if err != nil {
+		if len(c.Body()) == 0 {
+			return nil, errors.New("expected a CSV body or a multipart \"file\" field")
+		}
+		return bytes.NewReader(c.Body()), nil
+	}
+
+	files := form.File["file"]
+	if len(files) == 0 {
+		return nil, errors.New("missing multipart \"file\" field")
+	}
+
+	f, err := files[0].Open()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	content, err := io.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+
+	return bytes.NewReader(content), nil
+}
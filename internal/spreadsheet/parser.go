@@ -0,0 +1,211 @@
+package spreadsheet
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/jeancarloshp/desafio-frete-rapido/internal/quote"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/money"
+)
+
+var ErrInvalidFile = errors.New("invalid spreadsheet")
+
+// parseCSV reads the input file into carts. Problems with a single row are
+// recorded on its cart instead of failing the whole file; only an unreadable
+// file or a missing column is returned as an error.
+func parseCSV(r io.Reader) ([]*cart, error) {
+	br := bufio.NewReader(r)
+
+	reader := csv.NewReader(br)
+	reader.Comma = detectDelimiter(br)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: the file is empty", ErrInvalidFile)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+
+	for _, name := range requiredColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("%w: missing column %q", ErrInvalidFile, name)
+		}
+	}
+
+	v := validator.New()
+
+	var carts []*cart
+	byReference := make(map[string]*cart)
+
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+		}
+
+		if isBlank(record) {
+			continue
+		}
+
+		field := func(name string) string {
+			i, ok := columns[name]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		reference := field(columnReference)
+		if reference == "" {
+			reference = "row " + strconv.Itoa(line)
+		}
+
+		c, ok := byReference[reference]
+		if !ok {
+			c = &cart{Reference: reference}
+			c.Request.Recipient.Address.ZipCode = field(columnZipCode)
+			byReference[reference] = c
+			carts = append(carts, c)
+		}
+
+		rowErrors := &rowErrors{line: line}
+
+		if zipcode := field(columnZipCode); zipcode != c.Request.Recipient.Address.ZipCode {
+			rowErrors.add(columnZipCode, fmt.Sprintf("differs from the cart zipcode %q", c.Request.Recipient.Address.ZipCode))
+		}
+
+		volume := quote.Volume{
+			Category:      rowErrors.int(columnCategory, field(columnCategory)),
+			Amount:        rowErrors.int(columnAmount, field(columnAmount)),
+			UnitaryWeight: rowErrors.float(columnUnitaryWeight, field(columnUnitaryWeight)),
+			Price:         rowErrors.money(columnPrice, field(columnPrice)),
+			SKU:           field(columnSKU),
+			Height:        rowErrors.float(columnHeight, field(columnHeight)),
+			Width:         rowErrors.float(columnWidth, field(columnWidth)),
+			Length:        rowErrors.float(columnLength, field(columnLength)),
+		}
+
+		if len(rowErrors.messages) == 0 {
+			if err := v.Struct(volume); err != nil {
+				rowErrors.validation(err)
+			}
+		}
+
+		c.Request.Volumes = append(c.Request.Volumes, volume)
+		c.Errors = append(c.Errors, rowErrors.messages...)
+	}
+
+	for _, c := range carts {
+		if len(c.Errors) > 0 {
+			continue
+		}
+
+		if err := v.Struct(c.Request.Recipient); err != nil {
+			c.Errors = append(c.Errors, fmt.Sprintf("%s: must be a CEP with 8 digits", columnZipCode))
+		}
+	}
+
+	return carts, nil
+}
+
+// detectDelimiter picks ";" for files exported by spreadsheets configured for
+// Brazilian Portuguese, which use "," as the decimal separator.
+func detectDelimiter(br *bufio.Reader) rune {
+	line, _ := br.Peek(br.Size())
+	if i := bytes.IndexByte(line, '\n'); i >= 0 {
+		line = line[:i]
+	}
+
+	if bytes.Count(line, []byte(";")) > bytes.Count(line, []byte(",")) {
+		return ';'
+	}
+
+	return ','
+}
+
+func isBlank(record []string) bool {
+	for _, field := range record {
+		if strings.TrimSpace(field) != "" {
+			return false
+		}
+	}
+
+	return true
+}
+
+type rowErrors struct {
+	line     int
+	messages []string
+}
+
+func (e *rowErrors) add(column, message string) {
+	e.messages = append(e.messages, fmt.Sprintf("row %d: %s %s", e.line, column, message))
+}
+
+func (e *rowErrors) int(column, value string) int {
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		e.add(column, fmt.Sprintf("must be an integer, got %q", value))
+	}
+
+	return n
+}
+
+func (e *rowErrors) float(column, value string) float64 {
+	n, err := strconv.ParseFloat(strings.Replace(value, ",", ".", 1), 64)
+	if err != nil {
+		e.add(column, fmt.Sprintf("must be a number, got %q", value))
+	}
+
+	return n
+}
+
+func (e *rowErrors) money(column, value string) money.Money {
+	m, err := money.Parse(value)
+	if err != nil {
+		e.add(column, fmt.Sprintf("must be an amount, got %q", value))
+	}
+
+	return m
+}
+
+func (e *rowErrors) validation(err error) {
+	var fieldErrors validator.ValidationErrors
+	if !errors.As(err, &fieldErrors) {
+		e.add("volume", err.Error())
+		return
+	}
+
+	columnsByField := map[string]string{
+		"Category":      columnCategory,
+		"Amount":        columnAmount,
+		"UnitaryWeight": columnUnitaryWeight,
+		"Price":         columnPrice,
+		"SKU":           columnSKU,
+		"Height":        columnHeight,
+		"Width":         columnWidth,
+		"Length":        columnLength,
+	}
+
+	for _, fe := range fieldErrors {
+		e.add(columnsByField[fe.Field()], "is required")
+	}
+}
@@ -0,0 +1,98 @@
+package spreadsheet
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/money"
+)
+
+const header = "reference,zipcode,category,amount,unitary_weight,price,sku,height,width,length\n"
+
+func TestParseCSV_GroupsRowsByReference(t *testing.T) {
+	input := header +
+		"pedido-1,01311000,7,1,5,349,abc-1,0.2,0.2,0.2\n" +
+		"pedido-1,01311000,7,2,1,10,abc-2,0.1,0.1,0.1\n" +
+		"pedido-2,29161376,7,1,5,349,abc-1,0.2,0.2,0.2\n"
+
+	carts, err := parseCSV(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if len(carts) != 2 {
+		t.Fatalf("Expected 2 carts, got: %d", len(carts))
+	}
+
+	if len(carts[0].Request.Volumes) != 2 {
+		t.Errorf("Expected 2 volumes in the first cart, got: %d", len(carts[0].Request.Volumes))
+	}
+
+	if carts[1].Request.Recipient.Address.ZipCode != "29161376" {
+		t.Errorf("Expected zipcode 29161376, got: %s", carts[1].Request.Recipient.Address.ZipCode)
+	}
+
+	for _, c := range carts {
+		if len(c.Errors) > 0 {
+			t.Errorf("Expected no errors for %s, got: %v", c.Reference, c.Errors)
+		}
+	}
+}
+
+func TestParseCSV_RecordsRowErrorsPerCart(t *testing.T) {
+	input := header +
+		"pedido-1,01311000,7,um,5,349,abc-1,0.2,0.2,0.2\n" +
+		"pedido-2,01311000,7,1,5,349,abc-1,0,0.2,0.2\n" +
+		"pedido-3,123,7,1,5,349,abc-1,0.2,0.2,0.2\n" +
+		"pedido-4,01311000,7,1,5,349,abc-1,0.2,0.2,0.2\n"
+
+	carts, err := parseCSV(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	// Erros de uma linha não devem impedir as demais de serem cotadas
+	expected := []string{
+		`row 2: amount must be an integer, got "um"`,
+		"row 3: height is required",
+		"zipcode: must be a CEP with 8 digits",
+		"",
+	}
+
+	for i, c := range carts {
+		got := strings.Join(c.Errors, "; ")
+		if got != expected[i] {
+			t.Errorf("Expected errors %q for %s, got: %q", expected[i], c.Reference, got)
+		}
+	}
+}
+
+func TestParseCSV_SemicolonAndDecimalComma(t *testing.T) {
+	input := "zipcode;category;amount;unitary_weight;price;sku;height;width;length\n" +
+		"01311000;7;1;5,5;349,90;abc-1;0,2;0,2;0,2\n"
+
+	carts, err := parseCSV(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if len(carts) != 1 || len(carts[0].Errors) > 0 {
+		t.Fatalf("Expected one valid cart, got: %+v", carts)
+	}
+
+	if carts[0].Reference != "row 2" {
+		t.Errorf("Expected the row number as reference, got: %s", carts[0].Reference)
+	}
+
+	if price := carts[0].Request.Volumes[0].Price; price != money.FromCents(34990) {
+		t.Errorf("Expected price 349.90, got: %v", price)
+	}
+}
+
+func TestParseCSV_MissingColumn(t *testing.T) {
+	_, err := parseCSV(strings.NewReader("zipcode,category\n01311000,7\n"))
+	if !errors.Is(err, ErrInvalidFile) {
+		t.Errorf("Expected ErrInvalidFile, got: %v", err)
+	}
+}
@@ -0,0 +1,102 @@
+package spreadsheet
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/xuri/excelize/v2"
+)
+
+const xlsxSheet = "Quotes"
+
+func (f Format) ContentType() string {
+	if f == FormatXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+
+	return "text/csv; charset=utf-8"
+}
+
+func ParseFormat(value string) (Format, error) {
+	switch Format(value) {
+	case "", FormatCSV:
+		return FormatCSV, nil
+	case FormatXLSX:
+		return FormatXLSX, nil
+	}
+
+	return "", fmt.Errorf("unsupported format %q, use csv or xlsx", value)
+}
+
+func Write(w io.Writer, format Format, results []Result) error {
+	if format == FormatXLSX {
+		return writeXLSX(w, results)
+	}
+
+	return writeCSV(w, results)
+}
+
+func writeCSV(w io.Writer, results []Result) error {
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write(resultHeader); err != nil {
+		return err
+	}
+
+	for _, r := range results {
+		record := []string{r.Reference, r.ZipCode, r.Carrier, r.Service, "", "", "", r.Error}
+		if r.Error == "" {
+			record[4] = strconv.Itoa(r.Deadline)
+			record[5] = r.Price.String()
+			record[6] = strconv.FormatBool(r.Estimated)
+		}
+
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
+
+func writeXLSX(w io.Writer, results []Result) error {
+	file := excelize.NewFile()
+	defer file.Close()
+
+	if err := file.SetSheetName(file.GetSheetName(0), xlsxSheet); err != nil {
+		return err
+	}
+
+	header := make([]any, len(resultHeader))
+	for i, name := range resultHeader {
+		header[i] = name
+	}
+
+	if err := file.SetSheetRow(xlsxSheet, "A1", &header); err != nil {
+		return err
+	}
+
+	for i, r := range results {
+		row := []any{r.Reference, r.ZipCode, r.Carrier, r.Service, nil, nil, nil, r.Error}
+		if r.Error == "" {
+			row[4] = r.Deadline
+			row[5] = r.Price.Float64()
+			row[6] = r.Estimated
+		}
+
+		cell, err := excelize.CoordinatesToCellName(1, i+2)
+		if err != nil {
+			return err
+		}
+
+		if err := file.SetSheetRow(xlsxSheet, cell, &row); err != nil {
+			return err
+		}
+	}
+
+	return file.Write(w)
+}
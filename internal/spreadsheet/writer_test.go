@@ -0,0 +1,76 @@
+package spreadsheet_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+
+	"github.com/jeancarloshp/desafio-frete-rapido/internal/spreadsheet"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/money"
+	"github.com/xuri/excelize/v2"
+)
+
+var results = []spreadsheet.Result{
+	{Reference: "pedido-1", ZipCode: "01311000", Carrier: "CORREIOS", Service: "PAC", Deadline: 5, Price: money.FromCents(2550)},
+	{Reference: "pedido-1", ZipCode: "01311000", Carrier: "JADLOG", Service: ".Package", Deadline: 7, Price: money.FromCents(3190), Estimated: true},
+	{Reference: "pedido-2", ZipCode: "123", Error: "zipcode: must be a CEP with 8 digits"},
+}
+
+func TestWrite_CSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := spreadsheet.Write(&buf, spreadsheet.FormatCSV, results); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Expected valid CSV, got: %v", err)
+	}
+
+	if len(records) != 4 {
+		t.Fatalf("Expected header and 3 rows, got: %d", len(records))
+	}
+
+	if records[1][5] != "25.50" || records[1][6] != "false" {
+		t.Errorf("Expected price 25.50 from Frete Rápido, got: %v", records[1])
+	}
+
+	// Ofertas das tabelas locais são marcadas como estimadas
+	if records[2][6] != "true" {
+		t.Errorf("Expected the rate table offer to be estimated, got: %v", records[2])
+	}
+
+	if records[3][7] != results[2].Error || records[3][5] != "" || records[3][6] != "" {
+		t.Errorf("Expected only the error column for a failed cart, got: %v", records[3])
+	}
+}
+
+func TestWrite_XLSX(t *testing.T) {
+	var buf bytes.Buffer
+	if err := spreadsheet.Write(&buf, spreadsheet.FormatXLSX, results); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	file, err := excelize.OpenReader(&buf)
+	if err != nil {
+		t.Fatalf("Expected valid XLSX, got: %v", err)
+	}
+	defer file.Close()
+
+	rows, err := file.GetRows("Quotes")
+	if err != nil {
+		t.Fatalf("Expected Quotes sheet, got: %v", err)
+	}
+
+	if len(rows) != 4 {
+		t.Fatalf("Expected header and 3 rows, got: %d", len(rows))
+	}
+
+	if rows[1][2] != "CORREIOS" || rows[1][4] != "5" || rows[1][6] != "FALSE" {
+		t.Errorf("Expected CORREIOS with deadline 5 from Frete Rápido, got: %v", rows[1])
+	}
+
+	if rows[2][6] != "TRUE" {
+		t.Errorf("Expected the rate table offer to be estimated, got: %v", rows[2])
+	}
+}
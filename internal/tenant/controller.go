@@ -0,0 +1,174 @@
+package tenant
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Keys are "fr_" followed by the hex encoded random bytes. The first
+// apiKeyPrefixChars hex characters identify the key for revocation and must
+// be unique, so they are wide enough to make collisions negligible.
+const (
+	apiKeyPrefix      = "fr_"
+	apiKeyPrefixChars = 16
+	apiKeyRandomBytes = 24
+)
+
+var ErrInvalidAPIKey = errors.New("invalid api key")
+
+type TenantController struct {
+	tenantRepository *TenantRepository
+	now              func() time.Time
+}
+
+func NewTenantController(tenantRepository *TenantRepository) *TenantController {
+	return &TenantController{
+		tenantRepository: tenantRepository,
+		now:              time.Now,
+	}
+}
+
+func (tc *TenantController) CreateTenant(ctx context.Context, t Tenant) (Tenant, error) {
+	v := validator.New()
+	if err := v.Struct(t); err != nil {
+		return Tenant{}, fmt.Errorf("invalid tenant: %w", err)
+	}
+
+	return tc.tenantRepository.CreateTenant(ctx, t)
+}
+
+func (tc *TenantController) FindTenant(ctx context.Context, id int) (Tenant, error) {
+	return tc.tenantRepository.FindTenantByID(ctx, id)
+}
+
+func (tc *TenantController) FindTenantByName(ctx context.Context, name string) (Tenant, error) {
+	return tc.tenantRepository.FindTenantByName(ctx, name)
+}
+
+func (tc *TenantController) IssueAPIKey(ctx context.Context, tenantName string) (APIKey, error) {
+	t, err := tc.tenantRepository.FindTenantByName(ctx, tenantName)
+	if err != nil {
+		return APIKey{}, err
+	}
+
+	random := make([]byte, apiKeyRandomBytes)
+	if _, err := rand.Read(random); err != nil {
+		return APIKey{}, fmt.Errorf("failed to generate api key: %w", err)
+	}
+
+	secret := hex.EncodeToString(random)
+	key := APIKey{
+		Prefix: secret[:apiKeyPrefixChars],
+		Key:    apiKeyPrefix + secret,
+	}
+
+	if err := tc.tenantRepository.SaveAPIKey(ctx, t.ID, key.Prefix, hashAPIKey(key.Key)); err != nil {
+		return APIKey{}, err
+	}
+
+	return key, nil
+}
+
+func (tc *TenantController) RevokeAPIKey(ctx context.Context, prefix string) error {
+	revoked, err := tc.tenantRepository.RevokeAPIKey(ctx, prefix)
+	if err != nil {
+		return err
+	}
+
+	if !revoked {
+		return fmt.Errorf("no active api key with prefix %q", prefix)
+	}
+
+	return nil
+}
+
+func (tc *TenantController) Authenticate(ctx context.Context, key string) (Tenant, error) {
+	if !strings.HasPrefix(key, apiKeyPrefix) || len(key) != len(apiKeyPrefix)+2*apiKeyRandomBytes {
+		return Tenant{}, ErrInvalidAPIKey
+	}
+
+	t, err := tc.tenantRepository.FindTenantByAPIKeyHash(ctx, hashAPIKey(key))
+	if errors.Is(err, ErrNotFound) {
+		return Tenant{}, ErrInvalidAPIKey
+	}
+
+	return t, err
+}
+
+// ConsumeQuote charges one quote to the monthly quota of the tenant in ctx and
+// is the only place quotas are enforced: every path that asks the carriers for
+// prices calls it first, and hands the usage to RefundQuote when the quote
+// fails. Requests without a tenant are not metered, nor is anything when tc is
+// nil, and get a zero QuotaUsage.
+func (tc *TenantController) ConsumeQuote(ctx context.Context) (QuotaUsage, error) {
+	t, ok := FromContext(ctx)
+	if tc == nil || !ok {
+		return QuotaUsage{}, nil
+	}
+
+	period, resetsAt := billingPeriod(tc.now())
+	usage := QuotaUsage{
+		TenantID:   t.ID,
+		TenantName: t.Name,
+		Limit:      t.MonthlyQuota,
+		ResetsAt:   resetsAt,
+	}
+
+	quota := math.MaxInt32
+	if t.MonthlyQuota != nil {
+		quota = *t.MonthlyQuota
+	}
+
+	used, err := tc.tenantRepository.ConsumeQuote(ctx, t.ID, period, quota)
+	usage.Used = used
+
+	return usage, err
+}
+
+// RefundQuote gives back the quote charged by ConsumeQuote, in the period it
+// was charged to. Zero usages, from unmetered requests, are ignored.
+func (tc *TenantController) RefundQuote(ctx context.Context, usage QuotaUsage) error {
+	if tc == nil || usage.TenantID == 0 {
+		return nil
+	}
+
+	return tc.tenantRepository.RefundQuote(ctx, usage.TenantID, usage.ResetsAt.AddDate(0, -1, 0))
+}
+
+func (tc *TenantController) QuotaUsage(ctx context.Context) ([]QuotaUsage, error) {
+	period, resetsAt := billingPeriod(tc.now())
+
+	usage, err := tc.tenantRepository.ListQuotaUsage(ctx, period)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range usage {
+		usage[i].ResetsAt = resetsAt
+	}
+
+	return usage, nil
+}
+
+// billingPeriod returns the first day of the current month and the instant
+// the next one starts, both in UTC.
+func billingPeriod(now time.Time) (time.Time, time.Time) {
+	now = now.UTC()
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	return start, start.AddDate(0, 1, 0)
+}
+
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,247 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/database/databasetest"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/database/querier"
+)
+
+// newTestController usa um schema isolado do Postgres; o teste é ignorado
+// sem TEST_DATABASE_URL
+func newTestController(t *testing.T) *TenantController {
+	t.Helper()
+	return NewTenantController(NewTenantRepository(querier.New(databasetest.Migrated(t))))
+}
+
+func createTestTenant(t *testing.T, tc *TenantController, name string, quota *int) Tenant {
+	t.Helper()
+
+	created, err := tc.CreateTenant(context.Background(), Tenant{
+		Name:          name,
+		ShipperCNPJ:   "25438296000158",
+		ShipperToken:  "1d52a9b6b78cf07b08586152459a5c90",
+		PlatformCode:  "5AKVkHqCn",
+		OriginZipCode: 29161376,
+		MonthlyQuota:  quota,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error creating tenant, got: %v", err)
+	}
+
+	return created
+}
+
+func TestHashAPIKey(t *testing.T) {
+	hash := hashAPIKey("fr_abc")
+
+	if len(hash) != 64 {
+		t.Errorf("Expected a hex encoded sha256, got: %q", hash)
+	}
+
+	if hash != hashAPIKey("fr_abc") {
+		t.Error("Expected the hash to be deterministic")
+	}
+
+	if hash == hashAPIKey("fr_abd") {
+		t.Error("Expected different keys to have different hashes")
+	}
+}
+
+func TestBillingPeriod(t *testing.T) {
+	tests := []struct {
+		name      string
+		now       time.Time
+		wantStart time.Time
+		wantReset time.Time
+	}{
+		{
+			name:      "meio do mês",
+			now:       time.Date(2026, time.March, 15, 12, 0, 0, 0, time.UTC),
+			wantStart: time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC),
+			wantReset: time.Date(2026, time.April, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "virada do ano",
+			now:       time.Date(2026, time.December, 31, 23, 59, 59, 0, time.UTC),
+			wantStart: time.Date(2026, time.December, 1, 0, 0, 0, 0, time.UTC),
+			wantReset: time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			// 31/01 21h em São Paulo já é fevereiro em UTC
+			name:      "fuso horário",
+			now:       time.Date(2026, time.January, 31, 21, 0, 0, 0, time.FixedZone("BRT", -3*60*60)),
+			wantStart: time.Date(2026, time.February, 1, 0, 0, 0, 0, time.UTC),
+			wantReset: time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, reset := billingPeriod(tt.now)
+
+			if !start.Equal(tt.wantStart) || !reset.Equal(tt.wantReset) {
+				t.Errorf("Expected %s to %s, got: %s to %s", tt.wantStart, tt.wantReset, start, reset)
+			}
+		})
+	}
+}
+
+// Chaves mal formadas são recusadas antes de consultar o banco
+func TestAuthenticate_RejectsMalformedKeys(t *testing.T) {
+	tc := NewTenantController(nil)
+
+	for _, key := range []string{"", "abc", "fr_", "fr_0123456789abcdef", "xx_" + strings.Repeat("a", 2*apiKeyRandomBytes), "fr_" + strings.Repeat("a", 2*apiKeyRandomBytes+1)} {
+		if _, err := tc.Authenticate(context.Background(), key); !errors.Is(err, ErrInvalidAPIKey) {
+			t.Errorf("Expected %q to be rejected, got: %v", key, err)
+		}
+	}
+}
+
+// Sem tenant, ou sem banco (controller nil), nada é cobrado
+func TestConsumeQuote_SkipsUnmeteredRequests(t *testing.T) {
+	ctx := context.Background()
+
+	var unmetered *TenantController
+	if usage, err := unmetered.ConsumeQuote(NewContext(ctx, Tenant{ID: 1})); err != nil || usage.Limit != nil {
+		t.Errorf("Expected a nil controller not to meter quotes, got: %+v (%v)", usage, err)
+	}
+
+	if usage, err := NewTenantController(nil).ConsumeQuote(ctx); err != nil || usage.Limit != nil {
+		t.Errorf("Expected requests without a tenant not to be metered, got: %+v (%v)", usage, err)
+	}
+}
+
+func TestIssueAPIKey_AuthenticatesByFullKey(t *testing.T) {
+	ctx := context.Background()
+	tc := newTestController(t)
+	created := createTestTenant(t, tc, "loja-a", nil)
+
+	key, err := tc.IssueAPIKey(ctx, "loja-a")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if len(key.Prefix) != apiKeyPrefixChars || !strings.HasPrefix(key.Key, apiKeyPrefix+key.Prefix) {
+		t.Errorf("Expected the key to start with fr_ and its %d character prefix, got: %q (%q)", apiKeyPrefixChars, key.Key, key.Prefix)
+	}
+
+	authenticated, err := tc.Authenticate(ctx, key.Key)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if authenticated.ID != created.ID {
+		t.Errorf("Expected tenant %d, got: %d", created.ID, authenticated.ID)
+	}
+
+	// Mesmo prefixo, segredo diferente
+	tampered := key.Key[:len(key.Key)-1] + "0"
+	if key.Key[len(key.Key)-1] == '0' {
+		tampered = key.Key[:len(key.Key)-1] + "1"
+	}
+
+	if _, err := tc.Authenticate(ctx, tampered); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Errorf("Expected a key with the same prefix to be rejected, got: %v", err)
+	}
+
+	if _, err := tc.Authenticate(ctx, apiKeyPrefix+strings.Repeat("0", 2*apiKeyRandomBytes)); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Errorf("Expected an unknown key to be rejected, got: %v", err)
+	}
+}
+
+func TestRevokeAPIKey(t *testing.T) {
+	ctx := context.Background()
+	tc := newTestController(t)
+	createTestTenant(t, tc, "loja-a", nil)
+
+	revoked, err := tc.IssueAPIKey(ctx, "loja-a")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	kept, err := tc.IssueAPIKey(ctx, "loja-a")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if err := tc.RevokeAPIKey(ctx, revoked.Prefix); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if _, err := tc.Authenticate(ctx, revoked.Key); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Errorf("Expected a revoked key to be rejected, got: %v", err)
+	}
+
+	if _, err := tc.Authenticate(ctx, kept.Key); err != nil {
+		t.Errorf("Expected the other key of the tenant to keep working, got: %v", err)
+	}
+
+	if err := tc.RevokeAPIKey(ctx, revoked.Prefix); err == nil {
+		t.Error("Expected an error revoking a key twice")
+	}
+}
+
+func TestConsumeQuote_ResetsEachPeriod(t *testing.T) {
+	ctx := context.Background()
+	tc := newTestController(t)
+
+	quota := 2
+	limited := createTestTenant(t, tc, "loja-a", &quota)
+	unlimited := createTestTenant(t, tc, "loja-b", nil)
+
+	limitedCtx := NewContext(ctx, limited)
+
+	now := time.Date(2026, time.January, 31, 23, 0, 0, 0, time.UTC)
+	tc.now = func() time.Time { return now }
+
+	var charged QuotaUsage
+	for want := 1; want <= quota; want++ {
+		usage, err := tc.ConsumeQuote(limitedCtx)
+		charged = usage
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+
+		if usage.Used != want {
+			t.Errorf("Expected %d quotes used, got: %d", want, usage.Used)
+		}
+	}
+
+	usage, err := tc.ConsumeQuote(limitedCtx)
+	if !errors.Is(err, ErrQuotaExceeded) {
+		t.Fatalf("Expected ErrQuotaExceeded, got: %v", err)
+	}
+
+	if want := time.Date(2026, time.February, 1, 0, 0, 0, 0, time.UTC); !usage.ResetsAt.Equal(want) {
+		t.Errorf("Expected the quota to reset at %s, got: %s", want, usage.ResetsAt)
+	}
+
+	if _, err := tc.ConsumeQuote(NewContext(ctx, unlimited)); err != nil {
+		t.Errorf("Expected another tenant to be unaffected, got: %v", err)
+	}
+
+	// O reembolso vale para o período em que a cotação foi cobrada
+	if err := tc.RefundQuote(ctx, charged); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if usage, err = tc.ConsumeQuote(limitedCtx); err != nil || usage.Used != quota {
+		t.Errorf("Expected the refunded quote to be charged again, got: %d (%v)", usage.Used, err)
+	}
+
+	now = now.Add(time.Hour)
+
+	usage, err = tc.ConsumeQuote(limitedCtx)
+	if err != nil {
+		t.Fatalf("Expected the quota to reset in the next month, got: %v", err)
+	}
+
+	if usage.Used != 1 {
+		t.Errorf("Expected 1 quote used in the new month, got: %d", usage.Used)
+	}
+}
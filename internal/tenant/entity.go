@@ -0,0 +1,63 @@
+package tenant
+
+import (
+	"context"
+	"time"
+
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/config"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/fastdelivery_api/models"
+)
+
+type Tenant struct {
+	ID            int    `json:"id"`
+	Name          string `json:"name" validate:"required"`
+	ShipperCNPJ   string `json:"shipper_cnpj" validate:"required,len=14,numeric"`
+	ShipperToken  string `json:"-" validate:"required,len=32"`
+	PlatformCode  string `json:"platform_code" validate:"required"`
+	OriginZipCode int    `json:"origin_zipcode" validate:"required,min=1000000,max=99999999"`
+	MonthlyQuota  *int   `json:"monthly_quota,omitempty" validate:"omitempty,gt=0"`
+}
+
+type QuotaUsage struct {
+	TenantID   int       `json:"tenant_id"`
+	TenantName string    `json:"tenant_name"`
+	Limit      *int      `json:"limit"`
+	Used       int       `json:"used"`
+	ResetsAt   time.Time `json:"resets_at"`
+}
+
+type APIKey struct {
+	Prefix string `json:"prefix"`
+	Key    string `json:"key"`
+}
+
+type contextKey struct{}
+
+func (t Tenant) Shipper() models.Shipper {
+	return models.Shipper{
+		RegisteredNumber: t.ShipperCNPJ,
+		Token:            t.ShipperToken,
+		PlatformCode:     t.PlatformCode,
+	}
+}
+
+// Default is the tenant built from the global configuration, used when API
+// key authentication is disabled.
+func Default(cfg *config.Config) Tenant {
+	return Tenant{
+		Name:          "default",
+		ShipperCNPJ:   cfg.FastDeliveryAPISenderCNPJ,
+		ShipperToken:  cfg.FastDeliveryAPIToken,
+		PlatformCode:  cfg.FastDeliveryAPIPlatformCode,
+		OriginZipCode: cfg.FastDeliveryAPIZipCode,
+	}
+}
+
+func NewContext(ctx context.Context, t Tenant) context.Context {
+	return context.WithValue(ctx, contextKey{}, t)
+}
+
+func FromContext(ctx context.Context) (Tenant, bool) {
+	t, ok := ctx.Value(contextKey{}).(Tenant)
+	return t, ok
+}
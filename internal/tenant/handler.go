@@ -0,0 +1,106 @@
+package tenant
+
+import (
+	"errors"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const apiKeyHeader = "X-API-Key"
+
+type TenantHandler struct {
+	tenantController *TenantController
+}
+
+func NewTenantHandler(tenantController *TenantController) *TenantHandler {
+	handler := &TenantHandler{
+		tenantController: tenantController,
+	}
+
+	return handler
+}
+
+func (th *TenantHandler) AuthMiddleware(c *fiber.Ctx) error {
+	key := c.Get(apiKeyHeader)
+	if key == "" {
+		key, _ = strings.CutPrefix(c.Get(fiber.HeaderAuthorization), "Bearer ")
+	}
+
+	if key == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "missing api key",
+		})
+	}
+
+	t, err := th.tenantController.Authenticate(c.UserContext(), key)
+	if errors.Is(err, ErrInvalidAPIKey) {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "invalid api key",
+		})
+	}
+	if err != nil {
+		slog.Error("failed to authenticate api key", "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to authenticate request",
+		})
+	}
+
+	c.SetUserContext(NewContext(c.UserContext(), t))
+	c.Locals("tenant", t.Name)
+	c.Locals("api_key_prefix", key[len(apiKeyPrefix):len(apiKeyPrefix)+apiKeyPrefixChars])
+
+	return c.Next()
+}
+
+func (th *TenantHandler) QuotaMiddleware(c *fiber.Ctx) error {
+	// Route timeouts replace the user context further down the chain, so
+	// keep this one for the refund.
+	ctx := c.UserContext()
+
+	usage, err := th.tenantController.ConsumeQuote(ctx)
+	if usage.Limit != nil {
+		c.Set("X-Quota-Limit", strconv.Itoa(*usage.Limit))
+		c.Set("X-Quota-Remaining", strconv.Itoa(max(*usage.Limit-usage.Used, 0)))
+		c.Set("X-Quota-Reset", usage.ResetsAt.Format(time.RFC3339))
+	}
+
+	if errors.Is(err, ErrQuotaExceeded) {
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(usage.ResetsAt.Sub(th.tenantController.now()).Seconds())+1))
+		return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+			"error": "monthly quote quota exceeded",
+		})
+	}
+	if err != nil {
+		slog.Error("failed to consume quote quota", "tenant", usage.TenantName, "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to check quote quota",
+		})
+	}
+
+	// Only quotes that succeed count: invalid requests, timeouts and upstream
+	// failures give the quote back.
+	err = c.Next()
+	if err != nil || c.Response().StatusCode() >= fiber.StatusBadRequest {
+		if refundErr := th.tenantController.RefundQuote(ctx, usage); refundErr != nil {
+			slog.Error("failed to refund quote quota", "tenant", usage.TenantName, "error", refundErr)
+		}
+	}
+
+	return err
+}
+
+func (th *TenantHandler) QuotaUsageHandler(c *fiber.Ctx) error {
+	usage, err := th.tenantController.QuotaUsage(c.UserContext())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "failed to retrieve quota usage",
+			"details": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(usage)
+}
@@ -0,0 +1,256 @@
+package tenant
+
+import (
+	"context"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// newTestApp monta as rotas como em cmd/main.go: a autenticação só é
+// aplicada com AUTH_ENABLED=true, e a cota vale para o tenant autenticado
+func newTestApp(handler *TenantHandler, authEnabled bool) *fiber.App {
+	app := fiber.New()
+	if authEnabled {
+		app.Use(handler.AuthMiddleware)
+	}
+
+	app.Post("/v1/quote", handler.QuotaMiddleware, func(c *fiber.Ctx) error {
+		t, ok := FromContext(c.UserContext())
+		if !ok {
+			return c.SendString("anonymous")
+		}
+
+		return c.SendString(t.Name)
+	})
+
+	return app
+}
+
+func doRequest(t *testing.T, app *fiber.App, header, value string) (int, string) {
+	t.Helper()
+
+	req := httptest.NewRequest(fiber.MethodPost, "/v1/quote", nil)
+	if header != "" {
+		req.Header.Set(header, value)
+	}
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+
+	return resp.StatusCode, string(body)
+}
+
+func TestAuthMiddleware_RejectsMissingOrMalformedKeys(t *testing.T) {
+	app := newTestApp(NewTenantHandler(NewTenantController(nil)), true)
+
+	tests := []struct {
+		name     string
+		header   string
+		value    string
+		wantBody string
+	}{
+		{name: "sem chave", wantBody: "missing api key"},
+		{name: "outro esquema", header: fiber.HeaderAuthorization, value: "Basic dXNlcjpwYXNz", wantBody: "invalid api key"},
+		{name: "chave mal formada", header: apiKeyHeader, value: "fr_123", wantBody: "invalid api key"},
+		{name: "bearer mal formado", header: fiber.HeaderAuthorization, value: "Bearer abc", wantBody: "invalid api key"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := doRequest(t, app, tt.header, tt.value)
+
+			if status != fiber.StatusUnauthorized {
+				t.Errorf("Expected status %d, got: %d", fiber.StatusUnauthorized, status)
+			}
+
+			if !strings.Contains(body, tt.wantBody) {
+				t.Errorf("Expected body to contain %q, got: %s", tt.wantBody, body)
+			}
+		})
+	}
+}
+
+// Com AUTH_ENABLED=false nenhuma chave é exigida e não há cota a consumir
+func TestAuthDisabled_PassesThrough(t *testing.T) {
+	app := newTestApp(NewTenantHandler(NewTenantController(nil)), false)
+
+	status, body := doRequest(t, app, "", "")
+
+	if status != fiber.StatusOK || body != "anonymous" {
+		t.Errorf("Expected the request to pass through, got: %d %s", status, body)
+	}
+}
+
+func TestAuthMiddleware_AuthenticatesTenant(t *testing.T) {
+	ctx := context.Background()
+	tc := newTestController(t)
+	createTestTenant(t, tc, "loja-a", nil)
+
+	key, err := tc.IssueAPIKey(ctx, "loja-a")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	app := newTestApp(NewTenantHandler(tc), true)
+
+	for _, header := range []struct{ name, value string }{
+		{name: apiKeyHeader, value: key.Key},
+		{name: fiber.HeaderAuthorization, value: "Bearer " + key.Key},
+	} {
+		status, body := doRequest(t, app, header.name, header.value)
+		if status != fiber.StatusOK || body != "loja-a" {
+			t.Errorf("Expected %s to authenticate loja-a, got: %d %s", header.name, status, body)
+		}
+	}
+
+	if err := tc.RevokeAPIKey(ctx, key.Prefix); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if status, _ := doRequest(t, app, apiKeyHeader, key.Key); status != fiber.StatusUnauthorized {
+		t.Errorf("Expected a revoked key to get status %d, got: %d", fiber.StatusUnauthorized, status)
+	}
+
+	if status, _ := doRequest(t, app, apiKeyHeader, apiKeyPrefix+strings.Repeat("0", 2*apiKeyRandomBytes)); status != fiber.StatusUnauthorized {
+		t.Errorf("Expected an unknown key to get status %d, got: %d", fiber.StatusUnauthorized, status)
+	}
+}
+
+func TestQuotaMiddleware_RejectsOverQuota(t *testing.T) {
+	ctx := context.Background()
+	tc := newTestController(t)
+
+	quota := 1
+	createTestTenant(t, tc, "loja-a", &quota)
+
+	key, err := tc.IssueAPIKey(ctx, "loja-a")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	now := time.Date(2026, time.March, 31, 23, 59, 0, 0, time.UTC)
+	tc.now = func() time.Time { return now }
+
+	app := newTestApp(NewTenantHandler(tc), true)
+
+	req := func() (int, string, string) {
+		r := httptest.NewRequest(fiber.MethodPost, "/v1/quote", nil)
+		r.Header.Set(apiKeyHeader, key.Key)
+
+		resp, err := app.Test(r)
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		defer resp.Body.Close()
+
+		return resp.StatusCode, resp.Header.Get("X-Quota-Remaining"), resp.Header.Get(fiber.HeaderRetryAfter)
+	}
+
+	if status, remaining, _ := req(); status != fiber.StatusOK || remaining != "0" {
+		t.Errorf("Expected the first quote to pass with 0 remaining, got: %d (remaining %q)", status, remaining)
+	}
+
+	status, remaining, retryAfter := req()
+	if status != fiber.StatusTooManyRequests {
+		t.Errorf("Expected status %d, got: %d", fiber.StatusTooManyRequests, status)
+	}
+
+	if remaining != "0" || retryAfter != "61" {
+		t.Errorf("Expected 0 remaining and a retry after 61s, got: %q and %q", remaining, retryAfter)
+	}
+
+	now = time.Date(2026, time.April, 1, 0, 0, 0, 0, time.UTC)
+
+	if status, _, _ := req(); status != fiber.StatusOK {
+		t.Errorf("Expected the quota to reset in the next month, got: %d", status)
+	}
+}
+
+// Cotações que falham, por validação ou no Frete Rápido, devolvem a cota
+func TestQuotaMiddleware_RefundsFailedQuotes(t *testing.T) {
+	ctx := context.Background()
+	tc := newTestController(t)
+
+	quota := 1
+	created := createTestTenant(t, tc, "loja-a", &quota)
+
+	key, err := tc.IssueAPIKey(ctx, "loja-a")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	handler := NewTenantHandler(tc)
+
+	app := fiber.New()
+	app.Use(handler.AuthMiddleware)
+	app.Post("/v1/quote", handler.QuotaMiddleware, func(c *fiber.Ctx) error {
+		switch string(c.Body()) {
+		case "invalid":
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "validation failed"})
+		case "upstream":
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to process quote request"})
+		case "timeout":
+			return fiber.ErrRequestTimeout
+		}
+
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	used := func() int {
+		t.Helper()
+
+		usage, err := tc.QuotaUsage(ctx)
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+
+		for _, u := range usage {
+			if u.TenantID == created.ID {
+				return u.Used
+			}
+		}
+
+		return 0
+	}
+
+	tests := []struct {
+		body       string
+		wantStatus int
+		wantUsed   int
+	}{
+		{body: "invalid", wantStatus: fiber.StatusBadRequest, wantUsed: 0},
+		{body: "upstream", wantStatus: fiber.StatusInternalServerError, wantUsed: 0},
+		{body: "timeout", wantStatus: fiber.StatusRequestTimeout, wantUsed: 0},
+		{body: "ok", wantStatus: fiber.StatusOK, wantUsed: 1},
+		{body: "invalid", wantStatus: fiber.StatusTooManyRequests, wantUsed: 1},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(fiber.MethodPost, "/v1/quote", strings.NewReader(tt.body))
+		req.Header.Set(apiKeyHeader, key.Key)
+
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != tt.wantStatus {
+			t.Errorf("%s: expected status %d, got: %d", tt.body, tt.wantStatus, resp.StatusCode)
+		}
+
+		if got := used(); got != tt.wantUsed {
+			t.Errorf("%s: expected %d quotes used, got: %d", tt.body, tt.wantUsed, got)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/database/querier"
+)
+
+var (
+	ErrNotFound      = errors.New("tenant not found")
+	ErrQuotaExceeded = errors.New("monthly quote quota exceeded")
+)
+
+type TenantRepository struct {
+	conn *querier.Queries
+}
+
+func NewTenantRepository(conn *querier.Queries) *TenantRepository {
+	return &TenantRepository{
+		conn: conn,
+	}
+}
+
+func (r *TenantRepository) CreateTenant(ctx context.Context, t Tenant) (Tenant, error) {
+	created, err := r.conn.CreateTenant(ctx, querier.CreateTenantParams{
+		Name:              t.Name,
+		ShipperCnpj:       t.ShipperCNPJ,
+		ShipperToken:      t.ShipperToken,
+		PlatformCode:      t.PlatformCode,
+		OriginZipcode:     t.OriginZipCode,
+		MonthlyQuoteQuota: t.MonthlyQuota,
+	})
+	if err != nil {
+		return Tenant{}, fmt.Errorf("failed to create tenant: %w", err)
+	}
+
+	return toTenant(created), nil
+}
+
+func (r *TenantRepository) FindTenantByName(ctx context.Context, name string) (Tenant, error) {
+	t, err := r.conn.FindTenantByName(ctx, name)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Tenant{}, ErrNotFound
+	}
+	if err != nil {
+		return Tenant{}, fmt.Errorf("failed to find tenant: %w", err)
+	}
+
+	return toTenant(t), nil
+}
+
+func (r *TenantRepository) FindTenantByID(ctx context.Context, id int) (Tenant, error) {
+	t, err := r.conn.FindTenantByID(ctx, int32(id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Tenant{}, ErrNotFound
+	}
+	if err != nil {
+		return Tenant{}, fmt.Errorf("failed to find tenant: %w", err)
+	}
+
+	return toTenant(t), nil
+}
+
+func (r *TenantRepository) SaveAPIKey(ctx context.Context, tenantID int, prefix, keyHash string) error {
+	_, err := r.conn.CreateAPIKey(ctx, querier.CreateAPIKeyParams{
+		TenantID: tenantID,
+		Prefix:   prefix,
+		KeyHash:  keyHash,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save api key: %w", err)
+	}
+
+	return nil
+}
+
+func (r *TenantRepository) FindTenantByAPIKeyHash(ctx context.Context, keyHash string) (Tenant, error) {
+	t, err := r.conn.FindTenantByAPIKeyHash(ctx, keyHash)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Tenant{}, ErrNotFound
+	}
+	if err != nil {
+		return Tenant{}, fmt.Errorf("failed to find tenant by api key: %w", err)
+	}
+
+	return toTenant(t), nil
+}
+
+func (r *TenantRepository) RevokeAPIKey(ctx context.Context, prefix string) (bool, error) {
+	rows, err := r.conn.RevokeAPIKey(ctx, prefix)
+	if err != nil {
+		return false, fmt.Errorf("failed to revoke api key: %w", err)
+	}
+
+	return rows > 0, nil
+}
+
+func (r *TenantRepository) ConsumeQuote(ctx context.Context, tenantID int, period time.Time, quota int) (int, error) {
+	used, err := r.conn.ConsumeQuoteUsage(ctx, querier.ConsumeQuoteUsageParams{
+		TenantID: tenantID,
+		Period:   pgtype.Date{Time: period, Valid: true},
+		Quota:    quota,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return quota, ErrQuotaExceeded
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to consume quote quota: %w", err)
+	}
+
+	return used, nil
+}
+
+func (r *TenantRepository) RefundQuote(ctx context.Context, tenantID int, period time.Time) error {
+	if err := r.conn.RefundQuoteUsage(ctx, querier.RefundQuoteUsageParams{
+		TenantID: tenantID,
+		Period:   pgtype.Date{Time: period, Valid: true},
+	}); err != nil {
+		return fmt.Errorf("failed to refund quote quota: %w", err)
+	}
+
+	return nil
+}
+
+func (r *TenantRepository) ListQuotaUsage(ctx context.Context, period time.Time) ([]QuotaUsage, error) {
+	rows, err := r.conn.ListQuoteUsage(ctx, pgtype.Date{Time: period, Valid: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list quote usage: %w", err)
+	}
+
+	usage := make([]QuotaUsage, len(rows))
+	for i, row := range rows {
+		usage[i] = QuotaUsage{
+			TenantID:   int(row.TenantID),
+			TenantName: row.TenantName,
+			Limit:      row.MonthlyQuoteQuota,
+			Used:       row.Quotes,
+		}
+	}
+
+	return usage, nil
+}
+
+func toTenant(t querier.Tenant) Tenant {
+	return Tenant{
+		ID:            int(t.ID),
+		Name:          t.Name,
+		ShipperCNPJ:   t.ShipperCnpj,
+		ShipperToken:  t.ShipperToken,
+		PlatformCode:  t.PlatformCode,
+		OriginZipCode: t.OriginZipcode,
+		MonthlyQuota:  t.MonthlyQuoteQuota,
+	}
+}
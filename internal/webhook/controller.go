@@ -0,0 +1,183 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jeancarloshp/desafio-frete-rapido/internal/quote"
+	"github.com/jeancarloshp/desafio-frete-rapido/internal/tenant"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/config"
+)
+
+const (
+	secretPrefix   = "whsec_"
+	publishTimeout = 5 * time.Second
+	retryBase      = 30 * time.Second
+	retryMax       = time.Hour
+)
+
+type WebhookController struct {
+	cfg               *config.Config
+	webhookRepository *WebhookRepository
+	client            *http.Client
+}
+
+func NewWebhookController(cfg *config.Config, webhookRepository *WebhookRepository) *WebhookController {
+	return &WebhookController{
+		cfg:               cfg,
+		webhookRepository: webhookRepository,
+		client:            newClient(cfg.WebhookTimeout),
+	}
+}
+
+func (wc *WebhookController) CreateSubscription(ctx context.Context, req SubscriptionRequest) (Subscription, error) {
+	if err := wc.checkURL(ctx, req.URL); err != nil {
+		return Subscription{}, err
+	}
+
+	raw := make([]byte, 24)
+	if _, err := rand.Read(raw); err != nil {
+		return Subscription{}, fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	secret := secretPrefix + hex.EncodeToString(raw)
+
+	subscription, err := wc.webhookRepository.CreateSubscription(ctx, tenantID(ctx), req.URL, secret, req.EventTypes)
+	if err != nil {
+		return Subscription{}, err
+	}
+
+	// The secret is only returned when the subscription is created.
+	subscription.Secret = secret
+
+	return subscription, nil
+}
+
+func (wc *WebhookController) ListSubscriptions(ctx context.Context) ([]Subscription, error) {
+	return wc.webhookRepository.ListSubscriptions(ctx, tenantID(ctx))
+}
+
+func (wc *WebhookController) DeleteSubscription(ctx context.Context, id int) error {
+	return wc.webhookRepository.DeleteSubscription(ctx, tenantID(ctx), id)
+}
+
+func (wc *WebhookController) ListDeliveries(ctx context.Context, subscriptionID int, status string) ([]Delivery, error) {
+	return wc.webhookRepository.ListDeliveries(ctx, tenantID(ctx), subscriptionID, status)
+}
+
+func (wc *WebhookController) RetryDelivery(ctx context.Context, subscriptionID, id int) error {
+	return wc.webhookRepository.RetryDelivery(ctx, tenantID(ctx), subscriptionID, id)
+}
+
+// Publish stores one delivery per matching subscription. Sending happens in
+// the delivery workers, so a slow or failing receiver never delays a quote.
+func (wc *WebhookController) Publish(ctx context.Context, event quote.Event) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		slog.Error("failed to marshal webhook event", "event", event.Type, "error", err)
+		return
+	}
+
+	// The quote may have failed because ctx expired, the event must still be
+	// stored.
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), publishTimeout)
+	defer cancel()
+
+	if _, err := wc.webhookRepository.EnqueueDeliveries(ctx, event.TenantID, event.Type, payload); err != nil {
+		slog.Error("failed to enqueue webhook deliveries", "event", event.Type, "error", err)
+	}
+}
+
+// DeliverNext sends one due delivery and schedules a retry or moves it to
+// the dead-letter state when it fails. It reports false when nothing is due.
+func (wc *WebhookController) DeliverNext(ctx context.Context) (bool, error) {
+	delivery, err := wc.webhookRepository.ClaimDelivery(ctx, wc.cfg.WebhookTimeout+time.Minute)
+	if errors.Is(err, errNoDueDeliveries) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	statusCode, err := wc.send(ctx, delivery)
+	if err == nil {
+		return true, wc.webhookRepository.RecordAttempt(ctx, delivery.ID, DeliverySucceeded, 0, statusCode, nil)
+	}
+
+	status, retryAfter := DeliveryPending, backoff(delivery.Attempts)
+	if delivery.Attempts >= wc.cfg.WebhookMaxAttempts {
+		status, retryAfter = DeliveryDead, 0
+		slog.Warn("webhook delivery moved to dead letter", "delivery_id", delivery.ID, "url", delivery.URL, "attempts", delivery.Attempts, "error", err)
+	}
+
+	return true, wc.webhookRepository.RecordAttempt(ctx, delivery.ID, status, retryAfter, statusCode, err)
+}
+
+func (wc *WebhookController) send(ctx context.Context, delivery pendingDelivery) (*int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "desafio-frete-rapido-webhooks")
+	req.Header.Set(EventHeader, delivery.EventType)
+	req.Header.Set(DeliveryHeader, strconv.Itoa(delivery.ID))
+	req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(SignatureHeader, Sign(delivery.Secret, timestamp, delivery.Payload))
+
+	resp, err := wc.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &resp.StatusCode, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	return &resp.StatusCode, nil
+}
+
+// Sign returns the signature sent in X-Webhook-Signature. Receivers compute
+// it over "<timestamp>.<body>" with the subscription secret and compare.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// backoff doubles the wait after every failed attempt, starting at 30s and
+// capped at one hour.
+func backoff(attempts int) time.Duration {
+	wait := retryBase
+	for i := 1; i < attempts && wait < retryMax; i++ {
+		wait *= 2
+	}
+
+	return min(wait, retryMax)
+}
+
+func tenantID(ctx context.Context) *int {
+	if t, ok := tenant.FromContext(ctx); ok {
+		return &t.ID
+	}
+
+	return nil
+}
@@ -0,0 +1,216 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/config"
+)
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		expected time.Duration
+	}{
+		{attempts: 1, expected: 30 * time.Second},
+		{attempts: 2, expected: time.Minute},
+		{attempts: 4, expected: 4 * time.Minute},
+		{attempts: 8, expected: time.Hour},
+		{attempts: 50, expected: time.Hour},
+	}
+
+	for _, tt := range tests {
+		if got := backoff(tt.attempts); got != tt.expected {
+			t.Errorf("Expected backoff %s after %d attempts, got: %s", tt.expected, tt.attempts, got)
+		}
+	}
+}
+
+func TestSend_SignsPayload(t *testing.T) {
+	const secret = "whsec_test"
+	payload := []byte(`{"type":"quote.created"}`)
+
+	var received http.Header
+	var body []byte
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Clone()
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	wc := NewWebhookController(&config.Config{WebhookTimeout: time.Second}, nil)
+	// O receptor de teste escuta em loopback, recusado pelo cliente de produção
+	wc.client = receiver.Client()
+
+	statusCode, err := wc.send(context.Background(), pendingDelivery{
+		ID:        7,
+		EventType: "quote.created",
+		Payload:   payload,
+		URL:       receiver.URL,
+		Secret:    secret,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if statusCode == nil || *statusCode != http.StatusNoContent {
+		t.Errorf("Expected status code 204, got: %v", statusCode)
+	}
+
+	if received.Get(EventHeader) != "quote.created" || received.Get(DeliveryHeader) != "7" {
+		t.Errorf("Expected event and delivery headers, got: %v", received)
+	}
+
+	// O destinatário deve conseguir validar a assinatura com o segredo e o timestamp recebidos
+	timestamp, err := strconv.ParseInt(received.Get(TimestampHeader), 10, 64)
+	if err != nil {
+		t.Fatalf("Expected numeric timestamp header, got: %q", received.Get(TimestampHeader))
+	}
+
+	expected := Sign(secret, timestamp, body)
+	if !hmac.Equal([]byte(received.Get(SignatureHeader)), []byte(expected)) {
+		t.Errorf("Expected signature %s, got: %s", expected, received.Get(SignatureHeader))
+	}
+}
+
+func TestSend_NonSuccessStatus(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer receiver.Close()
+
+	wc := NewWebhookController(&config.Config{WebhookTimeout: time.Second}, nil)
+	// O receptor de teste escuta em loopback, recusado pelo cliente de produção
+	wc.client = receiver.Client()
+
+	statusCode, err := wc.send(context.Background(), pendingDelivery{URL: receiver.URL, Payload: []byte(`{}`)})
+	if err == nil {
+		t.Fatal("Expected an error for status 502")
+	}
+
+	if statusCode == nil || *statusCode != http.StatusBadGateway {
+		t.Errorf("Expected status code 502 to be recorded, got: %v", statusCode)
+	}
+}
+
+func TestCreateSubscription_RejectsInternalURLs(t *testing.T) {
+	tests := []struct {
+		name        string
+		url         string
+		environment string
+	}{
+		{name: "loopback", url: "http://127.0.0.1:8080/hook"},
+		{name: "localhost", url: "http://localhost:5432"},
+		{name: "metadata da nuvem", url: "http://169.254.169.254/latest/meta-data"},
+		{name: "rede privada", url: "http://10.0.0.5/hook"},
+		{name: "rede privada 192.168", url: "https://192.168.0.10/hook"},
+		{name: "não especificado", url: "http://0.0.0.0/hook"},
+		{name: "loopback IPv6", url: "http://[::1]/hook"},
+		{name: "IPv4 mapeado em IPv6", url: "http://[::ffff:127.0.0.1]/hook"},
+		{name: "CGNAT", url: "http://100.64.0.1/hook"},
+		{name: "benchmarking", url: "http://198.18.0.1/hook"},
+		{name: "esquema não http", url: "ftp://8.8.8.8/hook"},
+		{name: "http em produção", url: "http://8.8.8.8/hook", environment: config.EnvProduction},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wc := NewWebhookController(&config.Config{Environment: tt.environment, WebhookTimeout: time.Second}, nil)
+
+			_, err := wc.CreateSubscription(context.Background(), SubscriptionRequest{URL: tt.url, EventTypes: EventTypes})
+			if !errors.Is(err, ErrForbiddenURL) {
+				t.Errorf("Expected ErrForbiddenURL for %s, got: %v", tt.url, err)
+			}
+		})
+	}
+}
+
+func TestCheckURL_AllowsPublicAddresses(t *testing.T) {
+	wc := NewWebhookController(&config.Config{Environment: config.EnvProduction, WebhookTimeout: time.Second}, nil)
+
+	if err := wc.checkURL(context.Background(), "https://8.8.8.8/hook"); err != nil {
+		t.Errorf("Expected a public https url to be accepted, got: %v", err)
+	}
+}
+
+func TestInternalIP(t *testing.T) {
+	tests := []struct {
+		ip       string
+		internal bool
+	}{
+		{ip: "8.8.8.8"},
+		{ip: "1.1.1.1"},
+		{ip: "2001:4860:4860::8888"},
+		{ip: "0.1.2.3", internal: true},
+		{ip: "10.1.2.3", internal: true},
+		{ip: "100.63.255.255"},
+		{ip: "100.64.0.0", internal: true},
+		{ip: "100.127.255.255", internal: true},
+		{ip: "100.128.0.0"},
+		{ip: "127.0.0.1", internal: true},
+		{ip: "169.254.169.254", internal: true},
+		{ip: "172.16.0.1", internal: true},
+		{ip: "192.0.0.8", internal: true},
+		{ip: "192.0.2.10", internal: true},
+		{ip: "192.168.1.1", internal: true},
+		{ip: "198.17.255.255"},
+		{ip: "198.18.0.0", internal: true},
+		{ip: "198.19.255.255", internal: true},
+		{ip: "198.20.0.0"},
+		{ip: "198.51.100.7", internal: true},
+		{ip: "203.0.113.7", internal: true},
+		{ip: "224.0.0.1", internal: true},
+		{ip: "255.255.255.255", internal: true},
+		{ip: "::1", internal: true},
+		{ip: "::ffff:100.64.0.1", internal: true},
+		{ip: "64:ff9b::a00:1", internal: true},
+		{ip: "2001:db8::1", internal: true},
+		{ip: "2002:a00:1::1", internal: true},
+		{ip: "fc00::1", internal: true},
+		{ip: "fe80::1", internal: true},
+		{ip: "ff02::1", internal: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			if got := internalIP(net.ParseIP(tt.ip)); got != tt.internal {
+				t.Errorf("Expected internal %v for %s, got: %v", tt.internal, tt.ip, got)
+			}
+
+			err := refuseInternal("tcp", net.JoinHostPort(tt.ip, "443"), nil)
+			if tt.internal != errors.Is(err, ErrForbiddenURL) {
+				t.Errorf("Expected the dialer to refuse %s: %v, got: %v", tt.ip, tt.internal, err)
+			}
+		})
+	}
+}
+
+// O endereço é conferido de novo na conexão, o que impede DNS rebinding
+func TestSend_RefusesInternalAddressAtConnect(t *testing.T) {
+	called := false
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	wc := NewWebhookController(&config.Config{WebhookTimeout: time.Second}, nil)
+
+	_, err := wc.send(context.Background(), pendingDelivery{URL: receiver.URL, Payload: []byte(`{}`)})
+	if !errors.Is(err, ErrForbiddenURL) {
+		t.Errorf("Expected ErrForbiddenURL, got: %v", err)
+	}
+
+	if called {
+		t.Error("Expected the internal receiver not to be called")
+	}
+}
@@ -0,0 +1,117 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+var ErrForbiddenURL = errors.New("webhook url is not allowed")
+
+// checkURL accepts only receivers on public addresses, so subscriptions
+// cannot make the workers post to the service's own network. Production also
+// requires https.
+func (wc *WebhookController) checkURL(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Hostname() == "" {
+		return fmt.Errorf("%w: %q is not an absolute url", ErrForbiddenURL, rawURL)
+	}
+
+	switch {
+	case u.Scheme != "http" && u.Scheme != "https":
+		return fmt.Errorf("%w: scheme must be http or https", ErrForbiddenURL)
+	case wc.cfg.IsProduction() && u.Scheme != "https":
+		return fmt.Errorf("%w: https is required in production", ErrForbiddenURL)
+	}
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, u.Hostname())
+	if err != nil {
+		return fmt.Errorf("%w: cannot resolve %s", ErrForbiddenURL, u.Hostname())
+	}
+
+	for _, addr := range addrs {
+		if internalIP(addr.IP) {
+			return fmt.Errorf("%w: %s resolves to the internal address %s", ErrForbiddenURL, u.Hostname(), addr.IP)
+		}
+	}
+
+	return nil
+}
+
+// newClient returns the client used for deliveries. Addresses are checked
+// again right before connecting, since a host may resolve to a public address
+// when subscribed and to an internal one later. Proxies are not used, as the
+// check would then only see the proxy.
+func newClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout, Control: refuseInternal}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
+
+func refuseInternal(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	if ip := net.ParseIP(host); ip == nil || internalIP(ip) {
+		return fmt.Errorf("%w: refusing to connect to %s", ErrForbiddenURL, host)
+	}
+
+	return nil
+}
+
+// nonPublicNets are the special-purpose ranges that the net.IP helpers do not
+// cover but are not reachable, or not meant to be reached, on the internet.
+var nonPublicNets = parseCIDRs(
+	"0.0.0.0/8",       // "this" network
+	"100.64.0.0/10",   // carrier-grade NAT
+	"192.0.0.0/24",    // IETF protocol assignments
+	"192.0.2.0/24",    // TEST-NET-1
+	"198.18.0.0/15",   // benchmarking
+	"198.51.100.0/24", // TEST-NET-2
+	"203.0.113.0/24",  // TEST-NET-3
+	"240.0.0.0/4",     // reserved, including broadcast
+	"64:ff9b::/96",    // NAT64, which may translate to internal IPv4 addresses
+	"64:ff9b:1::/48",  // local-use NAT64
+	"100::/64",        // discard-only
+	"2001::/23",       // IETF protocol assignments, including Teredo
+	"2001:db8::/32",   // documentation
+	"2002::/16",       // 6to4, which embeds any IPv4 address
+)
+
+func parseCIDRs(cidrs ...string) []*net.IPNet {
+	nets := make([]*net.IPNet, len(cidrs))
+	for i, cidr := range cidrs {
+		_, n, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		nets[i] = n
+	}
+
+	return nets
+}
+
+func internalIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsMulticast() || ip.IsLinkLocalUnicast() {
+		return true
+	}
+
+	for _, n := range nonPublicNets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
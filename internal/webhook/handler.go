@@ -0,0 +1,148 @@
+package webhook
+
+import (
+	"errors"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type WebhookHandler struct {
+	webhookController *WebhookController
+}
+
+func NewWebhookHandler(webhookController *WebhookController) *WebhookHandler {
+	handler := &WebhookHandler{
+		webhookController: webhookController,
+	}
+
+	return handler
+}
+
+func (wh *WebhookHandler) CreateSubscriptionHandler(c *fiber.Ctx) error {
+	var req SubscriptionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	v := validator.New()
+	if err := v.Struct(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "validation failed",
+			"details": err.Error(),
+		})
+	}
+
+	subscription, err := wh.webhookController.CreateSubscription(c.UserContext(), req)
+	if errors.Is(err, ErrForbiddenURL) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "validation failed",
+			"details": err.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "failed to create webhook subscription",
+			"details": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(subscription)
+}
+
+func (wh *WebhookHandler) ListSubscriptionsHandler(c *fiber.Ctx) error {
+	subscriptions, err := wh.webhookController.ListSubscriptions(c.UserContext())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "failed to list webhook subscriptions",
+			"details": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(subscriptions)
+}
+
+func (wh *WebhookHandler) DeleteSubscriptionHandler(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "id must be a positive integer",
+		})
+	}
+
+	err = wh.webhookController.DeleteSubscription(c.UserContext(), id)
+	if errors.Is(err, ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "webhook subscription not found",
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "failed to delete webhook subscription",
+			"details": err.Error(),
+		})
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (wh *WebhookHandler) ListDeliveriesHandler(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "id must be a positive integer",
+		})
+	}
+
+	status := c.Query("status")
+	switch DeliveryStatus(status) {
+	case "", DeliveryPending, DeliverySucceeded, DeliveryDead:
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "status must be one of pending, succeeded or dead",
+		})
+	}
+
+	deliveries, err := wh.webhookController.ListDeliveries(c.UserContext(), id, status)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "failed to list webhook deliveries",
+			"details": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(deliveries)
+}
+
+func (wh *WebhookHandler) RetryDeliveryHandler(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "id must be a positive integer",
+		})
+	}
+
+	deliveryID, err := c.ParamsInt("delivery_id")
+	if err != nil || deliveryID <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "delivery_id must be a positive integer",
+		})
+	}
+
+	err = wh.webhookController.RetryDelivery(c.UserContext(), id, deliveryID)
+	if errors.Is(err, ErrDeliveryNotDead) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "failed to retry webhook delivery",
+			"details": err.Error(),
+		})
+	}
+
+	return c.SendStatus(fiber.StatusAccepted)
+}
@@ -0,0 +1,183 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/database/querier"
+)
+
+var (
+	ErrNotFound        = errors.New("webhook subscription not found")
+	ErrDeliveryNotDead = errors.New("webhook delivery not found or not in the dead-letter state")
+	errNoDueDeliveries = errors.New("no webhook deliveries due")
+)
+
+type WebhookRepository struct {
+	conn *querier.Queries
+}
+
+func NewWebhookRepository(conn *querier.Queries) *WebhookRepository {
+	return &WebhookRepository{
+		conn: conn,
+	}
+}
+
+func (r *WebhookRepository) CreateSubscription(ctx context.Context, tenantID *int, url, secret string, eventTypes []string) (Subscription, error) {
+	created, err := r.conn.CreateWebhookSubscription(ctx, querier.CreateWebhookSubscriptionParams{
+		TenantID:   tenantID,
+		Url:        url,
+		Secret:     secret,
+		EventTypes: eventTypes,
+	})
+	if err != nil {
+		return Subscription{}, fmt.Errorf("failed to create webhook subscription: %w", err)
+	}
+
+	return toSubscription(created), nil
+}
+
+func (r *WebhookRepository) ListSubscriptions(ctx context.Context, tenantID *int) ([]Subscription, error) {
+	rows, err := r.conn.ListWebhookSubscriptions(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook subscriptions: %w", err)
+	}
+
+	subscriptions := make([]Subscription, len(rows))
+	for i, row := range rows {
+		subscriptions[i] = toSubscription(row)
+	}
+
+	return subscriptions, nil
+}
+
+func (r *WebhookRepository) DeleteSubscription(ctx context.Context, tenantID *int, id int) error {
+	rows, err := r.conn.DeleteWebhookSubscription(ctx, querier.DeleteWebhookSubscriptionParams{
+		ID:       int32(id),
+		TenantID: tenantID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook subscription: %w", err)
+	}
+
+	if rows == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *WebhookRepository) EnqueueDeliveries(ctx context.Context, tenantID *int, eventType string, payload []byte) (int64, error) {
+	rows, err := r.conn.EnqueueWebhookDeliveries(ctx, querier.EnqueueWebhookDeliveriesParams{
+		EventType: eventType,
+		Payload:   payload,
+		TenantID:  tenantID,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to enqueue webhook deliveries: %w", err)
+	}
+
+	return rows, nil
+}
+
+func (r *WebhookRepository) ClaimDelivery(ctx context.Context, lease time.Duration) (pendingDelivery, error) {
+	row, err := r.conn.ClaimWebhookDelivery(ctx, int(lease.Seconds()))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return pendingDelivery{}, errNoDueDeliveries
+	}
+	if err != nil {
+		return pendingDelivery{}, fmt.Errorf("failed to claim webhook delivery: %w", err)
+	}
+
+	return pendingDelivery{
+		ID:        int(row.ID),
+		EventType: row.EventType,
+		Payload:   row.Payload,
+		Attempts:  row.Attempts,
+		URL:       row.Url,
+		Secret:    row.Secret,
+	}, nil
+}
+
+func (r *WebhookRepository) RecordAttempt(ctx context.Context, id int, status DeliveryStatus, retryAfter time.Duration, statusCode *int, attemptErr error) error {
+	params := querier.RecordWebhookDeliveryAttemptParams{
+		ID:                int32(id),
+		Status:            string(status),
+		RetryAfterSeconds: int(retryAfter.Seconds()),
+		LastStatusCode:    statusCode,
+	}
+
+	if attemptErr != nil {
+		message := attemptErr.Error()
+		params.LastError = &message
+	}
+
+	if err := r.conn.RecordWebhookDeliveryAttempt(ctx, params); err != nil {
+		return fmt.Errorf("failed to record webhook delivery attempt: %w", err)
+	}
+
+	return nil
+}
+
+func (r *WebhookRepository) ListDeliveries(ctx context.Context, tenantID *int, subscriptionID int, status string) ([]Delivery, error) {
+	rows, err := r.conn.ListWebhookDeliveries(ctx, querier.ListWebhookDeliveriesParams{
+		SubscriptionID: subscriptionID,
+		TenantID:       tenantID,
+		Status:         status,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+
+	deliveries := make([]Delivery, len(rows))
+	for i, row := range rows {
+		deliveries[i] = Delivery{
+			ID:             int(row.ID),
+			EventType:      row.EventType,
+			Status:         DeliveryStatus(row.Status),
+			Attempts:       row.Attempts,
+			LastStatusCode: row.LastStatusCode,
+			CreatedAt:      row.CreatedAt.Time,
+			UpdatedAt:      row.UpdatedAt.Time,
+		}
+
+		if row.LastError != nil {
+			deliveries[i].LastError = *row.LastError
+		}
+
+		if deliveries[i].Status == DeliveryPending {
+			deliveries[i].NextAttemptAt = &row.NextAttemptAt.Time
+		}
+	}
+
+	return deliveries, nil
+}
+
+func (r *WebhookRepository) RetryDelivery(ctx context.Context, tenantID *int, subscriptionID, id int) error {
+	rows, err := r.conn.RetryWebhookDelivery(ctx, querier.RetryWebhookDeliveryParams{
+		ID:             int32(id),
+		SubscriptionID: subscriptionID,
+		TenantID:       tenantID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to retry webhook delivery: %w", err)
+	}
+
+	if rows == 0 {
+		return ErrDeliveryNotDead
+	}
+
+	return nil
+}
+
+func toSubscription(s querier.WebhookSubscription) Subscription {
+	return Subscription{
+		ID:         int(s.ID),
+		URL:        s.Url,
+		EventTypes: s.EventTypes,
+		CreatedAt:  s.CreatedAt.Time,
+	}
+}
@@ -0,0 +1,87 @@
+package webhook_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jeancarloshp/desafio-frete-rapido/internal/quote"
+	"github.com/jeancarloshp/desafio-frete-rapido/internal/webhook"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/database/databasetest"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/database/querier"
+)
+
+// Cada evento só deve ser entregue às assinaturas do mesmo tenant, e as
+// assinaturas globais só recebem eventos sem tenant
+func TestWebhookRepository_EnqueueDeliveriesIsolatesTenants(t *testing.T) {
+	ctx := context.Background()
+	q := querier.New(databasetest.Migrated(t))
+	repository := webhook.NewWebhookRepository(q)
+
+	tenants := make([]*int, 2)
+	for i, name := range []string{"loja-a", "loja-b"} {
+		created, err := q.CreateTenant(ctx, querier.CreateTenantParams{
+			Name:          name,
+			ShipperCnpj:   "25438296000158",
+			ShipperToken:  "token",
+			PlatformCode:  "platform",
+			OriginZipcode: 29161376,
+		})
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		id := int(created.ID)
+		tenants[i] = &id
+	}
+
+	subscriptions := make(map[string]webhook.Subscription)
+	for name, tenantID := range map[string]*int{"a": tenants[0], "b": tenants[1], "global": nil} {
+		subscription, err := repository.CreateSubscription(ctx, tenantID, "https://hooks.loja.com.br/"+name, "whsec_test", []string{quote.EventQuoteCreated})
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		subscriptions[name] = subscription
+	}
+
+	tests := []struct {
+		name     string
+		tenantID *int
+		want     string
+	}{
+		{name: "tenant a", tenantID: tenants[0], want: "a"},
+		{name: "tenant b", tenantID: tenants[1], want: "b"},
+		{name: "sem tenant", tenantID: nil, want: "global"},
+	}
+
+	for _, tt := range tests {
+		queued, err := repository.EnqueueDeliveries(ctx, tt.tenantID, quote.EventQuoteCreated, []byte(`{}`))
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+
+		if queued != 1 {
+			t.Errorf("Expected one delivery for %s, got: %d", tt.name, queued)
+		}
+	}
+
+	for name, subscription := range subscriptions {
+		deliveries, err := repository.ListDeliveries(ctx, tenantOf(name, tenants), subscription.ID, "")
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+
+		if len(deliveries) != 1 {
+			t.Errorf("Expected subscription %s to receive only its own event, got: %d deliveries", name, len(deliveries))
+		}
+	}
+}
+
+func tenantOf(name string, tenants []*int) *int {
+	switch name {
+	case "a":
+		return tenants[0]
+	case "b":
+		return tenants[1]
+	}
+
+	return nil
+}
@@ -0,0 +1,40 @@
+package webhook
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+type Worker struct {
+	webhookController *WebhookController
+	pollInterval      time.Duration
+}
+
+func NewWorker(webhookController *WebhookController, pollInterval time.Duration) *Worker {
+	return &Worker{
+		webhookController: webhookController,
+		pollInterval:      pollInterval,
+	}
+}
+
+// Run sends due deliveries until ctx is cancelled. The delivery in flight
+// when that happens is allowed to finish.
+func (w *Worker) Run(ctx context.Context) error {
+	for {
+		delivered, err := w.webhookController.DeliverNext(context.WithoutCancel(ctx))
+		if err != nil {
+			slog.Error("failed to process webhook delivery", "error", err)
+		}
+
+		if delivered && err == nil && ctx.Err() == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(w.pollInterval):
+		}
+	}
+}
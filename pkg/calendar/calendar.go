@@ -0,0 +1,237 @@
+// Package calendar turns delivery deadlines in business days into dates. It
+// skips weekends and the Brazilian national and state holidays, and dispatches
+// orders placed after the warehouse cut-off on the next business day.
+package calendar
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	// The service runs from a scratch image, which has no zoneinfo.
+	_ "time/tzdata"
+
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/cep"
+)
+
+// DateLayout is how delivery dates are written in quotes.
+const DateLayout = "2006-01-02"
+
+var ErrInvalidCalendar = errors.New("invalid holiday calendar")
+
+//go:embed holidays.json
+var embedded []byte
+
+// Holiday is a day without dispatches or deliveries. Date is either "MM-DD",
+// repeated every year, or "YYYY-MM-DD" for a single year. Holidays tied to
+// Easter, such as Carnaval, set Easter to their offset in days from Easter
+// Sunday instead. An empty UF makes the holiday national.
+type Holiday struct {
+	Name   string `json:"name"`
+	Date   string `json:"date,omitempty"`
+	Easter *int   `json:"easter,omitempty"`
+	UF     string `json:"uf,omitempty"`
+}
+
+func (h Holiday) validate() error {
+	switch {
+	case h.Name == "":
+		return errors.New("name is required")
+	case (h.Date == "") == (h.Easter == nil):
+		return errors.New("exactly one of date and easter is required")
+	case h.UF != "" && !cep.ValidUF(h.UF):
+		return fmt.Errorf("unknown uf %q", h.UF)
+	}
+
+	if h.Date == "" {
+		return nil
+	}
+
+	if _, err := time.Parse("01-02", h.Date); err == nil {
+		return nil
+	}
+
+	if _, err := time.Parse(DateLayout, h.Date); err == nil {
+		return nil
+	}
+
+	return fmt.Errorf("date %q must be MM-DD or YYYY-MM-DD", h.Date)
+}
+
+func (h Holiday) falls(day time.Time) bool {
+	if h.Easter != nil {
+		return sameDay(easter(day.Year(), day.Location()).AddDate(0, 0, *h.Easter), day)
+	}
+
+	if len(h.Date) == len("01-02") {
+		return day.Format("01-02") == h.Date
+	}
+
+	return day.Format(DateLayout) == h.Date
+}
+
+type Calendar struct {
+	holidays []Holiday
+	location *time.Location
+	cutoff   time.Duration
+}
+
+// New builds a calendar in location. Orders placed at cutoff, measured from
+// midnight, or later are dispatched on the next business day.
+func New(holidays []Holiday, location *time.Location, cutoff time.Duration) (*Calendar, error) {
+	if location == nil {
+		return nil, fmt.Errorf("%w: location is required", ErrInvalidCalendar)
+	}
+
+	if cutoff < 0 || cutoff >= 24*time.Hour {
+		return nil, fmt.Errorf("%w: cut-off must be a time of day, got %s", ErrInvalidCalendar, cutoff)
+	}
+
+	normalized := make([]Holiday, len(holidays))
+	for i, h := range holidays {
+		if err := h.validate(); err != nil {
+			return nil, fmt.Errorf("%w: holiday %d (%s): %v", ErrInvalidCalendar, i+1, h.Name, err)
+		}
+
+		h.UF = strings.ToUpper(h.UF)
+		normalized[i] = h
+	}
+
+	return &Calendar{
+		holidays: normalized,
+		location: location,
+		cutoff:   cutoff,
+	}, nil
+}
+
+// Embedded returns the holidays shipped with the service.
+func Embedded() ([]Holiday, error) {
+	return Parse(bytes.NewReader(embedded))
+}
+
+// Load reads a JSON array of holidays, in the same format as the embedded
+// calendar.
+func Load(path string) ([]Holiday, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open holiday calendar: %w", err)
+	}
+	defer file.Close()
+
+	return Parse(file)
+}
+
+func Parse(r io.Reader) ([]Holiday, error) {
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+
+	var holidays []Holiday
+	if err := decoder.Decode(&holidays); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCalendar, err)
+	}
+
+	return holidays, nil
+}
+
+// ParseCutoff reads a time of day written as "15:04".
+func ParseCutoff(value string) (time.Duration, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("%w: cut-off %q must be HH:MM", ErrInvalidCalendar, value)
+	}
+
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// Holiday returns the holiday on day, checking the national ones and those of
+// uf.
+func (c *Calendar) Holiday(day time.Time, uf string) (Holiday, bool) {
+	day = day.In(c.location)
+	for _, h := range c.holidays {
+		if h.UF != "" && !strings.EqualFold(h.UF, uf) {
+			continue
+		}
+
+		if h.falls(day) {
+			return h, true
+		}
+	}
+
+	return Holiday{}, false
+}
+
+func (c *Calendar) IsBusinessDay(day time.Time, uf string) bool {
+	day = day.In(c.location)
+	if day.Weekday() == time.Saturday || day.Weekday() == time.Sunday {
+		return false
+	}
+
+	_, holiday := c.Holiday(day, uf)
+	return !holiday
+}
+
+// DispatchDate is the day an order placed at now leaves a warehouse in uf:
+// the same day when it is a business day and now is before the cut-off, the
+// next business day otherwise. The result is at midnight in the calendar
+// location.
+func (c *Calendar) DispatchDate(now time.Time, uf string) time.Time {
+	now = now.In(c.location)
+	day := midnight(now)
+	if now.Sub(day) < c.cutoff && c.IsBusinessDay(day, uf) {
+		return day
+	}
+
+	return c.next(day, uf)
+}
+
+// DeliveryDate adds days business days of the destination uf to the dispatch
+// date of an order placed at now.
+func (c *Calendar) DeliveryDate(now time.Time, originUF, destinationUF string, days int) time.Time {
+	day := c.DispatchDate(now, originUF)
+	for range days {
+		day = c.next(day, destinationUF)
+	}
+
+	return day
+}
+
+func (c *Calendar) next(day time.Time, uf string) time.Time {
+	day = day.AddDate(0, 0, 1)
+	for !c.IsBusinessDay(day, uf) {
+		day = day.AddDate(0, 0, 1)
+	}
+
+	return day
+}
+
+func midnight(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+func sameDay(a, b time.Time) bool {
+	return a.Year() == b.Year() && a.YearDay() == b.YearDay()
+}
+
+// easter returns Easter Sunday of year in the Gregorian calendar, following
+// the anonymous algorithm (Meeus/Jones/Butcher).
+func easter(year int, location *time.Location) time.Time {
+	a := year % 19
+	b, c := year/100, year%100
+	d, e := b/4, b%4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i, k := c/4, c%4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, location)
+}
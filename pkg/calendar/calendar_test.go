@@ -0,0 +1,264 @@
+package calendar_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/calendar"
+)
+
+func newCalendar(t *testing.T, cutoff string) *calendar.Calendar {
+	t.Helper()
+
+	holidays, err := calendar.Embedded()
+	if err != nil {
+		t.Fatalf("Expected no error loading the embedded holidays, got: %v", err)
+	}
+
+	location, err := time.LoadLocation("America/Sao_Paulo")
+	if err != nil {
+		t.Fatalf("Expected no error loading the location, got: %v", err)
+	}
+
+	duration, err := calendar.ParseCutoff(cutoff)
+	if err != nil {
+		t.Fatalf("Expected no error parsing the cut-off, got: %v", err)
+	}
+
+	c, err := calendar.New(holidays, location, duration)
+	if err != nil {
+		t.Fatalf("Expected no error building the calendar, got: %v", err)
+	}
+
+	return c
+}
+
+func at(t *testing.T, value string) time.Time {
+	t.Helper()
+
+	location, _ := time.LoadLocation("America/Sao_Paulo")
+	parsed, err := time.ParseInLocation("2006-01-02 15:04", value, location)
+	if err != nil {
+		t.Fatalf("Expected no error parsing %q, got: %v", value, err)
+	}
+
+	return parsed
+}
+
+func TestIsBusinessDay(t *testing.T) {
+	c := newCalendar(t, "14:00")
+
+	tests := []struct {
+		name string
+		day  string
+		uf   string
+		want bool
+	}{
+		{name: "dia útil", day: "2025-03-10", uf: "SP", want: true},
+		{name: "sábado", day: "2025-03-08", uf: "SP", want: false},
+		{name: "domingo", day: "2025-03-09", uf: "SP", want: false},
+		{name: "feriado nacional fixo", day: "2025-04-21", uf: "SP", want: false},
+		{name: "carnaval", day: "2025-03-04", uf: "SP", want: false},
+		{name: "sexta-feira santa", day: "2025-04-18", uf: "RJ", want: false},
+		{name: "corpus christi", day: "2025-06-19", uf: "PR", want: false},
+		{name: "feriado estadual na UF", day: "2025-07-09", uf: "SP", want: false},
+		{name: "feriado estadual de outra UF", day: "2025-07-09", uf: "RJ", want: true},
+		{name: "feriado estadual com UF minúscula", day: "2025-09-22", uf: "rs", want: true},
+		{name: "feriado do ES ligado à páscoa", day: "2025-04-28", uf: "ES", want: false},
+		{name: "UF desconhecida só considera feriados nacionais", day: "2025-07-09", uf: "", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := c.IsBusinessDay(at(t, tt.day+" 10:00"), tt.uf); got != tt.want {
+				t.Errorf("Expected %s in %q to be a business day: %v, got: %v", tt.day, tt.uf, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestDispatchDate(t *testing.T) {
+	c := newCalendar(t, "14:00")
+
+	tests := []struct {
+		name string
+		now  string
+		uf   string
+		want string
+	}{
+		{name: "antes do corte", now: "2025-03-10 13:59", uf: "SP", want: "2025-03-10"},
+		{name: "no corte", now: "2025-03-10 14:00", uf: "SP", want: "2025-03-11"},
+		{name: "sexta depois do corte", now: "2025-03-14 18:00", uf: "SP", want: "2025-03-17"},
+		{name: "sábado", now: "2025-03-15 09:00", uf: "SP", want: "2025-03-17"},
+		{name: "véspera de feriado estadual", now: "2025-07-08 15:00", uf: "SP", want: "2025-07-10"},
+		{name: "feriado estadual", now: "2025-07-09 09:00", uf: "SP", want: "2025-07-10"},
+		{name: "feriado estadual de outra UF", now: "2025-07-09 09:00", uf: "MG", want: "2025-07-09"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := c.DispatchDate(at(t, tt.now), tt.uf).Format(calendar.DateLayout)
+			if got != tt.want {
+				t.Errorf("Expected dispatch on %s, got: %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestDispatchDate_ConvertsToCalendarLocation(t *testing.T) {
+	c := newCalendar(t, "14:00")
+
+	// 16:30 UTC são 13:30 em São Paulo, antes do corte
+	now := time.Date(2025, 3, 10, 16, 30, 0, 0, time.UTC)
+	if got := c.DispatchDate(now, "SP").Format(calendar.DateLayout); got != "2025-03-10" {
+		t.Errorf("Expected dispatch on 2025-03-10, got: %s", got)
+	}
+
+	// 17:30 UTC são 14:30 em São Paulo, depois do corte
+	now = time.Date(2025, 3, 10, 17, 30, 0, 0, time.UTC)
+	if got := c.DispatchDate(now, "SP").Format(calendar.DateLayout); got != "2025-03-11" {
+		t.Errorf("Expected dispatch on 2025-03-11, got: %s", got)
+	}
+}
+
+func TestDeliveryDate(t *testing.T) {
+	c := newCalendar(t, "14:00")
+
+	tests := []struct {
+		name        string
+		now         string
+		origin      string
+		destination string
+		days        int
+		want        string
+	}{
+		{name: "mesmo dia", now: "2025-03-10 10:00", origin: "SP", destination: "SP", days: 0, want: "2025-03-10"},
+		{name: "pula o fim de semana", now: "2025-03-13 10:00", origin: "SP", destination: "SP", days: 3, want: "2025-03-18"},
+		{name: "pula o carnaval", now: "2025-02-28 10:00", origin: "SP", destination: "SP", days: 1, want: "2025-03-05"},
+		{name: "pula o feriado do destino", now: "2025-04-22 10:00", origin: "SP", destination: "RJ", days: 1, want: "2025-04-24"},
+		{name: "ignora o feriado da origem no destino", now: "2025-07-08 10:00", origin: "SP", destination: "RJ", days: 1, want: "2025-07-09"},
+		{name: "despacho depois do corte", now: "2025-12-23 15:00", origin: "SP", destination: "SP", days: 2, want: "2025-12-29"},
+		{name: "virada do ano", now: "2025-12-30 10:00", origin: "SP", destination: "SP", days: 2, want: "2026-01-02"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := c.DeliveryDate(at(t, tt.now), tt.origin, tt.destination, tt.days).Format(calendar.DateLayout)
+			if got != tt.want {
+				t.Errorf("Expected delivery on %s, got: %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestHoliday(t *testing.T) {
+	c := newCalendar(t, "14:00")
+
+	// páscoa de 2024 foi em 31/03 e a de 2026 será em 05/04
+	tests := []struct {
+		day  string
+		want string
+	}{
+		{day: "2024-02-13", want: "Carnaval"},
+		{day: "2024-05-30", want: "Corpus Christi"},
+		{day: "2026-04-03", want: "Sexta-feira Santa"},
+		{day: "2026-11-20", want: "Dia Nacional de Zumbi e da Consciência Negra"},
+	}
+
+	for _, tt := range tests {
+		holiday, ok := c.Holiday(at(t, tt.day+" 00:00"), "SP")
+		if !ok || holiday.Name != tt.want {
+			t.Errorf("Expected %s to be %q, got: %q (%v)", tt.day, tt.want, holiday.Name, ok)
+		}
+	}
+}
+
+func TestNew_OneOffHoliday(t *testing.T) {
+	location, _ := time.LoadLocation("America/Sao_Paulo")
+	holidays, err := calendar.Parse(strings.NewReader(`[
+		{"name": "Ponto facultativo", "date": "2025-03-11"}
+	]`))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	c, err := calendar.New(holidays, location, 14*time.Hour)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if c.IsBusinessDay(at(t, "2025-03-11 10:00"), "SP") {
+		t.Error("Expected 2025-03-11 not to be a business day")
+	}
+
+	if !c.IsBusinessDay(at(t, "2026-03-11 10:00"), "SP") {
+		t.Error("Expected 2026-03-11 to be a business day")
+	}
+}
+
+func TestNew_Invalid(t *testing.T) {
+	location, _ := time.LoadLocation("America/Sao_Paulo")
+	offset := 1
+
+	tests := []struct {
+		name     string
+		holidays []calendar.Holiday
+		location *time.Location
+		cutoff   time.Duration
+	}{
+		{name: "sem nome", holidays: []calendar.Holiday{{Date: "01-01"}}, location: location},
+		{name: "sem data", holidays: []calendar.Holiday{{Name: "x"}}, location: location},
+		{name: "data e páscoa", holidays: []calendar.Holiday{{Name: "x", Date: "01-01", Easter: &offset}}, location: location},
+		{name: "data inválida", holidays: []calendar.Holiday{{Name: "x", Date: "13-01"}}, location: location},
+		{name: "UF inválida", holidays: []calendar.Holiday{{Name: "x", Date: "01-01", UF: "XX"}}, location: location},
+		{name: "sem location", location: nil},
+		{name: "corte negativo", location: location, cutoff: -time.Minute},
+		{name: "corte de 24h", location: location, cutoff: 24 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := calendar.New(tt.holidays, tt.location, tt.cutoff)
+			if !errors.Is(err, calendar.ErrInvalidCalendar) {
+				t.Errorf("Expected ErrInvalidCalendar, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestParseCutoff(t *testing.T) {
+	got, err := calendar.ParseCutoff("17:30")
+	if err != nil || got != 17*time.Hour+30*time.Minute {
+		t.Errorf("Expected 17h30m, got: %v (%v)", got, err)
+	}
+
+	for _, value := range []string{"", "24:00", "5pm", "17:30:00"} {
+		if _, err := calendar.ParseCutoff(value); !errors.Is(err, calendar.ErrInvalidCalendar) {
+			t.Errorf("Expected %q to be rejected, got: %v", value, err)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "holidays.json")
+	if err := os.WriteFile(path, []byte(`[{"name": "Aniversário da cidade", "date": "01-25", "uf": "SP"}]`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	holidays, err := calendar.Load(path)
+	if err != nil || len(holidays) != 1 || holidays[0].UF != "SP" {
+		t.Errorf("Expected one holiday in SP, got: %+v (%v)", holidays, err)
+	}
+
+	if err := os.WriteFile(path, []byte(`[{"name": "x", "day": "01-25"}]`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := calendar.Load(path); !errors.Is(err, calendar.ErrInvalidCalendar) {
+		t.Errorf("Expected unknown fields to be rejected, got: %v", err)
+	}
+}
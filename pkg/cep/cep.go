@@ -0,0 +1,69 @@
+// Package cep maps Brazilian postal codes to the state (UF) they belong to,
+// following the CEP ranges assigned by the Correios.
+package cep
+
+import "strings"
+
+type span struct {
+	start, end int
+	uf         string
+}
+
+// spans lists the ranges by their first five digits.
+var spans = []span{
+	{1000, 19999, "SP"},
+	{20000, 28999, "RJ"},
+	{29000, 29999, "ES"},
+	{30000, 39999, "MG"},
+	{40000, 48999, "BA"},
+	{49000, 49999, "SE"},
+	{50000, 56999, "PE"},
+	{57000, 57999, "AL"},
+	{58000, 58999, "PB"},
+	{59000, 59999, "RN"},
+	{60000, 63999, "CE"},
+	{64000, 64999, "PI"},
+	{65000, 65999, "MA"},
+	{66000, 68899, "PA"},
+	{68900, 68999, "AP"},
+	{69000, 69299, "AM"},
+	{69300, 69399, "RR"},
+	{69400, 69899, "AM"},
+	{69900, 69999, "AC"},
+	{70000, 72799, "DF"},
+	{72800, 72999, "GO"},
+	{73000, 73699, "DF"},
+	{73700, 76799, "GO"},
+	{76800, 76999, "RO"},
+	{77000, 77999, "TO"},
+	{78000, 78899, "MT"},
+	{79000, 79999, "MS"},
+	{80000, 87999, "PR"},
+	{88000, 89999, "SC"},
+	{90000, 99999, "RS"},
+}
+
+// UF returns the state of an 8-digit CEP, or false when the CEP is outside
+// every assigned range.
+func UF(zipcode int) (string, bool) {
+	prefix := zipcode / 1000
+	for _, s := range spans {
+		if prefix >= s.start && prefix <= s.end {
+			return s.uf, true
+		}
+	}
+
+	return "", false
+}
+
+// ValidUF reports whether uf is one of the 27 federative units, in any case.
+func ValidUF(uf string) bool {
+	uf = strings.ToUpper(uf)
+	for _, s := range spans {
+		if s.uf == uf {
+			return true
+		}
+	}
+
+	return false
+}
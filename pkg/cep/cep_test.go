@@ -0,0 +1,47 @@
+package cep_test
+
+import (
+	"testing"
+
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/cep"
+)
+
+func TestUF(t *testing.T) {
+	tests := []struct {
+		zipcode int
+		want    string
+		wantOK  bool
+	}{
+		{zipcode: 1311000, want: "SP", wantOK: true},
+		{zipcode: 20040002, want: "RJ", wantOK: true},
+		{zipcode: 29161376, want: "ES", wantOK: true},
+		{zipcode: 69301000, want: "RR", wantOK: true},
+		{zipcode: 69400000, want: "AM", wantOK: true},
+		{zipcode: 70040010, want: "DF", wantOK: true},
+		{zipcode: 72800000, want: "GO", wantOK: true},
+		{zipcode: 73000000, want: "DF", wantOK: true},
+		{zipcode: 99999999, want: "RS", wantOK: true},
+		{zipcode: 999999, wantOK: false},
+	}
+
+	for _, tt := range tests {
+		got, ok := cep.UF(tt.zipcode)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("Expected %08d to be in %q (%v), got: %q (%v)", tt.zipcode, tt.want, tt.wantOK, got, ok)
+		}
+	}
+}
+
+func TestValidUF(t *testing.T) {
+	for _, uf := range []string{"SP", "df", "Ac"} {
+		if !cep.ValidUF(uf) {
+			t.Errorf("Expected %s to be valid", uf)
+		}
+	}
+
+	for _, uf := range []string{"", "XX", "BRA"} {
+		if cep.ValidUF(uf) {
+			t.Errorf("Expected %q to be invalid", uf)
+		}
+	}
+}
@@ -1,69 +1,277 @@
 package config
 
 import (
+	"errors"
+	"fmt"
 	"reflect"
+	"strings"
+	"time"
 
+	"github.com/go-viper/mapstructure/v2"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/freightmath"
 	"github.com/spf13/viper"
 )
 
+const redactedValue = "********"
+
+// MinRouteInterval keeps scheduled re-quotes from becoming a load test of the
+// upstream API.
+const MinRouteInterval = 15 * time.Minute
+
+const (
+	StoragePostgres = "postgres"
+	StorageSQLite   = "sqlite"
+	StorageMemory   = "memory"
+)
+
 type Config struct {
-	AppPort           string `mapstructure:"APP_PORT"`
-	LoggingJSONFormat bool   `mapstructure:"LOGGING_JSON_FORMAT"`
-	LoggingLevel      string `mapstructure:"LOGGING_LEVEL"`
+	Environment string `mapstructure:"GO_ENV" validate:"required,oneof=development test production"`
+
+	AppPort             string        `mapstructure:"APP_PORT" validate:"required,tcpport"`
+	ShutdownGracePeriod time.Duration `mapstructure:"SHUTDOWN_GRACE_PERIOD" validate:"gt=0"`
+	LoggingJSONFormat   bool          `mapstructure:"LOGGING_JSON_FORMAT"`
+	LoggingLevel        string        `mapstructure:"LOGGING_LEVEL" validate:"omitempty,oneof=DEBUG INFO WARNING ERROR" reload:"hot"`
 
+	StorageBackend   string `mapstructure:"STORAGE_BACKEND" validate:"required,oneof=postgres sqlite memory"`
+	SQLitePath       string `mapstructure:"SQLITE_PATH"`
 	DatabaseHost     string `mapstructure:"DATABASE_HOST"`
-	DatabasePort     string `mapstructure:"DATABASE_PORT"`
+	DatabasePort     string `mapstructure:"DATABASE_PORT" validate:"omitempty,tcpport"`
 	DatabaseUser     string `mapstructure:"DATABASE_USER"`
-	DatabasePassword string `mapstructure:"DATABASE_PASSWORD"`
+	DatabasePassword string `mapstructure:"DATABASE_PASSWORD" redact:"true"`
 	DatabaseName     string `mapstructure:"DATABASE_NAME"`
 
-	HTTPCorsAllowedHeaders string `mapstructure:"HTTP_CORS_ALLOWED_HEADERS"`
-	HTTPCorsAllowedMethods string `mapstructure:"HTTP_CORS_ALLOWED_METHODS"`
-	HTTPCorsAllowedOrigins string `mapstructure:"HTTP_CORS_ALLOWED_ORIGINS"`
+	AuthEnabled bool   `mapstructure:"AUTH_ENABLED"`
+	AdminAPIKey string `mapstructure:"ADMIN_API_KEY" validate:"omitempty,min=32" redact:"true"`
+
+	RateLimitRequestsPerMinute int `mapstructure:"RATE_LIMIT_REQUESTS_PER_MINUTE" validate:"gt=0"`
+	RateLimitBurst             int `mapstructure:"RATE_LIMIT_BURST" validate:"gt=0"`
+
+	HTTPRecoverStackTrace  bool          `mapstructure:"HTTP_RECOVER_STACK_TRACE"`
+	HTTPCorsAllowedHeaders string        `mapstructure:"HTTP_CORS_ALLOWED_HEADERS" reload:"hot"`
+	HTTPCorsAllowedMethods string        `mapstructure:"HTTP_CORS_ALLOWED_METHODS" reload:"hot"`
+	HTTPCorsAllowedOrigins string        `mapstructure:"HTTP_CORS_ALLOWED_ORIGINS" validate:"origins" reload:"hot"`
+	HTTPBodyLimit          int           `mapstructure:"HTTP_BODY_LIMIT" validate:"gt=0"`
+	HTTPRequestTimeout     time.Duration `mapstructure:"HTTP_REQUEST_TIMEOUT" validate:"gt=0"`
+	HTTPRouteTimeouts      string        `mapstructure:"HTTP_ROUTE_TIMEOUTS" validate:"routetimeouts"`
+	HTTPImportTimeout      time.Duration `mapstructure:"HTTP_IMPORT_TIMEOUT" validate:"gt=0"`
+	HTTPTrustedProxies     string        `mapstructure:"HTTP_TRUSTED_PROXIES"`
+	HTTPProxyHeader        string        `mapstructure:"HTTP_PROXY_HEADER"`
+	HTTPHSTSMaxAge         int           `mapstructure:"HTTP_HSTS_MAX_AGE" validate:"gte=0"`
+
+	FastDeliveryAPIBaseURL      string        `mapstructure:"FASTDELIVERY_API_BASE_URL" validate:"required,http_url,noplaceholder"`
+	FastDeliveryAPITimeout      time.Duration `mapstructure:"FASTDELIVERY_API_TIMEOUT" validate:"gt=0" reload:"hot"`
+	FastDeliveryAPIToken        string        `mapstructure:"FASTDELIVERY_API_TOKEN" validate:"required,noplaceholder,len=32" redact:"true"`
+	FastDeliveryAPIPlatformCode string        `mapstructure:"FASTDELIVERY_API_PLATFORM_CODE" validate:"required,noplaceholder"`
+	FastDeliveryAPISenderCNPJ   string        `mapstructure:"FASTDELIVERY_API_SENDER_CNPJ" validate:"required,cnpj"`
+	FastDeliveryAPIZipCode      int           `mapstructure:"FASTDELIVERY_API_ZIP_CODE" validate:"required,cep"`
+
+	FastDeliveryAPIBreakerFailures int           `mapstructure:"FASTDELIVERY_API_BREAKER_FAILURES" validate:"gt=0"`
+	FastDeliveryAPIBreakerCooldown time.Duration `mapstructure:"FASTDELIVERY_API_BREAKER_COOLDOWN" validate:"gt=0"`
+	FastDeliveryAPIMaxConcurrency  int           `mapstructure:"FASTDELIVERY_API_MAX_CONCURRENCY" validate:"gt=0"`
+
+	BatchWorkers      int           `mapstructure:"BATCH_WORKERS" validate:"gt=0"`
+	BatchMaxItems     int           `mapstructure:"BATCH_MAX_ITEMS" validate:"gt=0"`
+	BatchPollInterval time.Duration `mapstructure:"BATCH_POLL_INTERVAL" validate:"gt=0"`
 
-	FastDeliveryAPIBaseURL      string `mapstructure:"FASTDELIVERY_API_BASE_URL"`
-	FastDeliveryAPIToken        string `mapstructure:"FASTDELIVERY_API_TOKEN"`
-	FastDeliveryAPIPlatformCode string `mapstructure:"FASTDELIVERY_API_PLATFORM_CODE"`
-	FastDeliveryAPISenderCNPJ   string `mapstructure:"FASTDELIVERY_API_SENDER_CNPJ"`
-	FastDeliveryAPIZipCode      int    `mapstructure:"FASTDELIVERY_API_ZIP_CODE"`
+	WebhookWorkers     int           `mapstructure:"WEBHOOK_WORKERS" validate:"gt=0"`
+	WebhookMaxAttempts int           `mapstructure:"WEBHOOK_MAX_ATTEMPTS" validate:"gt=0"`
+	WebhookTimeout     time.Duration `mapstructure:"WEBHOOK_TIMEOUT" validate:"gt=0"`
+
+	RouteWatchInterval     time.Duration `mapstructure:"ROUTE_WATCH_INTERVAL" validate:"gt=0"`
+	RouteSchedulerInterval time.Duration `mapstructure:"ROUTE_SCHEDULER_INTERVAL" validate:"gt=0"`
+
+	FreightCubageFactor          float64 `mapstructure:"FREIGHT_CUBAGE_FACTOR" validate:"gt=0"`
+	FreightCubageFactorsModal    string  `mapstructure:"FREIGHT_CUBAGE_FACTORS_BY_MODAL" validate:"cubagefactors"`
+	FreightCubageFactorsCarriers string  `mapstructure:"FREIGHT_CUBAGE_FACTORS_BY_CARRIER" validate:"cubagefactors"`
+	FreightRateTablesPath        string  `mapstructure:"FREIGHT_RATE_TABLES_PATH"`
+	FreightPricingRulesPath      string  `mapstructure:"FREIGHT_PRICING_RULES_PATH" reload:"hot" watch:"file"`
+
+	DeliveryTimezone       string `mapstructure:"DELIVERY_TIMEZONE" validate:"required,timezone"`
+	DeliveryDispatchCutoff string `mapstructure:"DELIVERY_DISPATCH_CUTOFF" validate:"required,datetime=15:04"`
+	DeliveryHolidaysPath   string `mapstructure:"DELIVERY_HOLIDAYS_PATH"`
+
+	HealthUpstreamProbe         bool          `mapstructure:"HEALTH_UPSTREAM_PROBE"`
+	HealthUpstreamProbeInterval time.Duration `mapstructure:"HEALTH_UPSTREAM_PROBE_INTERVAL" validate:"gt=0"`
+
+	configFile string
 }
 
-func New() *Config {
+type Setting struct {
+	Key   string
+	Value string
+}
+
+func New(args []string) (*Config, error) {
 	config := &Config{}
-	err := load(config)
+	problems, err := load(config, args)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 
-	return config
+	if err := config.Validate(); err != nil {
+		var validationErr *ValidationError
+		if !errors.As(err, &validationErr) {
+			return nil, err
+		}
+		problems.merge(validationErr)
+	}
+
+	if err := problems.errOrNil(); err != nil {
+		return config, err
+	}
+
+	return config, nil
 }
 
-func load(config *Config) error {
-	viper.AddConfigPath(".")
-	viper.SetConfigType("env")
-	viper.SetConfigName(".env.local")
+func load(config *Config, args []string) (*ValidationError, error) {
+	v := viper.New()
+	v.AddConfigPath(".")
+	v.SetConfigType("env")
+	v.SetConfigName(".env.local")
 
-	viper.AutomaticEnv()
+	v.AutomaticEnv()
 
 	variableNames := getTags("mapstructure", Config{})
 
-	for _, v := range variableNames {
-		if err := viper.BindEnv(v); err != nil {
-			return err
+	fs := newFlagSet(variableNames)
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	for _, name := range variableNames {
+		if err := v.BindEnv(name); err != nil {
+			return nil, err
 		}
 	}
 
-	if err := viper.ReadInConfig(); err != nil {
+	if err := bindFlags(v, fs, variableNames); err != nil {
+		return nil, err
+	}
+
+	if err := v.ReadInConfig(); err != nil {
 		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
-			return err
+			return nil, err
+		}
+	}
+
+	configFile, err := readConfigFile(v, fs)
+	if err != nil {
+		return nil, err
+	}
+	config.configFile = configFile
+
+	applyProfile(v)
+
+	problems := &ValidationError{}
+	readSecretFiles(v, fs, variableNames, problems)
+	decodeProblems, err := decode(v, config)
+	if err != nil {
+		return nil, err
+	}
+	problems.merge(decodeProblems)
+
+	return problems, nil
+}
+
+func decode(v *viper.Viper, config *Config) (*ValidationError, error) {
+	problems := &ValidationError{}
+
+	value := reflect.ValueOf(config).Elem()
+	for i := 0; i < value.NumField(); i++ {
+		key := value.Type().Field(i).Tag.Get("mapstructure")
+		if key == "" || !v.IsSet(key) {
+			continue
+		}
+
+		decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+			WeaklyTypedInput: true,
+			DecodeHook:       mapstructure.StringToTimeDurationHookFunc(),
+			Result:           value.Field(i).Addr().Interface(),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if err := decoder.Decode(v.Get(key)); err != nil {
+			problems.add(key, fmt.Sprintf("cannot parse %q as %s", v.GetString(key), value.Field(i).Kind()))
 		}
 	}
 
-	if err := viper.Unmarshal(&config); err != nil {
-		return err
+	return problems, nil
+}
+
+func (c *Config) Redacted() []Setting {
+	value := reflect.ValueOf(c).Elem()
+	settings := make([]Setting, 0, value.NumField())
+
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		key := field.Tag.Get("mapstructure")
+		if key == "" {
+			continue
+		}
+
+		formatted := fmt.Sprintf("%v", value.Field(i).Interface())
+		if field.Tag.Get("redact") == "true" && formatted != "" {
+			formatted = redactedValue
+		}
+
+		settings = append(settings, Setting{Key: key, Value: formatted})
+	}
+
+	return settings
+}
+
+func (c *Config) RouteTimeouts() (map[string]time.Duration, error) {
+	timeouts := make(map[string]time.Duration)
+
+	for _, entry := range splitList(c.HTTPRouteTimeouts) {
+		route, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("route timeout %q must be in the form \"METHOD /path=duration\"", entry)
+		}
+
+		method, path, ok := strings.Cut(strings.TrimSpace(route), " ")
+		if !ok || method == "" || !strings.HasPrefix(path, "/") {
+			return nil, fmt.Errorf("route %q must be in the form \"METHOD /path\"", route)
+		}
+
+		timeout, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil || timeout <= 0 {
+			return nil, fmt.Errorf("route %q has an invalid timeout %q", route, value)
+		}
+
+		timeouts[strings.ToUpper(method)+" "+path] = timeout
+	}
+
+	return timeouts, nil
+}
+
+// CubageFactors combines the default cubage factor with the overrides per
+// modal and per carrier.
+func (c *Config) CubageFactors() (freightmath.Factors, error) {
+	modal, err := freightmath.ParseFactors(c.FreightCubageFactorsModal)
+	if err != nil {
+		return freightmath.Factors{}, err
+	}
+
+	carrier, err := freightmath.ParseFactors(c.FreightCubageFactorsCarriers)
+	if err != nil {
+		return freightmath.Factors{}, err
+	}
+
+	return freightmath.Factors{Default: c.FreightCubageFactor, Modal: modal, Carrier: carrier}, nil
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
 	}
 
-	return nil
+	return items
 }
 
 func getTags(tagName string, obj any) []string {
@@ -0,0 +1,445 @@
+package config_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/config"
+)
+
+func validConfig() *config.Config {
+	return &config.Config{
+		Environment:                    config.EnvDevelopment,
+		AppPort:                        "8080",
+		ShutdownGracePeriod:            30 * time.Second,
+		LoggingLevel:                   "INFO",
+		StorageBackend:                 config.StoragePostgres,
+		DatabaseHost:                   "localhost",
+		DatabasePort:                   "5432",
+		DatabaseUser:                   "postgres",
+		DatabasePassword:               "postgres",
+		DatabaseName:                   "desafio_frete_rapido",
+		FastDeliveryAPIBaseURL:         "https://sp.freterapido.com/api/v3",
+		FastDeliveryAPITimeout:         10 * time.Second,
+		HTTPBodyLimit:                  1024 * 1024,
+		HTTPRequestTimeout:             15 * time.Second,
+		HTTPImportTimeout:              5 * time.Minute,
+		RateLimitRequestsPerMinute:     60,
+		RateLimitBurst:                 20,
+		FastDeliveryAPIToken:           "1d52a9b6b78cf07b08586152459a5c90",
+		FastDeliveryAPIPlatformCode:    "5AKVkHqCn",
+		FastDeliveryAPISenderCNPJ:      "25438296000158",
+		FastDeliveryAPIZipCode:         29161376,
+		FastDeliveryAPIBreakerFailures: 5,
+		FastDeliveryAPIBreakerCooldown: 30 * time.Second,
+		FastDeliveryAPIMaxConcurrency:  8,
+		BatchWorkers:                   4,
+		BatchMaxItems:                  1000,
+		BatchPollInterval:              time.Second,
+		WebhookWorkers:                 2,
+		WebhookMaxAttempts:             8,
+		WebhookTimeout:                 10 * time.Second,
+		RouteWatchInterval:             6 * time.Hour,
+		RouteSchedulerInterval:         30 * time.Second,
+		FreightCubageFactor:            300,
+		DeliveryTimezone:               "America/Sao_Paulo",
+		DeliveryDispatchCutoff:         "14:00",
+		HealthUpstreamProbeInterval:    time.Minute,
+	}
+}
+
+func TestValidate_ValidConfig(t *testing.T) {
+	if err := validConfig().Validate(); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+}
+
+func TestValidate_AggregatesProblems(t *testing.T) {
+	cfg := validConfig()
+	cfg.AppPort = "70000"
+	cfg.FastDeliveryAPIBaseURL = "https://baseurl.com/api/v3"
+	cfg.FastDeliveryAPIToken = "short"
+	cfg.FastDeliveryAPISenderCNPJ = "12.345.678/0001-90"
+	cfg.FastDeliveryAPIZipCode = 0
+
+	err := cfg.Validate()
+
+	var validationErr *config.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("Expected *config.ValidationError, got: %v", err)
+	}
+
+	expectedKeys := []string{
+		"APP_PORT",
+		"FASTDELIVERY_API_BASE_URL",
+		"FASTDELIVERY_API_TOKEN",
+		"FASTDELIVERY_API_SENDER_CNPJ",
+		"FASTDELIVERY_API_ZIP_CODE",
+	}
+
+	if len(validationErr.Problems) != len(expectedKeys) {
+		t.Fatalf("Expected %d problems, got: %d (%v)", len(expectedKeys), len(validationErr.Problems), err)
+	}
+
+	for i, key := range expectedKeys {
+		if validationErr.Problems[i].Key != key {
+			t.Errorf("Expected problem %d for %s, got: %s", i, key, validationErr.Problems[i].Key)
+		}
+	}
+
+	// O token nunca deve aparecer na mensagem de erro
+	if strings.Contains(err.Error(), "short") {
+		t.Errorf("Expected token to be redacted from error, got: %s", err.Error())
+	}
+}
+
+func TestValidate_ProductionRefusesUnsafeSettings(t *testing.T) {
+	cfg := validConfig()
+	cfg.Environment = config.EnvProduction
+	cfg.AuthEnabled = true
+	cfg.LoggingLevel = "DEBUG"
+	cfg.HTTPCorsAllowedOrigins = "https://loja.example.com.br, *"
+
+	err := cfg.Validate()
+
+	var validationErr *config.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("Expected *config.ValidationError, got: %v", err)
+	}
+
+	if len(validationErr.Problems) != 2 {
+		t.Fatalf("Expected 2 problems, got: %v", err)
+	}
+
+	if validationErr.Problems[0].Key != "HTTP_CORS_ALLOWED_ORIGINS" || validationErr.Problems[1].Key != "LOGGING_LEVEL" {
+		t.Errorf("Expected CORS and logging problems, got: %v", err)
+	}
+}
+
+func TestValidate_StorageBackend(t *testing.T) {
+	// Sem banco de dados configurado, o backend em memória é válido
+	cfg := validConfig()
+	cfg.StorageBackend = config.StorageMemory
+	cfg.DatabaseHost = ""
+	cfg.DatabasePassword = ""
+
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	// Autenticação depende dos tenants no Postgres
+	cfg.AuthEnabled = true
+	err := cfg.Validate()
+
+	var validationErr *config.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("Expected *config.ValidationError, got: %v", err)
+	}
+
+	if len(validationErr.Problems) != 1 || validationErr.Problems[0].Key != "AUTH_ENABLED" {
+		t.Errorf("Expected an AUTH_ENABLED problem, got: %v", err)
+	}
+
+	// O backend postgres exige as variáveis do banco
+	cfg = validConfig()
+	cfg.DatabaseHost = ""
+	cfg.DatabasePassword = ""
+
+	err = cfg.Validate()
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("Expected *config.ValidationError, got: %v", err)
+	}
+
+	if len(validationErr.Problems) != 2 || validationErr.Problems[0].Key != "DATABASE_HOST" || validationErr.Problems[1].Key != "DATABASE_PASSWORD" {
+		t.Errorf("Expected DATABASE_HOST and DATABASE_PASSWORD problems, got: %v", err)
+	}
+
+	// O backend sqlite exige apenas o caminho do arquivo
+	cfg = validConfig()
+	cfg.StorageBackend = config.StorageSQLite
+	cfg.DatabaseHost = ""
+
+	err = cfg.Validate()
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("Expected *config.ValidationError, got: %v", err)
+	}
+
+	if len(validationErr.Problems) != 1 || validationErr.Problems[0].Key != "SQLITE_PATH" {
+		t.Errorf("Expected a SQLITE_PATH problem, got: %v", err)
+	}
+
+	cfg.SQLitePath = "data/frete_rapido.db"
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected no error, got: %v", err)
+	}
+
+	cfg = validConfig()
+	cfg.StorageBackend = "mongodb"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for unknown storage backend, got: nil")
+	}
+}
+
+func TestNew_ProfileDefaults(t *testing.T) {
+	t.Setenv("GO_ENV", config.EnvProduction)
+
+	cfg, _ := config.New(nil)
+
+	if !cfg.LoggingJSONFormat {
+		t.Error("Expected JSON logs by default in production")
+	}
+
+	if cfg.LoggingLevel != "INFO" {
+		t.Errorf("Expected INFO logs by default in production, got: %s", cfg.LoggingLevel)
+	}
+
+	if cfg.HTTPRecoverStackTrace {
+		t.Error("Expected stack traces disabled by default in production")
+	}
+
+	if cfg.HTTPCorsAllowedOrigins != "" {
+		t.Errorf("Expected no CORS origins by default in production, got: %s", cfg.HTTPCorsAllowedOrigins)
+	}
+}
+
+func TestValidate_HTTPSettings(t *testing.T) {
+	cfg := validConfig()
+	cfg.HTTPCorsAllowedOrigins = "https://loja.com.br, https://*.loja.com.br, loja.com.br"
+	cfg.HTTPRouteTimeouts = "POST /v1/quote=20s, GET /v1/metrics"
+
+	err := cfg.Validate()
+
+	var validationErr *config.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("Expected *config.ValidationError, got: %v", err)
+	}
+
+	if len(validationErr.Problems) != 2 {
+		t.Fatalf("Expected 2 problems, got: %v", err)
+	}
+}
+
+func TestValidate_DeliverySettings(t *testing.T) {
+	cfg := validConfig()
+	cfg.DeliveryTimezone = "America/Recife_Velho"
+	cfg.DeliveryDispatchCutoff = "2pm"
+
+	err := cfg.Validate()
+
+	var validationErr *config.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("Expected *config.ValidationError, got: %v", err)
+	}
+
+	if len(validationErr.Problems) != 2 {
+		t.Fatalf("Expected 2 problems, got: %v", err)
+	}
+
+	if validationErr.Problems[0].Key != "DELIVERY_TIMEZONE" || validationErr.Problems[1].Key != "DELIVERY_DISPATCH_CUTOFF" {
+		t.Errorf("Expected timezone and cut-off problems, got: %v", err)
+	}
+}
+
+func TestValidate_ZipCode(t *testing.T) {
+	tests := []struct {
+		name    string
+		zipCode int
+		wantErr bool
+	}{
+		{name: "menor CEP válido", zipCode: 1000000},
+		{name: "maior CEP válido", zipCode: 99999999},
+		{name: "CEP do remetente", zipCode: 29161376},
+		{name: "zero", zipCode: 0, wantErr: true},
+		{name: "um dígito", zipCode: 1, wantErr: true},
+		{name: "cinco dígitos", zipCode: 29161, wantErr: true},
+		{name: "abaixo de 01000000", zipCode: 999999, wantErr: true},
+		{name: "nove dígitos", zipCode: 100000000, wantErr: true},
+		{name: "negativo", zipCode: -29161376, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig()
+			cfg.FastDeliveryAPIZipCode = tt.zipCode
+
+			err := cfg.Validate()
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("Expected error %v, got: %v", tt.wantErr, err)
+			}
+
+			var validationErr *config.ValidationError
+			if tt.wantErr && (!errors.As(err, &validationErr) || validationErr.Problems[0].Key != "FASTDELIVERY_API_ZIP_CODE") {
+				t.Errorf("Expected a FASTDELIVERY_API_ZIP_CODE problem, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidate_RouteIntervals(t *testing.T) {
+	tests := []struct {
+		name      string
+		watch     time.Duration
+		scheduler time.Duration
+		wantKey   string
+	}{
+		{name: "padrões", watch: 6 * time.Hour, scheduler: 30 * time.Second},
+		{name: "limites", watch: config.MinRouteInterval, scheduler: config.MinRouteInterval},
+		{name: "rota abaixo do mínimo", watch: 5 * time.Minute, scheduler: 30 * time.Second, wantKey: "ROUTE_WATCH_INTERVAL"},
+		{name: "agendador acima do mínimo", watch: 6 * time.Hour, scheduler: time.Hour, wantKey: "ROUTE_SCHEDULER_INTERVAL"},
+		{name: "agendador zerado", watch: 6 * time.Hour, scheduler: 0, wantKey: "ROUTE_SCHEDULER_INTERVAL"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig()
+			cfg.RouteWatchInterval = tt.watch
+			cfg.RouteSchedulerInterval = tt.scheduler
+
+			err := cfg.Validate()
+			if tt.wantKey == "" {
+				if err != nil {
+					t.Fatalf("Expected no error, got: %v", err)
+				}
+				return
+			}
+
+			var validationErr *config.ValidationError
+			if !errors.As(err, &validationErr) || len(validationErr.Problems) != 1 || validationErr.Problems[0].Key != tt.wantKey {
+				t.Errorf("Expected a single %s problem, got: %v", tt.wantKey, err)
+			}
+		})
+	}
+}
+
+func TestRouteTimeouts(t *testing.T) {
+	cfg := validConfig()
+	cfg.HTTPRouteTimeouts = "post /v1/quote=20s, GET /v1/metrics=2s"
+
+	timeouts, err := cfg.RouteTimeouts()
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if timeouts["POST /v1/quote"] != 20*time.Second || timeouts["GET /v1/metrics"] != 2*time.Second {
+		t.Errorf("Unexpected route timeouts: %v", timeouts)
+	}
+}
+
+func TestCubageFactors(t *testing.T) {
+	cfg := validConfig()
+	cfg.FreightCubageFactorsModal = "Aéreo=166.67"
+	cfg.FreightCubageFactorsCarriers = "JADLOG=200"
+
+	factors, err := cfg.CubageFactors()
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if factors.For("JADLOG", "Aéreo") != 200 || factors.For("LATAM", "Aéreo") != 166.67 || factors.For("CORREIOS", "") != 300 {
+		t.Errorf("Unexpected cubage factors: %+v", factors)
+	}
+
+	cfg.FreightCubageFactorsModal = "Aéreo"
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "FREIGHT_CUBAGE_FACTORS_BY_MODAL") {
+		t.Errorf("Expected FREIGHT_CUBAGE_FACTORS_BY_MODAL to be rejected, got: %v", err)
+	}
+}
+
+func TestRedacted_HidesSecrets(t *testing.T) {
+	cfg := validConfig()
+
+	for _, s := range cfg.Redacted() {
+		switch s.Key {
+		case "DATABASE_PASSWORD", "FASTDELIVERY_API_TOKEN":
+			if s.Value != "********" {
+				t.Errorf("Expected %s to be redacted, got: %s", s.Key, s.Value)
+			}
+		case "APP_PORT":
+			if s.Value != "8080" {
+				t.Errorf("Expected APP_PORT 8080, got: %s", s.Value)
+			}
+		}
+	}
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write %s: %v", name, err)
+	}
+
+	return path
+}
+
+func TestNew_SourcePrecedence(t *testing.T) {
+	configFile := writeFile(t, "config.yaml", `
+app_port: "7000"
+logging_level: WARNING
+database_host: db-from-file
+database_port: "5432"
+database_user: postgres
+database_name: desafio_frete_rapido
+fastdelivery_api_base_url: https://sp.freterapido.com/api/v3
+fastdelivery_api_platform_code: 5AKVkHqCn
+fastdelivery_api_sender_cnpj: "25438296000158"
+fastdelivery_api_zip_code: 29161376
+`)
+	tokenFile := writeFile(t, "token", "1d52a9b6b78cf07b08586152459a5c90\n")
+	passwordFile := writeFile(t, "password", "secret-from-file")
+
+	t.Setenv("DATABASE_HOST", "db-from-env")
+	t.Setenv("LOGGING_LEVEL", "ERROR")
+	t.Setenv("FASTDELIVERY_API_TOKEN_FILE", tokenFile)
+	t.Setenv("DATABASE_PASSWORD_FILE", passwordFile)
+
+	cfg, err := config.New([]string{"--config", configFile, "--logging-level", "DEBUG"})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	// Arquivo de configuração
+	if cfg.AppPort != "7000" {
+		t.Errorf("Expected APP_PORT from file 7000, got: %s", cfg.AppPort)
+	}
+
+	// Variável de ambiente sobrescreve o arquivo
+	if cfg.DatabaseHost != "db-from-env" {
+		t.Errorf("Expected DATABASE_HOST from env, got: %s", cfg.DatabaseHost)
+	}
+
+	// Flag sobrescreve a variável de ambiente
+	if cfg.LoggingLevel != "DEBUG" {
+		t.Errorf("Expected LOGGING_LEVEL from flag DEBUG, got: %s", cfg.LoggingLevel)
+	}
+
+	// Segredos lidos de *_FILE
+	if cfg.FastDeliveryAPIToken != "1d52a9b6b78cf07b08586152459a5c90" {
+		t.Errorf("Expected token from FASTDELIVERY_API_TOKEN_FILE, got: %q", cfg.FastDeliveryAPIToken)
+	}
+
+	if cfg.DatabasePassword != "secret-from-file" {
+		t.Errorf("Expected password from DATABASE_PASSWORD_FILE, got: %q", cfg.DatabasePassword)
+	}
+}
+
+func TestNew_SecretFileConflict(t *testing.T) {
+	t.Setenv("DATABASE_PASSWORD", "postgres")
+	t.Setenv("DATABASE_PASSWORD_FILE", writeFile(t, "password", "other"))
+
+	_, err := config.New(nil)
+
+	var validationErr *config.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("Expected *config.ValidationError, got: %v", err)
+	}
+
+	if !strings.Contains(err.Error(), "both DATABASE_PASSWORD and DATABASE_PASSWORD_FILE are set") {
+		t.Errorf("Expected conflict problem, got: %v", err)
+	}
+}
@@ -0,0 +1,116 @@
+package config
+
+import (
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/freightmath"
+	"github.com/spf13/viper"
+)
+
+const (
+	EnvDevelopment = "development"
+	EnvTest        = "test"
+	EnvProduction  = "production"
+)
+
+const freteRapidoBaseURL = "https://sp.freterapido.com/api/v3"
+
+var profiles = map[string]map[string]any{
+	EnvDevelopment: {
+		"LOGGING_JSON_FORMAT":       false,
+		"LOGGING_LEVEL":             "DEBUG",
+		"AUTH_ENABLED":              false,
+		"HTTP_RECOVER_STACK_TRACE":  true,
+		"HTTP_CORS_ALLOWED_ORIGINS": "*",
+		"HTTP_CORS_ALLOWED_METHODS": "GET,POST",
+		"HTTP_CORS_ALLOWED_HEADERS": "*",
+		"FASTDELIVERY_API_BASE_URL": freteRapidoBaseURL,
+		"HTTP_HSTS_MAX_AGE":         0,
+	},
+	EnvTest: {
+		"LOGGING_JSON_FORMAT":       false,
+		"LOGGING_LEVEL":             "WARNING",
+		"AUTH_ENABLED":              false,
+		"HTTP_RECOVER_STACK_TRACE":  true,
+		"HTTP_CORS_ALLOWED_ORIGINS": "*",
+		"HTTP_CORS_ALLOWED_METHODS": "GET,POST",
+		"HTTP_CORS_ALLOWED_HEADERS": "*",
+		"FASTDELIVERY_API_BASE_URL": "http://localhost:8081/api/v3",
+		"HTTP_HSTS_MAX_AGE":         0,
+	},
+	EnvProduction: {
+		"LOGGING_JSON_FORMAT":       true,
+		"LOGGING_LEVEL":             "INFO",
+		"AUTH_ENABLED":              true,
+		"HTTP_RECOVER_STACK_TRACE":  false,
+		"HTTP_CORS_ALLOWED_ORIGINS": "",
+		"HTTP_CORS_ALLOWED_METHODS": "GET,POST",
+		"HTTP_CORS_ALLOWED_HEADERS": "Content-Type,Authorization",
+		"FASTDELIVERY_API_BASE_URL": freteRapidoBaseURL,
+		"HTTP_HSTS_MAX_AGE":         31536000,
+	},
+}
+
+func applyProfile(v *viper.Viper) {
+	v.SetDefault("GO_ENV", EnvDevelopment)
+	v.SetDefault("STORAGE_BACKEND", StoragePostgres)
+	v.SetDefault("SQLITE_PATH", "data/frete_rapido.db")
+	v.SetDefault("SHUTDOWN_GRACE_PERIOD", 30*time.Second)
+	v.SetDefault("FASTDELIVERY_API_TIMEOUT", 10*time.Second)
+	v.SetDefault("FASTDELIVERY_API_BREAKER_FAILURES", 5)
+	v.SetDefault("FASTDELIVERY_API_BREAKER_COOLDOWN", 30*time.Second)
+	v.SetDefault("FASTDELIVERY_API_MAX_CONCURRENCY", 8)
+	v.SetDefault("BATCH_WORKERS", 4)
+	v.SetDefault("BATCH_MAX_ITEMS", 1000)
+	v.SetDefault("BATCH_POLL_INTERVAL", time.Second)
+	v.SetDefault("WEBHOOK_WORKERS", 2)
+	v.SetDefault("WEBHOOK_MAX_ATTEMPTS", 8)
+	v.SetDefault("WEBHOOK_TIMEOUT", 10*time.Second)
+	v.SetDefault("ROUTE_WATCH_INTERVAL", 6*time.Hour)
+	v.SetDefault("ROUTE_SCHEDULER_INTERVAL", 30*time.Second)
+	v.SetDefault("FREIGHT_CUBAGE_FACTOR", freightmath.DefaultCubageFactor)
+	v.SetDefault("DELIVERY_TIMEZONE", "America/Sao_Paulo")
+	v.SetDefault("DELIVERY_DISPATCH_CUTOFF", "14:00")
+	v.SetDefault("HEALTH_UPSTREAM_PROBE_INTERVAL", time.Minute)
+	v.SetDefault("HTTP_BODY_LIMIT", 1024*1024)
+	v.SetDefault("HTTP_REQUEST_TIMEOUT", 15*time.Second)
+	v.SetDefault("HTTP_IMPORT_TIMEOUT", 5*time.Minute)
+	v.SetDefault("HTTP_PROXY_HEADER", "X-Forwarded-For")
+	v.SetDefault("RATE_LIMIT_REQUESTS_PER_MINUTE", 60)
+	v.SetDefault("RATE_LIMIT_BURST", 20)
+
+	for key, value := range profiles[v.GetString("GO_ENV")] {
+		v.SetDefault(key, value)
+	}
+}
+
+func (c *Config) IsProduction() bool {
+	return c.Environment == EnvProduction
+}
+
+func (c *Config) validateProfile(problems *ValidationError) {
+	if !c.IsProduction() {
+		return
+	}
+
+	for _, origin := range strings.Split(c.HTTPCorsAllowedOrigins, ",") {
+		if strings.TrimSpace(origin) == "*" {
+			problems.add("HTTP_CORS_ALLOWED_ORIGINS", "wildcard origins are not allowed in production")
+			break
+		}
+	}
+
+	if !c.AuthEnabled {
+		problems.add("AUTH_ENABLED", "api key authentication cannot be disabled in production")
+	}
+
+	if c.LoggingLevel == "DEBUG" {
+		problems.add("LOGGING_LEVEL", "DEBUG logs are not allowed in production")
+	}
+
+	if u, err := url.Parse(c.FastDeliveryAPIBaseURL); err == nil && u.Scheme == "http" {
+		problems.add("FASTDELIVERY_API_BASE_URL", "plain http is not allowed in production")
+	}
+}
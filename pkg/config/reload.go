@@ -0,0 +1,318 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+const (
+	reloadDebounce   = 500 * time.Millisecond
+	reloadHistoryCap = 50
+)
+
+type ReloadEvent struct {
+	At      time.Time `json:"at"`
+	File    string    `json:"file"`
+	Applied []string  `json:"applied"`
+	Ignored []string  `json:"ignored"`
+	Error   string    `json:"error,omitempty"`
+}
+
+// Watcher reloads the config file when it changes. Settings tagged
+// watch:"file" name files that are watched too: when one of them changes,
+// subscribers are notified with the current configuration so they can read
+// it again.
+type Watcher struct {
+	args    []string
+	current atomic.Pointer[Config]
+	// checksums is only used by Run, after NewWatcher
+	checksums map[string][]byte
+
+	mu          sync.Mutex
+	subscribers []func(*Config)
+	history     []ReloadEvent
+}
+
+func NewWatcher(cfg *Config, args []string) *Watcher {
+	w := &Watcher{args: args, checksums: make(map[string][]byte)}
+	w.current.Store(cfg)
+
+	for _, path := range w.paths() {
+		w.checksums[path], _ = fileChecksum(path)
+	}
+
+	return w
+}
+
+func (w *Watcher) Current() *Config {
+	return w.current.Load()
+}
+
+func (w *Watcher) Subscribe(fn func(*Config)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.subscribers = append(w.subscribers, fn)
+}
+
+func (w *Watcher) History() []ReloadEvent {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	history := make([]ReloadEvent, len(w.history))
+	copy(history, w.history)
+
+	return history
+}
+
+func (w *Watcher) Run(ctx context.Context) error {
+	if len(w.paths()) == 0 {
+		slog.Info("no config file given, hot reload disabled")
+		return nil
+	}
+
+	fsWatcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create config watcher: %w", err)
+	}
+	defer fsWatcher.Close()
+
+	// Directories are watched instead of files so that editors that replace
+	// the file and Kubernetes ConfigMap symlink swaps are noticed.
+	dirs := make(map[string]bool)
+	if err := w.watchDirs(fsWatcher, dirs); err != nil {
+		return err
+	}
+
+	slog.Info("watching config files for changes", "files", w.paths())
+
+	var debounce <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-fsWatcher.Errors:
+			slog.Error("config watcher error", "error", err)
+		case <-fsWatcher.Events:
+			debounce = time.After(reloadDebounce)
+		case <-debounce:
+			debounce = nil
+
+			w.checkFiles()
+
+			// A reload may point a watch:"file" setting to another directory.
+			if err := w.watchDirs(fsWatcher, dirs); err != nil {
+				slog.Error("config watcher error", "error", err)
+			}
+		}
+	}
+}
+
+func (w *Watcher) Reload() ReloadEvent {
+	current := w.Current()
+	event := ReloadEvent{At: time.Now(), File: current.configFile}
+
+	next, err := New(w.args)
+	if err != nil {
+		event.Error = err.Error()
+		slog.Error("config reload rejected, keeping current configuration", "file", event.File, "error", err)
+		w.record(event)
+
+		return event
+	}
+
+	event.Applied, event.Ignored = mergeHotSettings(current, next)
+
+	for _, key := range event.Ignored {
+		slog.Warn("config change requires a restart and was ignored", "key", key)
+	}
+
+	if len(event.Applied) > 0 {
+		w.current.Store(next)
+		w.notify(next)
+	}
+
+	slog.Info("config reloaded", "file", event.File, "applied", event.Applied, "ignored", event.Ignored)
+	w.record(event)
+
+	return event
+}
+
+// checkFiles reloads the config file when it changed. Changed watch:"file"
+// files notify the subscribers, unless the reload already did.
+func (w *Watcher) checkFiles() {
+	current := w.Current()
+
+	var changed []watchedFile
+	for _, file := range watchedFiles(current) {
+		if w.changed(file.path) {
+			changed = append(changed, file)
+		}
+	}
+
+	if w.changed(current.configFile) {
+		if event := w.Reload(); len(event.Applied) > 0 {
+			// Subscribers read every file again, from the new paths.
+			for _, file := range watchedFiles(w.Current()) {
+				w.changed(file.path)
+			}
+			return
+		}
+	}
+
+	for _, file := range changed {
+		event := ReloadEvent{At: time.Now(), File: file.path, Applied: []string{file.key}}
+
+		slog.Info("watched file changed", "file", file.path, "key", file.key)
+		w.notify(current)
+		w.record(event)
+	}
+}
+
+// changed reports whether the content of path differs from the last time it
+// was checked. Files that cannot be read are reported unchanged.
+func (w *Watcher) changed(path string) bool {
+	if path == "" {
+		return false
+	}
+
+	checksum, err := fileChecksum(path)
+	if err != nil || bytes.Equal(checksum, w.checksums[path]) {
+		return false
+	}
+	w.checksums[path] = checksum
+
+	return true
+}
+
+// paths returns the config file and every watch:"file" file.
+func (w *Watcher) paths() []string {
+	current := w.Current()
+
+	var paths []string
+	if current.configFile != "" {
+		paths = append(paths, current.configFile)
+	}
+
+	for _, file := range watchedFiles(current) {
+		paths = append(paths, file.path)
+	}
+
+	return paths
+}
+
+func (w *Watcher) watchDirs(fsWatcher *fsnotify.Watcher, dirs map[string]bool) error {
+	for _, path := range w.paths() {
+		dir := filepath.Dir(path)
+		if dirs[dir] {
+			continue
+		}
+
+		if err := fsWatcher.Add(dir); err != nil {
+			return fmt.Errorf("failed to watch config file %q: %w", path, err)
+		}
+		dirs[dir] = true
+	}
+
+	return nil
+}
+
+func (w *Watcher) notify(cfg *Config) {
+	w.mu.Lock()
+	subscribers := make([]func(*Config), len(w.subscribers))
+	copy(subscribers, w.subscribers)
+	w.mu.Unlock()
+
+	for _, fn := range subscribers {
+		fn(cfg)
+	}
+}
+
+func (w *Watcher) record(event ReloadEvent) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.history = append(w.history, event)
+	if len(w.history) > reloadHistoryCap {
+		w.history = w.history[len(w.history)-reloadHistoryCap:]
+	}
+}
+
+// mergeHotSettings keeps every restart-only setting of next equal to current
+// and reports which hot settings changed and which changes were ignored.
+func mergeHotSettings(current, next *Config) (applied, ignored []string) {
+	currentValue := reflect.ValueOf(current).Elem()
+	nextValue := reflect.ValueOf(next).Elem()
+
+	for i := 0; i < currentValue.NumField(); i++ {
+		field := currentValue.Type().Field(i)
+		key := field.Tag.Get("mapstructure")
+		if key == "" {
+			continue
+		}
+
+		if reflect.DeepEqual(currentValue.Field(i).Interface(), nextValue.Field(i).Interface()) {
+			continue
+		}
+
+		if field.Tag.Get("reload") == "hot" {
+			applied = append(applied, key)
+			continue
+		}
+
+		ignored = append(ignored, key)
+		nextValue.Field(i).Set(currentValue.Field(i))
+	}
+
+	return applied, ignored
+}
+
+type watchedFile struct {
+	key  string
+	path string
+}
+
+// watchedFiles lists the files named by the watch:"file" settings of cfg.
+func watchedFiles(cfg *Config) []watchedFile {
+	value := reflect.ValueOf(cfg).Elem()
+
+	var files []watchedFile
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		if field.Tag.Get("watch") != "file" {
+			continue
+		}
+
+		if path := value.Field(i).String(); path != "" {
+			files = append(files, watchedFile{key: field.Tag.Get("mapstructure"), path: path})
+		}
+	}
+
+	return files
+}
+
+func fileChecksum(path string) ([]byte, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(content)
+
+	return sum[:], nil
+}
@@ -0,0 +1,171 @@
+package config_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/config"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/money"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/pricing"
+)
+
+const reloadConfigYAML = `
+app_port: "8080"
+database_host: %s
+database_port: "5432"
+database_user: postgres
+database_password: postgres
+database_name: desafio_frete_rapido
+logging_level: %s
+fastdelivery_api_timeout: %s
+fastdelivery_api_token: 1d52a9b6b78cf07b08586152459a5c90
+fastdelivery_api_platform_code: 5AKVkHqCn
+fastdelivery_api_sender_cnpj: "25438296000158"
+fastdelivery_api_zip_code: 29161376
+`
+
+func TestWatcher_ReloadAppliesOnlyHotSettings(t *testing.T) {
+	path := writeFile(t, "config.yaml", fmt.Sprintf(reloadConfigYAML, "localhost", "INFO", "10s"))
+	args := []string{"--config", path}
+
+	cfg, err := config.New(args)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	watcher := config.NewWatcher(cfg, args)
+
+	var notified *config.Config
+	watcher.Subscribe(func(c *config.Config) {
+		notified = c
+	})
+
+	if err := os.WriteFile(path, []byte(fmt.Sprintf(reloadConfigYAML, "other-host", "ERROR", "3s")), 0o600); err != nil {
+		t.Fatalf("failed to rewrite config: %v", err)
+	}
+
+	event := watcher.Reload()
+
+	if !slices.Equal(event.Applied, []string{"LOGGING_LEVEL", "FASTDELIVERY_API_TIMEOUT"}) {
+		t.Errorf("Expected applied [LOGGING_LEVEL FASTDELIVERY_API_TIMEOUT], got: %v", event.Applied)
+	}
+
+	if !slices.Equal(event.Ignored, []string{"DATABASE_HOST"}) {
+		t.Errorf("Expected ignored [DATABASE_HOST], got: %v", event.Ignored)
+	}
+
+	current := watcher.Current()
+	if current.LoggingLevel != "ERROR" || current.FastDeliveryAPITimeout != 3*time.Second {
+		t.Errorf("Expected hot settings to be applied, got: %s %s", current.LoggingLevel, current.FastDeliveryAPITimeout)
+	}
+
+	// Configurações que exigem reinício permanecem inalteradas
+	if current.DatabaseHost != "localhost" {
+		t.Errorf("Expected DATABASE_HOST to stay localhost, got: %s", current.DatabaseHost)
+	}
+
+	if notified != current {
+		t.Error("Expected subscribers to be notified with the new configuration")
+	}
+
+	if len(watcher.History()) != 1 {
+		t.Errorf("Expected 1 reload recorded, got: %d", len(watcher.History()))
+	}
+}
+
+func TestWatcher_ReloadRejectsInvalidConfig(t *testing.T) {
+	path := writeFile(t, "config.yaml", fmt.Sprintf(reloadConfigYAML, "localhost", "INFO", "10s"))
+	args := []string{"--config", path}
+
+	cfg, err := config.New(args)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	watcher := config.NewWatcher(cfg, args)
+
+	if err := os.WriteFile(path, []byte(fmt.Sprintf(reloadConfigYAML, "localhost", "VERBOSE", "10s")), 0o600); err != nil {
+		t.Fatalf("failed to rewrite config: %v", err)
+	}
+
+	event := watcher.Reload()
+
+	if event.Error == "" {
+		t.Fatal("Expected reload error for invalid LOGGING_LEVEL")
+	}
+
+	if watcher.Current() != cfg {
+		t.Error("Expected current configuration to be kept after a rejected reload")
+	}
+}
+
+// Editar o arquivo de regras de preço, sem tocar no arquivo de configuração,
+// também recarrega as regras
+func TestWatcher_RunReloadsPricingRulesFile(t *testing.T) {
+	rulesFile := writeFile(t, "rules.json", `[{"name":"margem","then":{"type":"markup","percent":5}}]`)
+	path := filepath.Join(filepath.Dir(rulesFile), "config.yaml")
+	configYAML := fmt.Sprintf(reloadConfigYAML, "localhost", "INFO", "10s") + "freight_pricing_rules_path: " + rulesFile + "\n"
+	if err := os.WriteFile(path, []byte(configYAML), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	args := []string{"--config", path}
+
+	cfg, err := config.New(args)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	watcher := config.NewWatcher(cfg, args)
+
+	prices := make(chan money.Money, 1)
+	watcher.Subscribe(func(c *config.Config) {
+		rules, err := pricing.Load(c.FreightPricingRulesPath)
+		if err != nil {
+			t.Errorf("Expected no error, got: %v", err)
+			return
+		}
+
+		prices <- rules.Apply(pricing.Offer{Carrier: "JADLOG", Price: money.FromCents(2000)}).Price
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- watcher.Run(ctx) }()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	// O arquivo é regravado, com intervalo maior que o debounce, até o watcher
+	// notar a mudança
+	timeout := time.After(10 * time.Second)
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case price := <-prices:
+			if price != money.FromCents(2200) {
+				t.Errorf("Expected the new rules to price 22.00, got: %s", price)
+			}
+
+			history := watcher.History()
+			if len(history) != 1 || history[0].File != rulesFile || !slices.Equal(history[0].Applied, []string{"FREIGHT_PRICING_RULES_PATH"}) {
+				t.Errorf("Expected the rules file change to be recorded, got: %+v", history)
+			}
+
+			return
+		case <-ticker.C:
+			if err := os.WriteFile(rulesFile, []byte(`[{"name":"margem","then":{"type":"markup","percent":10}}]`), 0o600); err != nil {
+				t.Fatalf("failed to rewrite rules: %v", err)
+			}
+		case <-timeout:
+			t.Fatal("Expected the rules file change to notify subscribers")
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+const (
+	configFileFlag = "config"
+	configFileEnv  = "CONFIG_FILE"
+	secretFileSufx = "_FILE"
+)
+
+// Sources are applied from the lowest to the highest precedence:
+// defaults, .env.local, the YAML/JSON config file, environment variables
+// (or their *_FILE counterparts) and finally command-line flags.
+func newFlagSet(keys []string) *pflag.FlagSet {
+	fs := pflag.NewFlagSet("desafio-frete-rapido", pflag.ContinueOnError)
+	fs.String(configFileFlag, "", fmt.Sprintf("path to a YAML or JSON config file (env %s)", configFileEnv))
+
+	for _, key := range keys {
+		fs.String(flagName(key), "", fmt.Sprintf("overrides %s", key))
+	}
+
+	return fs
+}
+
+func flagName(key string) string {
+	return strings.ReplaceAll(strings.ToLower(key), "_", "-")
+}
+
+func bindFlags(v *viper.Viper, fs *pflag.FlagSet, keys []string) error {
+	for _, key := range keys {
+		if err := v.BindPFlag(key, fs.Lookup(flagName(key))); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func readConfigFile(v *viper.Viper, fs *pflag.FlagSet) (string, error) {
+	path, _ := fs.GetString(configFileFlag)
+	if path == "" {
+		path = os.Getenv(configFileEnv)
+	}
+	if path == "" {
+		return "", nil
+	}
+
+	fileViper := viper.New()
+	fileViper.SetConfigFile(path)
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		fileViper.SetConfigType("yaml")
+	case ".json":
+		fileViper.SetConfigType("json")
+	default:
+		return "", fmt.Errorf("unsupported config file %q: expected .yaml, .yml or .json", path)
+	}
+
+	if err := fileViper.ReadInConfig(); err != nil {
+		return "", fmt.Errorf("failed to read config file %q: %w", path, err)
+	}
+
+	if err := v.MergeConfigMap(fileViper.AllSettings()); err != nil {
+		return "", fmt.Errorf("failed to merge config file %q: %w", path, err)
+	}
+
+	return path, nil
+}
+
+func readSecretFiles(v *viper.Viper, fs *pflag.FlagSet, keys []string, problems *ValidationError) {
+	for _, key := range keys {
+		path, ok := os.LookupEnv(key + secretFileSufx)
+		if !ok || path == "" {
+			continue
+		}
+
+		if _, set := os.LookupEnv(key); set {
+			problems.add(key, fmt.Sprintf("both %s and %s%s are set, use only one", key, key, secretFileSufx))
+			continue
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			problems.add(key, fmt.Sprintf("cannot read %s%s: %v", key, secretFileSufx, err))
+			continue
+		}
+
+		if fs.Changed(flagName(key)) {
+			continue
+		}
+
+		v.Set(key, strings.TrimRight(string(content), "\r\n"))
+	}
+}
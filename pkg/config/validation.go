@@ -0,0 +1,287 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/freightmath"
+)
+
+var placeholderHosts = []string{"baseurl.com", "example.com", "example.org", "localhost.localdomain"}
+
+type Problem struct {
+	Key     string
+	Message string
+}
+
+type ValidationError struct {
+	Problems []Problem
+}
+
+func (e *ValidationError) Error() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("invalid configuration (%d problems):", len(e.Problems)))
+	for _, p := range e.Problems {
+		sb.WriteString(fmt.Sprintf("\n  - %s: %s", p.Key, p.Message))
+	}
+
+	return sb.String()
+}
+
+func (e *ValidationError) add(key, message string) {
+	e.Problems = append(e.Problems, Problem{Key: key, Message: message})
+}
+
+func (e *ValidationError) merge(other *ValidationError) {
+	for _, p := range other.Problems {
+		if !e.has(p.Key) {
+			e.Problems = append(e.Problems, p)
+		}
+	}
+}
+
+func (e *ValidationError) has(key string) bool {
+	for _, p := range e.Problems {
+		if p.Key == key {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (e *ValidationError) errOrNil() error {
+	if len(e.Problems) == 0 {
+		return nil
+	}
+
+	return e
+}
+
+func (c *Config) Validate() error {
+	problems := &ValidationError{}
+
+	v := newValidator()
+	if err := v.Struct(c); err != nil {
+		var fieldErrors validator.ValidationErrors
+		if !errors.As(err, &fieldErrors) {
+			return err
+		}
+
+		for _, fe := range fieldErrors {
+			var value any = fe.Value()
+			if field, ok := reflect.TypeOf(c).Elem().FieldByName(fe.StructField()); ok && field.Tag.Get("redact") == "true" {
+				value = redactedValue
+			}
+
+			problems.add(fe.Field(), describe(fe, value))
+		}
+	}
+
+	c.validateStorage(problems)
+	c.validateRoutes(problems)
+	c.validateProfile(problems)
+
+	return problems.errOrNil()
+}
+
+// validateStorage requires the database settings only when they are used.
+// Tenants, batches, webhooks and watched routes need Postgres, so the other
+// backends cannot be combined with api key authentication.
+func (c *Config) validateStorage(problems *ValidationError) {
+	if c.StorageBackend == StorageSQLite && c.SQLitePath == "" {
+		problems.add("SQLITE_PATH", "is required with the sqlite storage backend")
+	}
+
+	if c.StorageBackend != StoragePostgres {
+		if c.AuthEnabled {
+			problems.add("AUTH_ENABLED", fmt.Sprintf("api key authentication is not available with the %s storage backend", c.StorageBackend))
+		}
+		return
+	}
+
+	required := []Setting{
+		{Key: "DATABASE_HOST", Value: c.DatabaseHost},
+		{Key: "DATABASE_PORT", Value: c.DatabasePort},
+		{Key: "DATABASE_USER", Value: c.DatabaseUser},
+		{Key: "DATABASE_PASSWORD", Value: c.DatabasePassword},
+		{Key: "DATABASE_NAME", Value: c.DatabaseName},
+	}
+	for _, setting := range required {
+		if setting.Value == "" {
+			problems.add(setting.Key, "is required with the postgres storage backend")
+		}
+	}
+}
+
+// validateRoutes keeps the route intervals consistent with MinRouteInterval.
+// A scheduler that ticks less often than the shortest route interval would
+// re-quote due routes late.
+func (c *Config) validateRoutes(problems *ValidationError) {
+	if !problems.has("ROUTE_WATCH_INTERVAL") && c.RouteWatchInterval < MinRouteInterval {
+		problems.add("ROUTE_WATCH_INTERVAL", fmt.Sprintf("must be at least %s", MinRouteInterval))
+	}
+
+	if !problems.has("ROUTE_SCHEDULER_INTERVAL") && c.RouteSchedulerInterval > MinRouteInterval {
+		problems.add("ROUTE_SCHEDULER_INTERVAL", fmt.Sprintf("must be at most %s", MinRouteInterval))
+	}
+}
+
+func newValidator() *validator.Validate {
+	v := validator.New()
+
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		return field.Tag.Get("mapstructure")
+	})
+
+	_ = v.RegisterValidation("tcpport", isTCPPort)
+	_ = v.RegisterValidation("cnpj", isCNPJ)
+	_ = v.RegisterValidation("cep", isCEP)
+	_ = v.RegisterValidation("noplaceholder", isNotPlaceholder)
+	_ = v.RegisterValidation("origins", isOriginList)
+	_ = v.RegisterValidation("routetimeouts", isRouteTimeoutList)
+	_ = v.RegisterValidation("cubagefactors", isCubageFactorList)
+
+	return v
+}
+
+func describe(fe validator.FieldError, value any) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "http_url":
+		return fmt.Sprintf("must be an absolute http(s) URL, got %q", value)
+	case "min":
+		return fmt.Sprintf("must be at least %s characters long", fe.Param())
+	case "len":
+		return fmt.Sprintf("must be exactly %s characters long", fe.Param())
+	case "gt":
+		return fmt.Sprintf("must be greater than %s, got %v", fe.Param(), value)
+	case "oneof":
+		return fmt.Sprintf("must be one of [%s], got %q", fe.Param(), value)
+	case "tcpport":
+		return fmt.Sprintf("must be a port number between 1 and 65535, got %q", value)
+	case "cnpj":
+		return "must be a CNPJ with exactly 14 digits"
+	case "cep":
+		return fmt.Sprintf("must be a CEP with 8 digits from 01000000 to 99999999, got %v", value)
+	case "origins":
+		return fmt.Sprintf("must be \"*\" or a comma-separated list of scheme://host origins, got %q", value)
+	case "routetimeouts":
+		return fmt.Sprintf("must be a comma-separated list of \"METHOD /path=duration\", got %q", value)
+	case "cubagefactors":
+		return fmt.Sprintf("must be a comma-separated list of \"name=factor\" with positive factors, got %q", value)
+	case "gte":
+		return fmt.Sprintf("must be greater than or equal to %s, got %v", fe.Param(), value)
+	case "timezone":
+		return fmt.Sprintf("must be an IANA time zone such as America/Sao_Paulo, got %q", value)
+	case "datetime":
+		return fmt.Sprintf("must be a time of day as HH:MM, got %q", value)
+	case "noplaceholder":
+		return fmt.Sprintf("still holds a placeholder value %q", value)
+	default:
+		return fmt.Sprintf("failed %q validation", fe.Tag())
+	}
+}
+
+func isTCPPort(fl validator.FieldLevel) bool {
+	port, err := strconv.Atoi(fl.Field().String())
+	if err != nil {
+		return false
+	}
+
+	return port >= 1 && port <= 65535
+}
+
+func isCNPJ(fl validator.FieldLevel) bool {
+	return isDigits(fl.Field().String(), 14)
+}
+
+// lowestCEP is 01000000, the first CEP assigned by the Correios. Integer
+// fields lose the leading zero, so anything below it would be a CEP with
+// fewer than 8 digits once zero-padded.
+const lowestCEP = 1000000
+
+func isCEP(fl validator.FieldLevel) bool {
+	field := fl.Field()
+	switch field.Kind() {
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		return field.Int() >= lowestCEP && field.Int() <= 99999999
+	case reflect.String:
+		return isDigits(field.String(), 8) && field.String() >= "01000000"
+	}
+
+	return false
+}
+
+func isNotPlaceholder(fl validator.FieldLevel) bool {
+	value := strings.ToLower(fl.Field().String())
+	if strings.HasPrefix(value, "your_") || strings.Contains(value, "changeme") {
+		return false
+	}
+
+	u, err := url.Parse(value)
+	if err != nil || u.Host == "" {
+		return true
+	}
+
+	for _, host := range placeholderHosts {
+		if u.Hostname() == host {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isOriginList(fl validator.FieldLevel) bool {
+	for _, origin := range splitList(fl.Field().String()) {
+		if origin == "*" {
+			continue
+		}
+
+		origin = strings.Replace(origin, "://*.", "://", 1)
+		u, err := url.Parse(origin)
+		if err != nil || u.Scheme == "" || u.Host == "" || strings.Contains(u.Host, "*") {
+			return false
+		}
+
+		if (u.Path != "" && u.Path != "/") || u.RawQuery != "" || u.Fragment != "" {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isRouteTimeoutList(fl validator.FieldLevel) bool {
+	c := &Config{HTTPRouteTimeouts: fl.Field().String()}
+	_, err := c.RouteTimeouts()
+
+	return err == nil
+}
+
+func isCubageFactorList(fl validator.FieldLevel) bool {
+	_, err := freightmath.ParseFactors(fl.Field().String())
+
+	return err == nil
+}
+
+func isDigits(value string, length int) bool {
+	if len(value) != length {
+		return false
+	}
+
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,77 @@
+// Package databasetest gives tests an isolated Postgres schema. Tests using
+// it are skipped unless TEST_DATABASE_URL points to a database where they may
+// create and drop schemas.
+package databasetest
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/database"
+)
+
+const URLVariable = "TEST_DATABASE_URL"
+
+// Postgres connects to a new empty schema, dropped when the test ends.
+func Postgres(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+
+	dsn := os.Getenv(URLVariable)
+	if dsn == "" {
+		t.Skipf("%s is not set", URLVariable)
+	}
+
+	ctx := context.Background()
+
+	admin, err := pgx.Connect(ctx, dsn)
+	if err != nil {
+		t.Fatalf("Expected no error connecting to %s, got: %v", URLVariable, err)
+	}
+
+	suffix := make([]byte, 6)
+	_, _ = rand.Read(suffix)
+	schema := pgx.Identifier{"test_" + hex.EncodeToString(suffix)}.Sanitize()
+
+	if _, err := admin.Exec(ctx, "CREATE SCHEMA "+schema); err != nil {
+		admin.Close(ctx)
+		t.Fatalf("Expected no error creating schema %s, got: %v", schema, err)
+	}
+
+	t.Cleanup(func() {
+		if _, err := admin.Exec(ctx, "DROP SCHEMA "+schema+" CASCADE"); err != nil {
+			t.Errorf("Expected no error dropping schema %s, got: %v", schema, err)
+		}
+		admin.Close(ctx)
+	})
+
+	config, err := pgxpool.ParseConfig(dsn)
+	if err != nil {
+		t.Fatalf("Expected no error parsing %s, got: %v", URLVariable, err)
+	}
+	config.ConnConfig.RuntimeParams["search_path"] = schema
+
+	pool, err := pgxpool.NewWithConfig(ctx, config)
+	if err != nil {
+		t.Fatalf("Expected no error connecting to schema %s, got: %v", schema, err)
+	}
+	t.Cleanup(pool.Close)
+
+	return pool
+}
+
+// Migrated is Postgres with every migration applied.
+func Migrated(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+
+	pool := Postgres(t)
+	if err := database.Migrate(context.Background(), pool); err != nil {
+		t.Fatalf("Expected no error migrating, got: %v", err)
+	}
+
+	return pool
+}
@@ -0,0 +1,74 @@
+package database_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/database"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/database/databasetest"
+)
+
+// oldInitSchema é o schema criado pelo script de init do docker-compose,
+// antes de as migrações serem registradas em schema_migrations
+const oldInitSchema = `CREATE TABLE quotes (
+  id SERIAL PRIMARY KEY,
+  carrier_name VARCHAR(255) NOT NULL,
+  service VARCHAR(255) NOT NULL,
+  price DECIMAL(10, 2) NOT NULL,
+  deadline INT NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT now(),
+  updated_at TIMESTAMP NOT NULL DEFAULT now()
+);`
+
+func TestMigrate_UpgradesOldInitSchema(t *testing.T) {
+	ctx := context.Background()
+	pool := databasetest.Postgres(t)
+
+	if _, err := pool.Exec(ctx, oldInitSchema); err != nil {
+		t.Fatalf("Expected no error creating the old schema, got: %v", err)
+	}
+
+	if _, err := pool.Exec(ctx, "INSERT INTO quotes (carrier_name, service, price, deadline) VALUES ('CORREIOS', 'PAC', 15.50, 5)"); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if err := database.Migrate(ctx, pool); err != nil {
+		t.Fatalf("Expected the old schema to be migrated, got: %v", err)
+	}
+
+	version, err := database.SchemaVersion(ctx, pool)
+	if err != nil || version != database.LatestSchemaVersion() {
+		t.Errorf("Expected version %d, got: %d (%v)", database.LatestSchemaVersion(), version, err)
+	}
+
+	// As cotações antigas continuam lá, com as colunas das migrações seguintes
+	var carrier string
+	var estimated bool
+	if err := pool.QueryRow(ctx, "SELECT carrier_name, estimated FROM quotes").Scan(&carrier, &estimated); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if carrier != "CORREIOS" || estimated {
+		t.Errorf("Expected the old quote to be kept, got: %s (estimated %v)", carrier, estimated)
+	}
+}
+
+func TestMigrate_IsIdempotent(t *testing.T) {
+	ctx := context.Background()
+	pool := databasetest.Postgres(t)
+
+	for range 2 {
+		if err := database.Migrate(ctx, pool); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+	}
+
+	var applied int
+	if err := pool.QueryRow(ctx, "SELECT COUNT(*) FROM schema_migrations").Scan(&applied); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if applied != database.LatestSchemaVersion() {
+		t.Errorf("Expected %d recorded migrations, got: %d", database.LatestSchemaVersion(), applied)
+	}
+}
@@ -0,0 +1,103 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: carrier_policies.sql
+
+package querier
+
+import (
+	"context"
+)
+
+const createCarrierPolicy = `-- name: CreateCarrierPolicy :one
+INSERT INTO carrier_policies (name, action, carrier, service, uf, zipcode_start, zipcode_end, category)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING id, name, action, carrier, service, uf, zipcode_start, zipcode_end, category, created_at
+`
+
+type CreateCarrierPolicyParams struct {
+	Name         string
+	Action       string
+	Carrier      string
+	Service      string
+	Uf           string
+	ZipcodeStart int
+	ZipcodeEnd   int
+	Category     int
+}
+
+func (q *Queries) CreateCarrierPolicy(ctx context.Context, arg CreateCarrierPolicyParams) (CarrierPolicy, error) {
+	row := q.db.QueryRow(ctx, createCarrierPolicy,
+		arg.Name,
+		arg.Action,
+		arg.Carrier,
+		arg.Service,
+		arg.Uf,
+		arg.ZipcodeStart,
+		arg.ZipcodeEnd,
+		arg.Category,
+	)
+	var i CarrierPolicy
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Action,
+		&i.Carrier,
+		&i.Service,
+		&i.Uf,
+		&i.ZipcodeStart,
+		&i.ZipcodeEnd,
+		&i.Category,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deleteCarrierPolicy = `-- name: DeleteCarrierPolicy :execrows
+DELETE FROM carrier_policies
+WHERE id = $1
+`
+
+func (q *Queries) DeleteCarrierPolicy(ctx context.Context, id int32) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteCarrierPolicy, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const listCarrierPolicies = `-- name: ListCarrierPolicies :many
+SELECT id, name, action, carrier, service, uf, zipcode_start, zipcode_end, category, created_at FROM carrier_policies
+ORDER BY id
+`
+
+func (q *Queries) ListCarrierPolicies(ctx context.Context) ([]CarrierPolicy, error) {
+	rows, err := q.db.Query(ctx, listCarrierPolicies)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CarrierPolicy
+	for rows.Next() {
+		var i CarrierPolicy
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Action,
+			&i.Carrier,
+			&i.Service,
+			&i.Uf,
+			&i.ZipcodeStart,
+			&i.ZipcodeEnd,
+			&i.Category,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,44 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: copyfrom.go
+
+package querier
+
+import (
+	"context"
+)
+
+// iteratorForCreateQuoteBatchItems implements pgx.CopyFromSource.
+type iteratorForCreateQuoteBatchItems struct {
+	rows                 []CreateQuoteBatchItemsParams
+	skippedFirstNextCall bool
+}
+
+func (r *iteratorForCreateQuoteBatchItems) Next() bool {
+	if len(r.rows) == 0 {
+		return false
+	}
+	if !r.skippedFirstNextCall {
+		r.skippedFirstNextCall = true
+		return true
+	}
+	r.rows = r.rows[1:]
+	return len(r.rows) > 0
+}
+
+func (r iteratorForCreateQuoteBatchItems) Values() ([]interface{}, error) {
+	return []interface{}{
+		r.rows[0].BatchID,
+		r.rows[0].Position,
+		r.rows[0].Request,
+	}, nil
+}
+
+func (r iteratorForCreateQuoteBatchItems) Err() error {
+	return nil
+}
+
+func (q *Queries) CreateQuoteBatchItems(ctx context.Context, arg []CreateQuoteBatchItemsParams) (int64, error) {
+	return q.db.CopyFrom(ctx, []string{"quote_batch_items"}, []string{"batch_id", "position", "request"}, &iteratorForCreateQuoteBatchItems{rows: arg})
+}
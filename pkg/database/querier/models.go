@@ -6,14 +6,139 @@ package querier
 
 import (
 	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/money"
 )
 
+type ApiKey struct {
+	ID        int32
+	TenantID  int
+	Prefix    string
+	KeyHash   string
+	CreatedAt pgtype.Timestamp
+	RevokedAt pgtype.Timestamp
+}
+
+type CarrierPolicy struct {
+	ID           int32
+	Name         string
+	Action       string
+	Carrier      string
+	Service      string
+	Uf           string
+	ZipcodeStart int
+	ZipcodeEnd   int
+	Category     int
+	CreatedAt    pgtype.Timestamp
+}
+
 type Quote struct {
+	ID            int32
+	CarrierName   string
+	Service       string
+	Price         money.Money
+	Deadline      int
+	CreatedAt     pgtype.Timestamp
+	UpdatedAt     pgtype.Timestamp
+	Estimated     bool
+	OriginalPrice money.Money
+}
+
+type QuoteBatch struct {
 	ID          int32
+	TenantID    *int
+	TotalItems  int
+	CreatedAt   pgtype.Timestamp
+	CompletedAt pgtype.Timestamp
+}
+
+type QuoteBatchItem struct {
+	ID        int32
+	BatchID   int
+	Position  int
+	Status    string
+	Request   []byte
+	Response  []byte
+	Error     *string
+	Attempts  int
+	CreatedAt pgtype.Timestamp
+	UpdatedAt pgtype.Timestamp
+}
+
+type QuoteFee struct {
+	QuoteID int
+	Fee     string
+	Amount  money.Money
+}
+
+type QuotePricingRule struct {
+	QuoteID int
+	Step    int
+	Rule    string
+	Price   money.Money
+}
+
+type QuoteUsage struct {
+	TenantID  int
+	Period    pgtype.Date
+	Quotes    int
+	UpdatedAt pgtype.Timestamp
+}
+
+type RoutePriceHistory struct {
+	ID          int64
+	RouteID     int
 	CarrierName string
 	Service     string
-	Price       float64
+	Price       money.Money
 	Deadline    int
-	CreatedAt   pgtype.Timestamp
-	UpdatedAt   pgtype.Timestamp
+	QuotedAt    pgtype.Timestamp
+}
+
+type Tenant struct {
+	ID                int32
+	Name              string
+	ShipperCnpj       string
+	ShipperToken      string
+	PlatformCode      string
+	OriginZipcode     int
+	CreatedAt         pgtype.Timestamp
+	UpdatedAt         pgtype.Timestamp
+	MonthlyQuoteQuota *int
+}
+
+type WatchedRoute struct {
+	ID                 int32
+	TenantID           *int
+	Name               string
+	OriginZipcode      int
+	DestinationZipcode string
+	Volumes            []byte
+	IntervalSeconds    int
+	NextRunAt          pgtype.Timestamp
+	LastRunAt          pgtype.Timestamp
+	LastError          *string
+	CreatedAt          pgtype.Timestamp
+}
+
+type WebhookDelivery struct {
+	ID             int32
+	SubscriptionID int
+	EventType      string
+	Payload        []byte
+	Status         string
+	Attempts       int
+	NextAttemptAt  pgtype.Timestamp
+	LastStatusCode *int
+	LastError      *string
+	CreatedAt      pgtype.Timestamp
+	UpdatedAt      pgtype.Timestamp
+}
+
+type WebhookSubscription struct {
+	ID         int32
+	TenantID   *int
+	Url        string
+	Secret     string
+	EventTypes []string
+	CreatedAt  pgtype.Timestamp
 }
@@ -0,0 +1,250 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: quote_batches.sql
+
+package querier
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const claimQuoteBatchItem = `-- name: ClaimQuoteBatchItem :one
+WITH next AS (
+  SELECT id FROM quote_batch_items
+  WHERE status = 'pending'
+  ORDER BY id
+  LIMIT 1
+  FOR UPDATE SKIP LOCKED
+)
+UPDATE quote_batch_items
+SET status = 'processing',
+    attempts = quote_batch_items.attempts + 1,
+    updated_at = now()
+FROM next, quote_batches
+WHERE quote_batch_items.id = next.id
+  AND quote_batches.id = quote_batch_items.batch_id
+RETURNING quote_batch_items.id, quote_batch_items.batch_id, quote_batch_items.request, quote_batch_items.attempts, quote_batches.tenant_id
+`
+
+type ClaimQuoteBatchItemRow struct {
+	ID       int32
+	BatchID  int
+	Request  []byte
+	Attempts int
+	TenantID *int
+}
+
+func (q *Queries) ClaimQuoteBatchItem(ctx context.Context) (ClaimQuoteBatchItemRow, error) {
+	row := q.db.QueryRow(ctx, claimQuoteBatchItem)
+	var i ClaimQuoteBatchItemRow
+	err := row.Scan(
+		&i.ID,
+		&i.BatchID,
+		&i.Request,
+		&i.Attempts,
+		&i.TenantID,
+	)
+	return i, err
+}
+
+const completeQuoteBatchItem = `-- name: CompleteQuoteBatchItem :execrows
+UPDATE quote_batch_items
+SET status = $1,
+    response = $2,
+    error = $3,
+    updated_at = now()
+WHERE id = $4
+  AND status = 'processing'
+  AND attempts = $5
+`
+
+type CompleteQuoteBatchItemParams struct {
+	Status   string
+	Response []byte
+	Error    *string
+	ID       int32
+	Attempts int
+}
+
+func (q *Queries) CompleteQuoteBatchItem(ctx context.Context, arg CompleteQuoteBatchItemParams) (int64, error) {
+	result, err := q.db.Exec(ctx, completeQuoteBatchItem,
+		arg.Status,
+		arg.Response,
+		arg.Error,
+		arg.ID,
+		arg.Attempts,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const createQuoteBatch = `-- name: CreateQuoteBatch :one
+INSERT INTO quote_batches (tenant_id, total_items)
+VALUES ($1, $2)
+RETURNING id, tenant_id, total_items, created_at, completed_at
+`
+
+type CreateQuoteBatchParams struct {
+	TenantID   *int
+	TotalItems int
+}
+
+func (q *Queries) CreateQuoteBatch(ctx context.Context, arg CreateQuoteBatchParams) (QuoteBatch, error) {
+	row := q.db.QueryRow(ctx, createQuoteBatch, arg.TenantID, arg.TotalItems)
+	var i QuoteBatch
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.TotalItems,
+		&i.CreatedAt,
+		&i.CompletedAt,
+	)
+	return i, err
+}
+
+type CreateQuoteBatchItemsParams struct {
+	BatchID  int
+	Position int
+	Request  []byte
+}
+
+const findQuoteBatch = `-- name: FindQuoteBatch :one
+SELECT quote_batches.id, quote_batches.tenant_id, quote_batches.total_items, quote_batches.created_at, quote_batches.completed_at,
+       COUNT(*) FILTER (WHERE quote_batch_items.status IN ('pending', 'processing'))::int AS pending_items,
+       COUNT(*) FILTER (WHERE quote_batch_items.status = 'succeeded')::int AS succeeded_items,
+       COUNT(*) FILTER (WHERE quote_batch_items.status = 'failed')::int AS failed_items
+FROM quote_batches
+JOIN quote_batch_items ON quote_batch_items.batch_id = quote_batches.id
+WHERE quote_batches.id = $1
+GROUP BY quote_batches.id
+`
+
+type FindQuoteBatchRow struct {
+	ID             int32
+	TenantID       *int
+	TotalItems     int
+	CreatedAt      pgtype.Timestamp
+	CompletedAt    pgtype.Timestamp
+	PendingItems   int
+	SucceededItems int
+	FailedItems    int
+}
+
+func (q *Queries) FindQuoteBatch(ctx context.Context, id int32) (FindQuoteBatchRow, error) {
+	row := q.db.QueryRow(ctx, findQuoteBatch, id)
+	var i FindQuoteBatchRow
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.TotalItems,
+		&i.CreatedAt,
+		&i.CompletedAt,
+		&i.PendingItems,
+		&i.SucceededItems,
+		&i.FailedItems,
+	)
+	return i, err
+}
+
+const finishQuoteBatch = `-- name: FinishQuoteBatch :exec
+UPDATE quote_batches
+SET completed_at = now()
+WHERE quote_batches.id = $1
+  AND quote_batches.completed_at IS NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM quote_batch_items
+    WHERE quote_batch_items.batch_id = $1
+      AND quote_batch_items.status IN ('pending', 'processing')
+  )
+`
+
+func (q *Queries) FinishQuoteBatch(ctx context.Context, batchID int32) error {
+	_, err := q.db.Exec(ctx, finishQuoteBatch, batchID)
+	return err
+}
+
+const listQuoteBatchItems = `-- name: ListQuoteBatchItems :many
+SELECT id, batch_id, position, status, request, response, error, attempts, created_at, updated_at FROM quote_batch_items
+WHERE batch_id = $1
+ORDER BY position
+`
+
+func (q *Queries) ListQuoteBatchItems(ctx context.Context, batchID int) ([]QuoteBatchItem, error) {
+	rows, err := q.db.Query(ctx, listQuoteBatchItems, batchID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []QuoteBatchItem
+	for rows.Next() {
+		var i QuoteBatchItem
+		if err := rows.Scan(
+			&i.ID,
+			&i.BatchID,
+			&i.Position,
+			&i.Status,
+			&i.Request,
+			&i.Response,
+			&i.Error,
+			&i.Attempts,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const requeueStaleQuoteBatchItems = `-- name: RequeueStaleQuoteBatchItems :many
+UPDATE quote_batch_items
+SET status = CASE WHEN attempts >= $1::int THEN 'failed' ELSE 'pending' END,
+    error = CASE
+      WHEN attempts >= $1::int THEN 'abandoned after ' || attempts || ' attempts'
+      ELSE error
+    END,
+    updated_at = now()
+WHERE status = 'processing'
+  AND updated_at < now() - make_interval(secs => $2::int)
+RETURNING id, batch_id, status
+`
+
+type RequeueStaleQuoteBatchItemsParams struct {
+	MaxAttempts       int
+	StaleAfterSeconds int
+}
+
+type RequeueStaleQuoteBatchItemsRow struct {
+	ID      int32
+	BatchID int
+	Status  string
+}
+
+func (q *Queries) RequeueStaleQuoteBatchItems(ctx context.Context, arg RequeueStaleQuoteBatchItemsParams) ([]RequeueStaleQuoteBatchItemsRow, error) {
+	rows, err := q.db.Query(ctx, requeueStaleQuoteBatchItems, arg.MaxAttempts, arg.StaleAfterSeconds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []RequeueStaleQuoteBatchItemsRow
+	for rows.Next() {
+		var i RequeueStaleQuoteBatchItemsRow
+		if err := rows.Scan(&i.ID, &i.BatchID, &i.Status); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -7,19 +7,23 @@ package querier
 
 import (
 	"context"
+
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/money"
 )
 
 const createQuote = `-- name: CreateQuote :one
-INSERT INTO quotes (carrier_name, service, price, deadline)
-VALUES ($1, $2, $3, $4)
-RETURNING id, carrier_name, service, price, deadline, created_at, updated_at
+INSERT INTO quotes (carrier_name, service, price, deadline, estimated, original_price)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, carrier_name, service, price, deadline, created_at, updated_at, estimated, original_price
 `
 
 type CreateQuoteParams struct {
-	CarrierName string
-	Service     string
-	Price       float64
-	Deadline    int
+	CarrierName   string
+	Service       string
+	Price         money.Money
+	Deadline      int
+	Estimated     bool
+	OriginalPrice money.Money
 }
 
 func (q *Queries) CreateQuote(ctx context.Context, arg CreateQuoteParams) (Quote, error) {
@@ -28,6 +32,8 @@ func (q *Queries) CreateQuote(ctx context.Context, arg CreateQuoteParams) (Quote
 		arg.Service,
 		arg.Price,
 		arg.Deadline,
+		arg.Estimated,
+		arg.OriginalPrice,
 	)
 	var i Quote
 	err := row.Scan(
@@ -38,12 +44,52 @@ func (q *Queries) CreateQuote(ctx context.Context, arg CreateQuoteParams) (Quote
 		&i.Deadline,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Estimated,
+		&i.OriginalPrice,
 	)
 	return i, err
 }
 
+const createQuoteFee = `-- name: CreateQuoteFee :exec
+INSERT INTO quote_fees (quote_id, fee, amount)
+VALUES ($1, $2, $3)
+`
+
+type CreateQuoteFeeParams struct {
+	QuoteID int
+	Fee     string
+	Amount  money.Money
+}
+
+func (q *Queries) CreateQuoteFee(ctx context.Context, arg CreateQuoteFeeParams) error {
+	_, err := q.db.Exec(ctx, createQuoteFee, arg.QuoteID, arg.Fee, arg.Amount)
+	return err
+}
+
+const createQuotePricingRule = `-- name: CreateQuotePricingRule :exec
+INSERT INTO quote_pricing_rules (quote_id, step, rule, price)
+VALUES ($1, $2, $3, $4)
+`
+
+type CreateQuotePricingRuleParams struct {
+	QuoteID int
+	Step    int
+	Rule    string
+	Price   money.Money
+}
+
+func (q *Queries) CreateQuotePricingRule(ctx context.Context, arg CreateQuotePricingRuleParams) error {
+	_, err := q.db.Exec(ctx, createQuotePricingRule,
+		arg.QuoteID,
+		arg.Step,
+		arg.Rule,
+		arg.Price,
+	)
+	return err
+}
+
 const findLastQuotes = `-- name: FindLastQuotes :many
-SELECT id, carrier_name, service, price, deadline, created_at, updated_at FROM quotes
+SELECT id, carrier_name, service, price, deadline, created_at, updated_at, estimated, original_price FROM quotes
 ORDER BY created_at DESC
 LIMIT $1::int
 `
@@ -65,6 +111,8 @@ func (q *Queries) FindLastQuotes(ctx context.Context, limitquotes int) ([]Quote,
 			&i.Deadline,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Estimated,
+			&i.OriginalPrice,
 		); err != nil {
 			return nil, err
 		}
@@ -0,0 +1,271 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: routes.sql
+
+package querier
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/money"
+)
+
+const claimDueWatchedRoutes = `-- name: ClaimDueWatchedRoutes :many
+WITH due AS (
+  SELECT id FROM watched_routes
+  WHERE next_run_at <= now()
+  ORDER BY next_run_at
+  LIMIT $1::int
+  FOR UPDATE SKIP LOCKED
+)
+UPDATE watched_routes
+SET next_run_at = now() + make_interval(secs => watched_routes.interval_seconds),
+    last_run_at = now()
+FROM due
+WHERE watched_routes.id = due.id
+RETURNING watched_routes.id, watched_routes.tenant_id, watched_routes.name, watched_routes.origin_zipcode, watched_routes.destination_zipcode, watched_routes.volumes, watched_routes.interval_seconds, watched_routes.next_run_at, watched_routes.last_run_at, watched_routes.last_error, watched_routes.created_at
+`
+
+func (q *Queries) ClaimDueWatchedRoutes(ctx context.Context, maxRoutes int) ([]WatchedRoute, error) {
+	rows, err := q.db.Query(ctx, claimDueWatchedRoutes, maxRoutes)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WatchedRoute
+	for rows.Next() {
+		var i WatchedRoute
+		if err := rows.Scan(
+			&i.ID,
+			&i.TenantID,
+			&i.Name,
+			&i.OriginZipcode,
+			&i.DestinationZipcode,
+			&i.Volumes,
+			&i.IntervalSeconds,
+			&i.NextRunAt,
+			&i.LastRunAt,
+			&i.LastError,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const createRoutePrice = `-- name: CreateRoutePrice :exec
+INSERT INTO route_price_history (route_id, carrier_name, service, price, deadline)
+VALUES ($1, $2, $3, $4, $5)
+`
+
+type CreateRoutePriceParams struct {
+	RouteID     int
+	CarrierName string
+	Service     string
+	Price       money.Money
+	Deadline    int
+}
+
+func (q *Queries) CreateRoutePrice(ctx context.Context, arg CreateRoutePriceParams) error {
+	_, err := q.db.Exec(ctx, createRoutePrice,
+		arg.RouteID,
+		arg.CarrierName,
+		arg.Service,
+		arg.Price,
+		arg.Deadline,
+	)
+	return err
+}
+
+const createWatchedRoute = `-- name: CreateWatchedRoute :one
+INSERT INTO watched_routes (tenant_id, name, origin_zipcode, destination_zipcode, volumes, interval_seconds)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, tenant_id, name, origin_zipcode, destination_zipcode, volumes, interval_seconds, next_run_at, last_run_at, last_error, created_at
+`
+
+type CreateWatchedRouteParams struct {
+	TenantID           *int
+	Name               string
+	OriginZipcode      int
+	DestinationZipcode string
+	Volumes            []byte
+	IntervalSeconds    int
+}
+
+func (q *Queries) CreateWatchedRoute(ctx context.Context, arg CreateWatchedRouteParams) (WatchedRoute, error) {
+	row := q.db.QueryRow(ctx, createWatchedRoute,
+		arg.TenantID,
+		arg.Name,
+		arg.OriginZipcode,
+		arg.DestinationZipcode,
+		arg.Volumes,
+		arg.IntervalSeconds,
+	)
+	var i WatchedRoute
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.Name,
+		&i.OriginZipcode,
+		&i.DestinationZipcode,
+		&i.Volumes,
+		&i.IntervalSeconds,
+		&i.NextRunAt,
+		&i.LastRunAt,
+		&i.LastError,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deleteWatchedRoute = `-- name: DeleteWatchedRoute :execrows
+DELETE FROM watched_routes
+WHERE id = $1
+  AND tenant_id IS NOT DISTINCT FROM $2
+`
+
+type DeleteWatchedRouteParams struct {
+	ID       int32
+	TenantID *int
+}
+
+func (q *Queries) DeleteWatchedRoute(ctx context.Context, arg DeleteWatchedRouteParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteWatchedRoute, arg.ID, arg.TenantID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const findWatchedRoute = `-- name: FindWatchedRoute :one
+SELECT id, tenant_id, name, origin_zipcode, destination_zipcode, volumes, interval_seconds, next_run_at, last_run_at, last_error, created_at FROM watched_routes
+WHERE id = $1
+  AND tenant_id IS NOT DISTINCT FROM $2
+`
+
+type FindWatchedRouteParams struct {
+	ID       int32
+	TenantID *int
+}
+
+func (q *Queries) FindWatchedRoute(ctx context.Context, arg FindWatchedRouteParams) (WatchedRoute, error) {
+	row := q.db.QueryRow(ctx, findWatchedRoute, arg.ID, arg.TenantID)
+	var i WatchedRoute
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.Name,
+		&i.OriginZipcode,
+		&i.DestinationZipcode,
+		&i.Volumes,
+		&i.IntervalSeconds,
+		&i.NextRunAt,
+		&i.LastRunAt,
+		&i.LastError,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listRoutePriceHistory = `-- name: ListRoutePriceHistory :many
+SELECT id, route_id, carrier_name, service, price, deadline, quoted_at FROM route_price_history
+WHERE route_id = $1
+  AND quoted_at >= $2
+  AND quoted_at < $3
+ORDER BY carrier_name, service, quoted_at
+`
+
+type ListRoutePriceHistoryParams struct {
+	RouteID  int
+	FromTime pgtype.Timestamp
+	ToTime   pgtype.Timestamp
+}
+
+func (q *Queries) ListRoutePriceHistory(ctx context.Context, arg ListRoutePriceHistoryParams) ([]RoutePriceHistory, error) {
+	rows, err := q.db.Query(ctx, listRoutePriceHistory, arg.RouteID, arg.FromTime, arg.ToTime)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []RoutePriceHistory
+	for rows.Next() {
+		var i RoutePriceHistory
+		if err := rows.Scan(
+			&i.ID,
+			&i.RouteID,
+			&i.CarrierName,
+			&i.Service,
+			&i.Price,
+			&i.Deadline,
+			&i.QuotedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWatchedRoutes = `-- name: ListWatchedRoutes :many
+SELECT id, tenant_id, name, origin_zipcode, destination_zipcode, volumes, interval_seconds, next_run_at, last_run_at, last_error, created_at FROM watched_routes
+WHERE tenant_id IS NOT DISTINCT FROM $1
+ORDER BY id
+`
+
+func (q *Queries) ListWatchedRoutes(ctx context.Context, tenantID *int) ([]WatchedRoute, error) {
+	rows, err := q.db.Query(ctx, listWatchedRoutes, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WatchedRoute
+	for rows.Next() {
+		var i WatchedRoute
+		if err := rows.Scan(
+			&i.ID,
+			&i.TenantID,
+			&i.Name,
+			&i.OriginZipcode,
+			&i.DestinationZipcode,
+			&i.Volumes,
+			&i.IntervalSeconds,
+			&i.NextRunAt,
+			&i.LastRunAt,
+			&i.LastError,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setWatchedRouteError = `-- name: SetWatchedRouteError :exec
+UPDATE watched_routes
+SET last_error = $1
+WHERE id = $2
+`
+
+type SetWatchedRouteErrorParams struct {
+	LastError *string
+	ID        int32
+}
+
+func (q *Queries) SetWatchedRouteError(ctx context.Context, arg SetWatchedRouteErrorParams) error {
+	_, err := q.db.Exec(ctx, setWatchedRouteError, arg.LastError, arg.ID)
+	return err
+}
@@ -0,0 +1,245 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: tenants.sql
+
+package querier
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const consumeQuoteUsage = `-- name: ConsumeQuoteUsage :one
+INSERT INTO quote_usage (tenant_id, period, quotes)
+VALUES ($1, $2, 1)
+ON CONFLICT (tenant_id, period) DO UPDATE
+SET quotes = quote_usage.quotes + 1,
+    updated_at = now()
+WHERE quote_usage.quotes < $3::int
+RETURNING quotes
+`
+
+type ConsumeQuoteUsageParams struct {
+	TenantID int
+	Period   pgtype.Date
+	Quota    int
+}
+
+func (q *Queries) ConsumeQuoteUsage(ctx context.Context, arg ConsumeQuoteUsageParams) (int, error) {
+	row := q.db.QueryRow(ctx, consumeQuoteUsage, arg.TenantID, arg.Period, arg.Quota)
+	var quotes int
+	err := row.Scan(&quotes)
+	return quotes, err
+}
+
+const createAPIKey = `-- name: CreateAPIKey :one
+INSERT INTO api_keys (tenant_id, prefix, key_hash)
+VALUES ($1, $2, $3)
+RETURNING id, tenant_id, prefix, key_hash, created_at, revoked_at
+`
+
+type CreateAPIKeyParams struct {
+	TenantID int
+	Prefix   string
+	KeyHash  string
+}
+
+func (q *Queries) CreateAPIKey(ctx context.Context, arg CreateAPIKeyParams) (ApiKey, error) {
+	row := q.db.QueryRow(ctx, createAPIKey, arg.TenantID, arg.Prefix, arg.KeyHash)
+	var i ApiKey
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.Prefix,
+		&i.KeyHash,
+		&i.CreatedAt,
+		&i.RevokedAt,
+	)
+	return i, err
+}
+
+const createTenant = `-- name: CreateTenant :one
+INSERT INTO tenants (name, shipper_cnpj, shipper_token, platform_code, origin_zipcode, monthly_quote_quota)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, name, shipper_cnpj, shipper_token, platform_code, origin_zipcode, created_at, updated_at, monthly_quote_quota
+`
+
+type CreateTenantParams struct {
+	Name              string
+	ShipperCnpj       string
+	ShipperToken      string
+	PlatformCode      string
+	OriginZipcode     int
+	MonthlyQuoteQuota *int
+}
+
+func (q *Queries) CreateTenant(ctx context.Context, arg CreateTenantParams) (Tenant, error) {
+	row := q.db.QueryRow(ctx, createTenant,
+		arg.Name,
+		arg.ShipperCnpj,
+		arg.ShipperToken,
+		arg.PlatformCode,
+		arg.OriginZipcode,
+		arg.MonthlyQuoteQuota,
+	)
+	var i Tenant
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.ShipperCnpj,
+		&i.ShipperToken,
+		&i.PlatformCode,
+		&i.OriginZipcode,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.MonthlyQuoteQuota,
+	)
+	return i, err
+}
+
+const findTenantByAPIKeyHash = `-- name: FindTenantByAPIKeyHash :one
+SELECT tenants.id, tenants.name, tenants.shipper_cnpj, tenants.shipper_token, tenants.platform_code, tenants.origin_zipcode, tenants.created_at, tenants.updated_at, tenants.monthly_quote_quota FROM tenants
+JOIN api_keys ON api_keys.tenant_id = tenants.id
+WHERE api_keys.key_hash = $1
+  AND api_keys.revoked_at IS NULL
+`
+
+func (q *Queries) FindTenantByAPIKeyHash(ctx context.Context, keyHash string) (Tenant, error) {
+	row := q.db.QueryRow(ctx, findTenantByAPIKeyHash, keyHash)
+	var i Tenant
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.ShipperCnpj,
+		&i.ShipperToken,
+		&i.PlatformCode,
+		&i.OriginZipcode,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.MonthlyQuoteQuota,
+	)
+	return i, err
+}
+
+const findTenantByID = `-- name: FindTenantByID :one
+SELECT id, name, shipper_cnpj, shipper_token, platform_code, origin_zipcode, created_at, updated_at, monthly_quote_quota FROM tenants
+WHERE id = $1
+`
+
+func (q *Queries) FindTenantByID(ctx context.Context, id int32) (Tenant, error) {
+	row := q.db.QueryRow(ctx, findTenantByID, id)
+	var i Tenant
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.ShipperCnpj,
+		&i.ShipperToken,
+		&i.PlatformCode,
+		&i.OriginZipcode,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.MonthlyQuoteQuota,
+	)
+	return i, err
+}
+
+const findTenantByName = `-- name: FindTenantByName :one
+SELECT id, name, shipper_cnpj, shipper_token, platform_code, origin_zipcode, created_at, updated_at, monthly_quote_quota FROM tenants
+WHERE name = $1
+`
+
+func (q *Queries) FindTenantByName(ctx context.Context, name string) (Tenant, error) {
+	row := q.db.QueryRow(ctx, findTenantByName, name)
+	var i Tenant
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.ShipperCnpj,
+		&i.ShipperToken,
+		&i.PlatformCode,
+		&i.OriginZipcode,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.MonthlyQuoteQuota,
+	)
+	return i, err
+}
+
+const listQuoteUsage = `-- name: ListQuoteUsage :many
+SELECT tenants.id AS tenant_id,
+       tenants.name AS tenant_name,
+       tenants.monthly_quote_quota,
+       COALESCE(quote_usage.quotes, 0)::int AS quotes
+FROM tenants
+LEFT JOIN quote_usage ON quote_usage.tenant_id = tenants.id
+  AND quote_usage.period = $1
+ORDER BY tenants.name
+`
+
+type ListQuoteUsageRow struct {
+	TenantID          int32
+	TenantName        string
+	MonthlyQuoteQuota *int
+	Quotes            int
+}
+
+func (q *Queries) ListQuoteUsage(ctx context.Context, period pgtype.Date) ([]ListQuoteUsageRow, error) {
+	rows, err := q.db.Query(ctx, listQuoteUsage, period)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListQuoteUsageRow
+	for rows.Next() {
+		var i ListQuoteUsageRow
+		if err := rows.Scan(
+			&i.TenantID,
+			&i.TenantName,
+			&i.MonthlyQuoteQuota,
+			&i.Quotes,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const refundQuoteUsage = `-- name: RefundQuoteUsage :exec
+UPDATE quote_usage
+SET quotes = quotes - 1,
+    updated_at = now()
+WHERE tenant_id = $1
+  AND period = $2
+  AND quotes > 0
+`
+
+type RefundQuoteUsageParams struct {
+	TenantID int
+	Period   pgtype.Date
+}
+
+func (q *Queries) RefundQuoteUsage(ctx context.Context, arg RefundQuoteUsageParams) error {
+	_, err := q.db.Exec(ctx, refundQuoteUsage, arg.TenantID, arg.Period)
+	return err
+}
+
+const revokeAPIKey = `-- name: RevokeAPIKey :execrows
+UPDATE api_keys
+SET revoked_at = now()
+WHERE prefix = $1
+  AND revoked_at IS NULL
+`
+
+func (q *Queries) RevokeAPIKey(ctx context.Context, prefix string) (int64, error) {
+	result, err := q.db.Exec(ctx, revokeAPIKey, prefix)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
@@ -0,0 +1,264 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: webhooks.sql
+
+package querier
+
+import (
+	"context"
+)
+
+const claimWebhookDelivery = `-- name: ClaimWebhookDelivery :one
+WITH next AS (
+  SELECT id FROM webhook_deliveries
+  WHERE status = 'pending'
+    AND next_attempt_at <= now()
+  ORDER BY next_attempt_at
+  LIMIT 1
+  FOR UPDATE SKIP LOCKED
+)
+UPDATE webhook_deliveries
+SET next_attempt_at = now() + make_interval(secs => $1::int),
+    attempts = webhook_deliveries.attempts + 1,
+    updated_at = now()
+FROM next, webhook_subscriptions
+WHERE webhook_deliveries.id = next.id
+  AND webhook_subscriptions.id = webhook_deliveries.subscription_id
+RETURNING webhook_deliveries.id, webhook_deliveries.event_type, webhook_deliveries.payload, webhook_deliveries.attempts,
+          webhook_subscriptions.url, webhook_subscriptions.secret
+`
+
+type ClaimWebhookDeliveryRow struct {
+	ID        int32
+	EventType string
+	Payload   []byte
+	Attempts  int
+	Url       string
+	Secret    string
+}
+
+func (q *Queries) ClaimWebhookDelivery(ctx context.Context, leaseSeconds int) (ClaimWebhookDeliveryRow, error) {
+	row := q.db.QueryRow(ctx, claimWebhookDelivery, leaseSeconds)
+	var i ClaimWebhookDeliveryRow
+	err := row.Scan(
+		&i.ID,
+		&i.EventType,
+		&i.Payload,
+		&i.Attempts,
+		&i.Url,
+		&i.Secret,
+	)
+	return i, err
+}
+
+const createWebhookSubscription = `-- name: CreateWebhookSubscription :one
+INSERT INTO webhook_subscriptions (tenant_id, url, secret, event_types)
+VALUES ($1, $2, $3, $4)
+RETURNING id, tenant_id, url, secret, event_types, created_at
+`
+
+type CreateWebhookSubscriptionParams struct {
+	TenantID   *int
+	Url        string
+	Secret     string
+	EventTypes []string
+}
+
+func (q *Queries) CreateWebhookSubscription(ctx context.Context, arg CreateWebhookSubscriptionParams) (WebhookSubscription, error) {
+	row := q.db.QueryRow(ctx, createWebhookSubscription,
+		arg.TenantID,
+		arg.Url,
+		arg.Secret,
+		arg.EventTypes,
+	)
+	var i WebhookSubscription
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.Url,
+		&i.Secret,
+		&i.EventTypes,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deleteWebhookSubscription = `-- name: DeleteWebhookSubscription :execrows
+DELETE FROM webhook_subscriptions
+WHERE id = $1
+  AND tenant_id IS NOT DISTINCT FROM $2
+`
+
+type DeleteWebhookSubscriptionParams struct {
+	ID       int32
+	TenantID *int
+}
+
+func (q *Queries) DeleteWebhookSubscription(ctx context.Context, arg DeleteWebhookSubscriptionParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteWebhookSubscription, arg.ID, arg.TenantID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const enqueueWebhookDeliveries = `-- name: EnqueueWebhookDeliveries :execrows
+INSERT INTO webhook_deliveries (subscription_id, event_type, payload)
+SELECT id, $1, $2
+FROM webhook_subscriptions
+WHERE $1::text = ANY (event_types)
+  AND webhook_subscriptions.tenant_id IS NOT DISTINCT FROM $3
+`
+
+type EnqueueWebhookDeliveriesParams struct {
+	EventType string
+	Payload   []byte
+	TenantID  *int
+}
+
+func (q *Queries) EnqueueWebhookDeliveries(ctx context.Context, arg EnqueueWebhookDeliveriesParams) (int64, error) {
+	result, err := q.db.Exec(ctx, enqueueWebhookDeliveries, arg.EventType, arg.Payload, arg.TenantID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const listWebhookDeliveries = `-- name: ListWebhookDeliveries :many
+SELECT webhook_deliveries.id, webhook_deliveries.subscription_id, webhook_deliveries.event_type, webhook_deliveries.payload, webhook_deliveries.status, webhook_deliveries.attempts, webhook_deliveries.next_attempt_at, webhook_deliveries.last_status_code, webhook_deliveries.last_error, webhook_deliveries.created_at, webhook_deliveries.updated_at FROM webhook_deliveries
+JOIN webhook_subscriptions ON webhook_subscriptions.id = webhook_deliveries.subscription_id
+WHERE webhook_deliveries.subscription_id = $1
+  AND webhook_subscriptions.tenant_id IS NOT DISTINCT FROM $2
+  AND ($3::text = '' OR webhook_deliveries.status = $3::text)
+ORDER BY webhook_deliveries.id DESC
+LIMIT 100
+`
+
+type ListWebhookDeliveriesParams struct {
+	SubscriptionID int
+	TenantID       *int
+	Status         string
+}
+
+func (q *Queries) ListWebhookDeliveries(ctx context.Context, arg ListWebhookDeliveriesParams) ([]WebhookDelivery, error) {
+	rows, err := q.db.Query(ctx, listWebhookDeliveries, arg.SubscriptionID, arg.TenantID, arg.Status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WebhookDelivery
+	for rows.Next() {
+		var i WebhookDelivery
+		if err := rows.Scan(
+			&i.ID,
+			&i.SubscriptionID,
+			&i.EventType,
+			&i.Payload,
+			&i.Status,
+			&i.Attempts,
+			&i.NextAttemptAt,
+			&i.LastStatusCode,
+			&i.LastError,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWebhookSubscriptions = `-- name: ListWebhookSubscriptions :many
+SELECT id, tenant_id, url, secret, event_types, created_at FROM webhook_subscriptions
+WHERE tenant_id IS NOT DISTINCT FROM $1
+ORDER BY id
+`
+
+func (q *Queries) ListWebhookSubscriptions(ctx context.Context, tenantID *int) ([]WebhookSubscription, error) {
+	rows, err := q.db.Query(ctx, listWebhookSubscriptions, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WebhookSubscription
+	for rows.Next() {
+		var i WebhookSubscription
+		if err := rows.Scan(
+			&i.ID,
+			&i.TenantID,
+			&i.Url,
+			&i.Secret,
+			&i.EventTypes,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const recordWebhookDeliveryAttempt = `-- name: RecordWebhookDeliveryAttempt :exec
+UPDATE webhook_deliveries
+SET status = $1,
+    next_attempt_at = now() + make_interval(secs => $2::int),
+    last_status_code = $3,
+    last_error = $4,
+    updated_at = now()
+WHERE id = $5
+`
+
+type RecordWebhookDeliveryAttemptParams struct {
+	Status            string
+	RetryAfterSeconds int
+	LastStatusCode    *int
+	LastError         *string
+	ID                int32
+}
+
+func (q *Queries) RecordWebhookDeliveryAttempt(ctx context.Context, arg RecordWebhookDeliveryAttemptParams) error {
+	_, err := q.db.Exec(ctx, recordWebhookDeliveryAttempt,
+		arg.Status,
+		arg.RetryAfterSeconds,
+		arg.LastStatusCode,
+		arg.LastError,
+		arg.ID,
+	)
+	return err
+}
+
+const retryWebhookDelivery = `-- name: RetryWebhookDelivery :execrows
+UPDATE webhook_deliveries
+SET status = 'pending',
+    attempts = 0,
+    next_attempt_at = now(),
+    updated_at = now()
+FROM webhook_subscriptions
+WHERE webhook_deliveries.id = $1
+  AND webhook_deliveries.subscription_id = $2
+  AND webhook_subscriptions.id = webhook_deliveries.subscription_id
+  AND webhook_subscriptions.tenant_id IS NOT DISTINCT FROM $3
+  AND webhook_deliveries.status = 'dead'
+`
+
+type RetryWebhookDeliveryParams struct {
+	ID             int32
+	SubscriptionID int
+	TenantID       *int
+}
+
+func (q *Queries) RetryWebhookDelivery(ctx context.Context, arg RetryWebhookDeliveryParams) (int64, error) {
+	result, err := q.db.Exec(ctx, retryWebhookDelivery, arg.ID, arg.SubscriptionID, arg.TenantID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
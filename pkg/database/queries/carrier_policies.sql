@@ -0,0 +1,12 @@
+-- name: CreateCarrierPolicy :one
+INSERT INTO carrier_policies (name, action, carrier, service, uf, zipcode_start, zipcode_end, category)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING *;
+
+-- name: ListCarrierPolicies :many
+SELECT * FROM carrier_policies
+ORDER BY id;
+
+-- name: DeleteCarrierPolicy :execrows
+DELETE FROM carrier_policies
+WHERE id = @id;
@@ -0,0 +1,73 @@
+-- name: CreateQuoteBatch :one
+INSERT INTO quote_batches (tenant_id, total_items)
+VALUES ($1, $2)
+RETURNING *;
+
+-- name: CreateQuoteBatchItems :copyfrom
+INSERT INTO quote_batch_items (batch_id, position, request)
+VALUES ($1, $2, $3);
+
+-- name: FindQuoteBatch :one
+SELECT quote_batches.*,
+       COUNT(*) FILTER (WHERE quote_batch_items.status IN ('pending', 'processing'))::int AS pending_items,
+       COUNT(*) FILTER (WHERE quote_batch_items.status = 'succeeded')::int AS succeeded_items,
+       COUNT(*) FILTER (WHERE quote_batch_items.status = 'failed')::int AS failed_items
+FROM quote_batches
+JOIN quote_batch_items ON quote_batch_items.batch_id = quote_batches.id
+WHERE quote_batches.id = $1
+GROUP BY quote_batches.id;
+
+-- name: ListQuoteBatchItems :many
+SELECT * FROM quote_batch_items
+WHERE batch_id = $1
+ORDER BY position;
+
+-- name: ClaimQuoteBatchItem :one
+WITH next AS (
+  SELECT id FROM quote_batch_items
+  WHERE status = 'pending'
+  ORDER BY id
+  LIMIT 1
+  FOR UPDATE SKIP LOCKED
+)
+UPDATE quote_batch_items
+SET status = 'processing',
+    attempts = quote_batch_items.attempts + 1,
+    updated_at = now()
+FROM next, quote_batches
+WHERE quote_batch_items.id = next.id
+  AND quote_batches.id = quote_batch_items.batch_id
+RETURNING quote_batch_items.id, quote_batch_items.batch_id, quote_batch_items.request, quote_batch_items.attempts, quote_batches.tenant_id;
+
+-- name: CompleteQuoteBatchItem :execrows
+UPDATE quote_batch_items
+SET status = @status,
+    response = @response,
+    error = @error,
+    updated_at = now()
+WHERE id = @id
+  AND status = 'processing'
+  AND attempts = @attempts;
+
+-- name: FinishQuoteBatch :exec
+UPDATE quote_batches
+SET completed_at = now()
+WHERE quote_batches.id = @batch_id
+  AND quote_batches.completed_at IS NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM quote_batch_items
+    WHERE quote_batch_items.batch_id = @batch_id
+      AND quote_batch_items.status IN ('pending', 'processing')
+  );
+
+-- name: RequeueStaleQuoteBatchItems :many
+UPDATE quote_batch_items
+SET status = CASE WHEN attempts >= @max_attempts::int THEN 'failed' ELSE 'pending' END,
+    error = CASE
+      WHEN attempts >= @max_attempts::int THEN 'abandoned after ' || attempts || ' attempts'
+      ELSE error
+    END,
+    updated_at = now()
+WHERE status = 'processing'
+  AND updated_at < now() - make_interval(secs => @stale_after_seconds::int)
+RETURNING id, batch_id, status;
@@ -1,9 +1,17 @@
 -- name: CreateQuote :one
-INSERT INTO quotes (carrier_name, service, price, deadline)
-VALUES ($1, $2, $3, $4)
+INSERT INTO quotes (carrier_name, service, price, deadline, estimated, original_price)
+VALUES ($1, $2, $3, $4, $5, $6)
 RETURNING *;
 
 -- name: FindLastQuotes :many
 SELECT * FROM quotes
 ORDER BY created_at DESC
-LIMIT @limitQuotes::int;
\ No newline at end of file
+LIMIT @limitQuotes::int;
+
+-- name: CreateQuoteFee :exec
+INSERT INTO quote_fees (quote_id, fee, amount)
+VALUES ($1, $2, $3);
+
+-- name: CreateQuotePricingRule :exec
+INSERT INTO quote_pricing_rules (quote_id, step, rule, price)
+VALUES ($1, $2, $3, $4);
@@ -0,0 +1,50 @@
+-- name: CreateWatchedRoute :one
+INSERT INTO watched_routes (tenant_id, name, origin_zipcode, destination_zipcode, volumes, interval_seconds)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: ListWatchedRoutes :many
+SELECT * FROM watched_routes
+WHERE tenant_id IS NOT DISTINCT FROM @tenant_id
+ORDER BY id;
+
+-- name: FindWatchedRoute :one
+SELECT * FROM watched_routes
+WHERE id = @id
+  AND tenant_id IS NOT DISTINCT FROM @tenant_id;
+
+-- name: DeleteWatchedRoute :execrows
+DELETE FROM watched_routes
+WHERE id = @id
+  AND tenant_id IS NOT DISTINCT FROM @tenant_id;
+
+-- name: ClaimDueWatchedRoutes :many
+WITH due AS (
+  SELECT id FROM watched_routes
+  WHERE next_run_at <= now()
+  ORDER BY next_run_at
+  LIMIT @max_routes::int
+  FOR UPDATE SKIP LOCKED
+)
+UPDATE watched_routes
+SET next_run_at = now() + make_interval(secs => watched_routes.interval_seconds),
+    last_run_at = now()
+FROM due
+WHERE watched_routes.id = due.id
+RETURNING watched_routes.*;
+
+-- name: SetWatchedRouteError :exec
+UPDATE watched_routes
+SET last_error = @last_error
+WHERE id = @id;
+
+-- name: CreateRoutePrice :exec
+INSERT INTO route_price_history (route_id, carrier_name, service, price, deadline)
+VALUES ($1, $2, $3, $4, $5);
+
+-- name: ListRoutePriceHistory :many
+SELECT * FROM route_price_history
+WHERE route_id = @route_id
+  AND quoted_at >= @from_time
+  AND quoted_at < @to_time
+ORDER BY carrier_name, service, quoted_at;
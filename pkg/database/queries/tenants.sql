@@ -0,0 +1,56 @@
+-- name: CreateTenant :one
+INSERT INTO tenants (name, shipper_cnpj, shipper_token, platform_code, origin_zipcode, monthly_quote_quota)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: FindTenantByName :one
+SELECT * FROM tenants
+WHERE name = $1;
+
+-- name: CreateAPIKey :one
+INSERT INTO api_keys (tenant_id, prefix, key_hash)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: FindTenantByAPIKeyHash :one
+SELECT tenants.* FROM tenants
+JOIN api_keys ON api_keys.tenant_id = tenants.id
+WHERE api_keys.key_hash = $1
+  AND api_keys.revoked_at IS NULL;
+
+-- name: RevokeAPIKey :execrows
+UPDATE api_keys
+SET revoked_at = now()
+WHERE prefix = $1
+  AND revoked_at IS NULL;
+
+-- name: ConsumeQuoteUsage :one
+INSERT INTO quote_usage (tenant_id, period, quotes)
+VALUES (@tenant_id, @period, 1)
+ON CONFLICT (tenant_id, period) DO UPDATE
+SET quotes = quote_usage.quotes + 1,
+    updated_at = now()
+WHERE quote_usage.quotes < @quota::int
+RETURNING quotes;
+
+-- name: RefundQuoteUsage :exec
+UPDATE quote_usage
+SET quotes = quotes - 1,
+    updated_at = now()
+WHERE tenant_id = @tenant_id
+  AND period = @period
+  AND quotes > 0;
+
+-- name: ListQuoteUsage :many
+SELECT tenants.id AS tenant_id,
+       tenants.name AS tenant_name,
+       tenants.monthly_quote_quota,
+       COALESCE(quote_usage.quotes, 0)::int AS quotes
+FROM tenants
+LEFT JOIN quote_usage ON quote_usage.tenant_id = tenants.id
+  AND quote_usage.period = @period
+ORDER BY tenants.name;
+
+-- name: FindTenantByID :one
+SELECT * FROM tenants
+WHERE id = $1;
@@ -0,0 +1,71 @@
+-- name: CreateWebhookSubscription :one
+INSERT INTO webhook_subscriptions (tenant_id, url, secret, event_types)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: ListWebhookSubscriptions :many
+SELECT * FROM webhook_subscriptions
+WHERE tenant_id IS NOT DISTINCT FROM @tenant_id
+ORDER BY id;
+
+-- name: DeleteWebhookSubscription :execrows
+DELETE FROM webhook_subscriptions
+WHERE id = @id
+  AND tenant_id IS NOT DISTINCT FROM @tenant_id;
+
+-- name: EnqueueWebhookDeliveries :execrows
+INSERT INTO webhook_deliveries (subscription_id, event_type, payload)
+SELECT id, @event_type, @payload
+FROM webhook_subscriptions
+WHERE @event_type::text = ANY (event_types)
+  AND webhook_subscriptions.tenant_id IS NOT DISTINCT FROM @tenant_id;
+
+-- name: ClaimWebhookDelivery :one
+WITH next AS (
+  SELECT id FROM webhook_deliveries
+  WHERE status = 'pending'
+    AND next_attempt_at <= now()
+  ORDER BY next_attempt_at
+  LIMIT 1
+  FOR UPDATE SKIP LOCKED
+)
+UPDATE webhook_deliveries
+SET next_attempt_at = now() + make_interval(secs => @lease_seconds::int),
+    attempts = webhook_deliveries.attempts + 1,
+    updated_at = now()
+FROM next, webhook_subscriptions
+WHERE webhook_deliveries.id = next.id
+  AND webhook_subscriptions.id = webhook_deliveries.subscription_id
+RETURNING webhook_deliveries.id, webhook_deliveries.event_type, webhook_deliveries.payload, webhook_deliveries.attempts,
+          webhook_subscriptions.url, webhook_subscriptions.secret;
+
+-- name: RecordWebhookDeliveryAttempt :exec
+UPDATE webhook_deliveries
+SET status = @status,
+    next_attempt_at = now() + make_interval(secs => @retry_after_seconds::int),
+    last_status_code = @last_status_code,
+    last_error = @last_error,
+    updated_at = now()
+WHERE id = @id;
+
+-- name: ListWebhookDeliveries :many
+SELECT webhook_deliveries.* FROM webhook_deliveries
+JOIN webhook_subscriptions ON webhook_subscriptions.id = webhook_deliveries.subscription_id
+WHERE webhook_deliveries.subscription_id = @subscription_id
+  AND webhook_subscriptions.tenant_id IS NOT DISTINCT FROM @tenant_id
+  AND (@status::text = '' OR webhook_deliveries.status = @status::text)
+ORDER BY webhook_deliveries.id DESC
+LIMIT 100;
+
+-- name: RetryWebhookDelivery :execrows
+UPDATE webhook_deliveries
+SET status = 'pending',
+    attempts = 0,
+    next_attempt_at = now(),
+    updated_at = now()
+FROM webhook_subscriptions
+WHERE webhook_deliveries.id = @id
+  AND webhook_deliveries.subscription_id = @subscription_id
+  AND webhook_subscriptions.id = webhook_deliveries.subscription_id
+  AND webhook_subscriptions.tenant_id IS NOT DISTINCT FROM @tenant_id
+  AND webhook_deliveries.status = 'dead';
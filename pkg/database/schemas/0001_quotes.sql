@@ -1,4 +1,6 @@
-CREATE TABLE quotes (
+-- Databases created before migrations were tracked already have this table,
+-- from the docker-compose init script, but no schema_migrations.
+CREATE TABLE IF NOT EXISTS quotes (
   id SERIAL PRIMARY KEY,
   carrier_name VARCHAR(255) NOT NULL,
   service VARCHAR(255) NOT NULL,
@@ -6,4 +8,4 @@ CREATE TABLE quotes (
   deadline INT NOT NULL,
   created_at TIMESTAMP NOT NULL DEFAULT now(),
   updated_at TIMESTAMP NOT NULL DEFAULT now()
-);
\ No newline at end of file
+);
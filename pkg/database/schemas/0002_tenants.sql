@@ -0,0 +1,20 @@
+-- Also run by the docker-compose init script before migrations were tracked.
+CREATE TABLE IF NOT EXISTS tenants (
+  id SERIAL PRIMARY KEY,
+  name VARCHAR(255) NOT NULL UNIQUE,
+  shipper_cnpj VARCHAR(14) NOT NULL,
+  shipper_token VARCHAR(255) NOT NULL,
+  platform_code VARCHAR(255) NOT NULL,
+  origin_zipcode INT NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT now(),
+  updated_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS api_keys (
+  id SERIAL PRIMARY KEY,
+  tenant_id INT NOT NULL REFERENCES tenants (id) ON DELETE CASCADE,
+  prefix VARCHAR(16) NOT NULL UNIQUE,
+  key_hash CHAR(64) NOT NULL UNIQUE,
+  created_at TIMESTAMP NOT NULL DEFAULT now(),
+  revoked_at TIMESTAMP
+);
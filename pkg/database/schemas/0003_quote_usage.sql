@@ -0,0 +1,10 @@
+-- Also run by the docker-compose init script before migrations were tracked.
+ALTER TABLE tenants ADD COLUMN IF NOT EXISTS monthly_quote_quota INT;
+
+CREATE TABLE IF NOT EXISTS quote_usage (
+  tenant_id INT NOT NULL REFERENCES tenants (id) ON DELETE CASCADE,
+  period DATE NOT NULL,
+  quotes INT NOT NULL DEFAULT 0,
+  updated_at TIMESTAMP NOT NULL DEFAULT now(),
+  PRIMARY KEY (tenant_id, period)
+);
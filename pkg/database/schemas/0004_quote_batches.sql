@@ -0,0 +1,23 @@
+CREATE TABLE quote_batches (
+  id SERIAL PRIMARY KEY,
+  tenant_id INT REFERENCES tenants (id) ON DELETE CASCADE,
+  total_items INT NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT now(),
+  completed_at TIMESTAMP
+);
+
+CREATE TABLE quote_batch_items (
+  id SERIAL PRIMARY KEY,
+  batch_id INT NOT NULL REFERENCES quote_batches (id) ON DELETE CASCADE,
+  position INT NOT NULL,
+  status VARCHAR(16) NOT NULL DEFAULT 'pending',
+  request JSONB NOT NULL,
+  response JSONB,
+  error TEXT,
+  attempts INT NOT NULL DEFAULT 0,
+  created_at TIMESTAMP NOT NULL DEFAULT now(),
+  updated_at TIMESTAMP NOT NULL DEFAULT now(),
+  UNIQUE (batch_id, position)
+);
+
+CREATE INDEX quote_batch_items_pending_idx ON quote_batch_items (id) WHERE status = 'pending';
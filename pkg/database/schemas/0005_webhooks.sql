@@ -0,0 +1,24 @@
+CREATE TABLE webhook_subscriptions (
+  id SERIAL PRIMARY KEY,
+  tenant_id INT REFERENCES tenants (id) ON DELETE CASCADE,
+  url TEXT NOT NULL,
+  secret VARCHAR(64) NOT NULL,
+  event_types TEXT[] NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE TABLE webhook_deliveries (
+  id SERIAL PRIMARY KEY,
+  subscription_id INT NOT NULL REFERENCES webhook_subscriptions (id) ON DELETE CASCADE,
+  event_type VARCHAR(64) NOT NULL,
+  payload JSONB NOT NULL,
+  status VARCHAR(16) NOT NULL DEFAULT 'pending',
+  attempts INT NOT NULL DEFAULT 0,
+  next_attempt_at TIMESTAMP NOT NULL DEFAULT now(),
+  last_status_code INT,
+  last_error TEXT,
+  created_at TIMESTAMP NOT NULL DEFAULT now(),
+  updated_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX webhook_deliveries_due_idx ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
@@ -0,0 +1,25 @@
+CREATE TABLE watched_routes (
+  id SERIAL PRIMARY KEY,
+  tenant_id INT REFERENCES tenants (id) ON DELETE CASCADE,
+  name VARCHAR(255) NOT NULL,
+  origin_zipcode INT NOT NULL,
+  destination_zipcode VARCHAR(8) NOT NULL,
+  volumes JSONB NOT NULL,
+  interval_seconds INT NOT NULL,
+  next_run_at TIMESTAMP NOT NULL DEFAULT now(),
+  last_run_at TIMESTAMP,
+  last_error TEXT,
+  created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE TABLE route_price_history (
+  id BIGSERIAL PRIMARY KEY,
+  route_id INT NOT NULL REFERENCES watched_routes (id) ON DELETE CASCADE,
+  carrier_name VARCHAR(255) NOT NULL,
+  service VARCHAR(255) NOT NULL,
+  price DECIMAL(10, 2) NOT NULL,
+  deadline INT NOT NULL,
+  quoted_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX route_price_history_route_idx ON route_price_history (route_id, quoted_at);
@@ -0,0 +1,8 @@
+CREATE TABLE quote_fees (
+  quote_id INT NOT NULL REFERENCES quotes (id) ON DELETE CASCADE,
+  fee VARCHAR(64) NOT NULL,
+  amount DECIMAL(10, 2) NOT NULL,
+  PRIMARY KEY (quote_id, fee)
+);
+
+CREATE INDEX quote_fees_fee_idx ON quote_fees (fee);
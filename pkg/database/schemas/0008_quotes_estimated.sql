@@ -0,0 +1 @@
+ALTER TABLE quotes ADD COLUMN estimated BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,13 @@
+ALTER TABLE quotes ADD COLUMN original_price DECIMAL(10, 2);
+UPDATE quotes SET original_price = price;
+ALTER TABLE quotes ALTER COLUMN original_price SET NOT NULL;
+
+CREATE TABLE quote_pricing_rules (
+  quote_id INT NOT NULL REFERENCES quotes (id) ON DELETE CASCADE,
+  step INT NOT NULL,
+  rule VARCHAR(255) NOT NULL,
+  price DECIMAL(10, 2) NOT NULL,
+  PRIMARY KEY (quote_id, step)
+);
+
+CREATE INDEX quote_pricing_rules_rule_idx ON quote_pricing_rules (rule);
@@ -0,0 +1,12 @@
+CREATE TABLE carrier_policies (
+  id SERIAL PRIMARY KEY,
+  name VARCHAR(255) NOT NULL,
+  action VARCHAR(16) NOT NULL,
+  carrier VARCHAR(255) NOT NULL DEFAULT '',
+  service VARCHAR(255) NOT NULL DEFAULT '',
+  uf VARCHAR(2) NOT NULL DEFAULT '',
+  zipcode_start INT NOT NULL DEFAULT 0,
+  zipcode_end INT NOT NULL DEFAULT 0,
+  category INT NOT NULL DEFAULT 0,
+  created_at TIMESTAMP NOT NULL DEFAULT now()
+);
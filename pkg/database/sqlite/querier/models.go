@@ -0,0 +1,34 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+
+package sqlitequerier
+
+import (
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/money"
+)
+
+type Quote struct {
+	ID            int
+	CarrierName   string
+	Service       string
+	Price         money.Money
+	Deadline      int
+	CreatedAt     string
+	UpdatedAt     string
+	Estimated     bool
+	OriginalPrice money.Money
+}
+
+type QuoteFee struct {
+	QuoteID int
+	Fee     string
+	Amount  money.Money
+}
+
+type QuotePricingRule struct {
+	QuoteID int
+	Step    int
+	Rule    string
+	Price   money.Money
+}
@@ -0,0 +1,128 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: quotes.sql
+
+package sqlitequerier
+
+import (
+	"context"
+
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/money"
+)
+
+const createQuote = `-- name: CreateQuote :one
+INSERT INTO quotes (carrier_name, service, price, deadline, estimated, original_price)
+VALUES (?, ?, ?, ?, ?, ?)
+RETURNING id, carrier_name, service, price, deadline, created_at, updated_at, estimated, original_price
+`
+
+type CreateQuoteParams struct {
+	CarrierName   string
+	Service       string
+	Price         money.Money
+	Deadline      int
+	Estimated     bool
+	OriginalPrice money.Money
+}
+
+func (q *Queries) CreateQuote(ctx context.Context, arg CreateQuoteParams) (Quote, error) {
+	row := q.db.QueryRowContext(ctx, createQuote,
+		arg.CarrierName,
+		arg.Service,
+		arg.Price,
+		arg.Deadline,
+		arg.Estimated,
+		arg.OriginalPrice,
+	)
+	var i Quote
+	err := row.Scan(
+		&i.ID,
+		&i.CarrierName,
+		&i.Service,
+		&i.Price,
+		&i.Deadline,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Estimated,
+		&i.OriginalPrice,
+	)
+	return i, err
+}
+
+const createQuoteFee = `-- name: CreateQuoteFee :exec
+INSERT INTO quote_fees (quote_id, fee, amount)
+VALUES (?, ?, ?)
+`
+
+type CreateQuoteFeeParams struct {
+	QuoteID int
+	Fee     string
+	Amount  money.Money
+}
+
+func (q *Queries) CreateQuoteFee(ctx context.Context, arg CreateQuoteFeeParams) error {
+	_, err := q.db.ExecContext(ctx, createQuoteFee, arg.QuoteID, arg.Fee, arg.Amount)
+	return err
+}
+
+const createQuotePricingRule = `-- name: CreateQuotePricingRule :exec
+INSERT INTO quote_pricing_rules (quote_id, step, rule, price)
+VALUES (?, ?, ?, ?)
+`
+
+type CreateQuotePricingRuleParams struct {
+	QuoteID int
+	Step    int
+	Rule    string
+	Price   money.Money
+}
+
+func (q *Queries) CreateQuotePricingRule(ctx context.Context, arg CreateQuotePricingRuleParams) error {
+	_, err := q.db.ExecContext(ctx, createQuotePricingRule,
+		arg.QuoteID,
+		arg.Step,
+		arg.Rule,
+		arg.Price,
+	)
+	return err
+}
+
+const findLastQuotes = `-- name: FindLastQuotes :many
+SELECT id, carrier_name, service, price, deadline, created_at, updated_at, estimated, original_price FROM quotes
+ORDER BY created_at DESC, id DESC
+LIMIT ?1
+`
+
+func (q *Queries) FindLastQuotes(ctx context.Context, limitQuotes int64) ([]Quote, error) {
+	rows, err := q.db.QueryContext(ctx, findLastQuotes, limitQuotes)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Quote
+	for rows.Next() {
+		var i Quote
+		if err := rows.Scan(
+			&i.ID,
+			&i.CarrierName,
+			&i.Service,
+			&i.Price,
+			&i.Deadline,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Estimated,
+			&i.OriginalPrice,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,18 @@
+-- name: CreateQuote :one
+INSERT INTO quotes (carrier_name, service, price, deadline, estimated, original_price)
+VALUES (?, ?, ?, ?, ?, ?)
+RETURNING *;
+
+-- name: FindLastQuotes :many
+SELECT * FROM quotes
+ORDER BY created_at DESC, id DESC
+LIMIT @limit_quotes;
+
+
+-- name: CreateQuoteFee :exec
+INSERT INTO quote_fees (quote_id, fee, amount)
+VALUES (?, ?, ?);
+
+-- name: CreateQuotePricingRule :exec
+INSERT INTO quote_pricing_rules (quote_id, step, rule, price)
+VALUES (?, ?, ?, ?);
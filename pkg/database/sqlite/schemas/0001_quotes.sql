@@ -0,0 +1,11 @@
+CREATE TABLE quotes (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  carrier_name TEXT NOT NULL,
+  service TEXT NOT NULL,
+  price TEXT NOT NULL,
+  deadline INTEGER NOT NULL,
+  created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+  updated_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+);
+
+CREATE INDEX quotes_created_at_idx ON quotes (created_at);
@@ -0,0 +1,8 @@
+CREATE TABLE quote_fees (
+  quote_id INTEGER NOT NULL REFERENCES quotes (id) ON DELETE CASCADE,
+  fee TEXT NOT NULL,
+  amount TEXT NOT NULL,
+  PRIMARY KEY (quote_id, fee)
+);
+
+CREATE INDEX quote_fees_fee_idx ON quote_fees (fee);
@@ -0,0 +1,12 @@
+ALTER TABLE quotes ADD COLUMN original_price TEXT NOT NULL DEFAULT '0.00';
+UPDATE quotes SET original_price = price;
+
+CREATE TABLE quote_pricing_rules (
+  quote_id INTEGER NOT NULL REFERENCES quotes (id) ON DELETE CASCADE,
+  step INTEGER NOT NULL,
+  rule TEXT NOT NULL,
+  price TEXT NOT NULL,
+  PRIMARY KEY (quote_id, step)
+);
+
+CREATE INDEX quote_pricing_rules_rule_idx ON quote_pricing_rules (rule);
@@ -0,0 +1,52 @@
+package database_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/config"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/database"
+)
+
+// As migrações do SQLite devem ser aplicadas uma única vez
+func TestMigrateSQLite_IsIdempotent(t *testing.T) {
+	db, err := database.NewSQLiteConnection(&config.Config{
+		SQLitePath: filepath.Join(t.TempDir(), "data", "quotes.db"),
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+
+	version, err := database.SQLiteSchemaVersion(ctx, db)
+	if err != nil || version != 0 {
+		t.Fatalf("Expected version 0 before migrating, got: %d (%v)", version, err)
+	}
+
+	for range 2 {
+		if err := database.MigrateSQLite(ctx, db); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+	}
+
+	version, err = database.SQLiteSchemaVersion(ctx, db)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if latest := database.LatestSQLiteSchemaVersion(); latest == 0 || version != latest {
+		t.Errorf("Expected version %d, got: %d", latest, version)
+	}
+
+	var applied int
+	if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM schema_migrations").Scan(&applied); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if applied != version {
+		t.Errorf("Expected %d recorded migrations, got: %d", version, applied)
+	}
+}
@@ -0,0 +1,277 @@
+package fakefastdelivery
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/fastdelivery_api/models"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/freightmath"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/money"
+)
+
+const (
+	SimulatePath = "/api/v3/quote/simulate"
+
+	ScenarioHeader = "X-Fake-Scenario"
+	LatencyHeader  = "X-Fake-Latency"
+)
+
+type Scenario string
+
+const (
+	ScenarioOK          Scenario = "ok"
+	ScenarioLatency     Scenario = "latency"
+	ScenarioServerError Scenario = "server_error"
+	ScenarioMalformed   Scenario = "malformed"
+	ScenarioEmpty       Scenario = "empty"
+	ScenarioExpired     Scenario = "expired"
+)
+
+var Scenarios = []Scenario{ScenarioOK, ScenarioLatency, ScenarioServerError, ScenarioMalformed, ScenarioEmpty, ScenarioExpired}
+
+type Config struct {
+	Scenario Scenario
+	Latency  time.Duration
+}
+
+type carrier struct {
+	name        string
+	cnpj        string
+	service     string
+	code        string
+	base        float64
+	perKilogram float64
+	days        int
+	modal       string
+}
+
+var carriers = []carrier{
+	{name: "CORREIOS", cnpj: "34028316000103", service: "PAC", code: "03298", base: 18.9, perKilogram: 2.1, days: 5, modal: "Rodoviário"},
+	{name: "CORREIOS", cnpj: "34028316000103", service: "SEDEX", code: "03220", base: 27.5, perKilogram: 3.4, days: 2, modal: "Aéreo"},
+	{name: "JADLOG", cnpj: "04884082000135", service: ".PACKAGE", code: "3", base: 21.3, perKilogram: 1.8, days: 4, modal: "Rodoviário"},
+	{name: "AZUL CARGO", cnpj: "09296295000160", service: "Amanhã", code: "AM", base: 35.0, perKilogram: 4.2, days: 1, modal: "Aéreo"},
+}
+
+// Server is a stand-in for the Frete Rápido quote simulation endpoint. Offers
+// are derived only from the request, so the same request always gets the
+// same response.
+type Server struct {
+	cfg Config
+	mux *http.ServeMux
+	now func() time.Time
+}
+
+func New(cfg Config) *Server {
+	if cfg.Scenario == "" {
+		cfg.Scenario = ScenarioOK
+	}
+
+	s := &Server{cfg: cfg, mux: http.NewServeMux(), now: time.Now}
+	s.mux.HandleFunc("POST "+SimulatePath, s.simulate)
+
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) simulate(w http.ResponseWriter, r *http.Request) {
+	scenario := s.cfg.Scenario
+	if value := r.Header.Get(ScenarioHeader); value != "" {
+		scenario = Scenario(value)
+	}
+
+	var latency time.Duration
+	if scenario == ScenarioLatency {
+		latency = s.cfg.Latency
+	}
+	if value := r.Header.Get(LatencyHeader); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid %s header: %v", LatencyHeader, err))
+			return
+		}
+		latency = parsed
+	}
+
+	slog.Info("fake quote simulation", "scenario", scenario, "latency", latency)
+
+	// An explicit latency header delays any scenario, e.g. a slow 5xx.
+	if latency > 0 {
+		select {
+		case <-time.After(latency):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	var req models.QuoteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if msg := validate(req); msg != "" {
+		writeError(w, http.StatusBadRequest, msg)
+		return
+	}
+
+	switch scenario {
+	case ScenarioOK, ScenarioLatency, ScenarioExpired:
+	case ScenarioServerError:
+		writeError(w, http.StatusInternalServerError, "internal server error")
+		return
+	case ScenarioMalformed:
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"dispatchers": [{"offers": [`))
+		return
+	case ScenarioEmpty:
+		writeJSON(w, http.StatusOK, models.QuoteResponse{Dispatchers: []models.DispatcherResponse{}})
+		return
+	default:
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("unknown scenario %q", scenario))
+		return
+	}
+
+	response := s.quote(req)
+	if scenario == ScenarioExpired {
+		for i := range response.Dispatchers {
+			for j := range response.Dispatchers[i].Offers {
+				response.Dispatchers[i].Offers[j].Expiration = s.now().Add(-24 * time.Hour).UTC()
+			}
+		}
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+func validate(req models.QuoteRequest) string {
+	switch {
+	case req.Shipper.RegisteredNumber == "" || req.Shipper.Token == "":
+		return "shipper registered_number and token are required"
+	case req.Recipient.Zipcode == 0:
+		return "recipient zipcode is required"
+	case len(req.Dispatchers) == 0:
+		return "at least one dispatcher is required"
+	}
+
+	for _, d := range req.Dispatchers {
+		if d.Zipcode == 0 || len(d.Volumes) == 0 {
+			return "every dispatcher needs a zipcode and volumes"
+		}
+	}
+
+	return ""
+}
+
+func (s *Server) quote(req models.QuoteRequest) models.QuoteResponse {
+	response := models.QuoteResponse{Dispatchers: make([]models.DispatcherResponse, len(req.Dispatchers))}
+
+	for i, d := range req.Dispatchers {
+		var invoice money.Money
+		items := make([]freightmath.Item, len(d.Volumes))
+		for j, v := range d.Volumes {
+			invoice = invoice.Add(v.UnitaryPrice.Mul(int64(max(v.Amount, 1))))
+			items[j] = freightmath.Item{
+				Amount:        v.Amount,
+				UnitaryWeight: v.UnitaryWeight,
+				Height:        v.Height,
+				Width:         v.Width,
+				Length:        v.Length,
+			}
+		}
+		weights := freightmath.Compute(items, freightmath.DefaultCubageFactor)
+
+		// Zipcode regions far apart cost more and take longer.
+		distance := math.Abs(float64(d.Zipcode/1000000 - req.Recipient.Zipcode/1000000))
+
+		dispatcher := models.DispatcherResponse{
+			ID:                         digest(req.Recipient.Zipcode, d),
+			RequestID:                  digest(req.Recipient.Zipcode, d, "request"),
+			RegisteredNumberShipper:    req.Shipper.RegisteredNumber,
+			RegisteredNumberDispatcher: d.RegisteredNumber,
+			ZipcodeOrigin:              d.Zipcode,
+			Offers:                     make([]models.Offer, len(carriers)),
+		}
+
+		for j, c := range carriers {
+			freight := money.FromFloat(c.base + weights.Billed*c.perKilogram*(1+distance/20))
+			adValorem := invoice.MulRatio(3, 1000)
+			gris := invoice.MulRatio(2, 1000)
+			variation := money.FromCents(int64(hash(req.Recipient.Zipcode, d, c.name, c.service) % 500))
+			cost := freight.Add(adValorem).Add(gris).Add(variation)
+			days := c.days + int(distance/3)
+
+			dispatcher.Offers[j] = models.Offer{
+				Offer:              j + 1,
+				SimulationType:     0,
+				Carrier:            models.Carrier{Reference: 281 + j, Name: c.name, RegisteredNumber: c.cnpj},
+				Service:            c.service,
+				ServiceCode:        c.code,
+				ServiceDescription: c.service,
+				DeliveryTime:       models.DeliveryTime{Days: days},
+				OriginalDeliveryTime: models.DeliveryTime{
+					Days: days,
+				},
+				Expiration: s.now().Add(72 * time.Hour).UTC().Truncate(time.Second),
+				CostPrice:  cost,
+				FinalPrice: cost,
+				Weights: models.Weights{
+					Real:  weights.Real,
+					Cubed: weights.Cubed,
+					Used:  weights.Billed,
+				},
+				HomeDelivery: true,
+				Modal:        c.modal,
+			}
+
+			// Like the real API, the breakdown is only sent when asked for.
+			// Its charges always add up to the final price.
+			if req.Returns.Composition {
+				dispatcher.Offers[j].Composition = models.Composition{
+					FreightWeight: freight,
+					SubTotal1: models.SubTotal1{
+						AdValorem: adValorem,
+						Gris:      gris,
+						Other:     variation,
+					},
+				}
+			}
+		}
+
+		response.Dispatchers[i] = dispatcher
+	}
+
+	return response
+}
+
+func hash(parts ...any) uint32 {
+	h := fnv.New32a()
+	for _, p := range parts {
+		fmt.Fprintf(h, "%v|", p)
+	}
+
+	return h.Sum32()
+}
+
+func digest(parts ...any) string {
+	return strconv.FormatUint(uint64(hash(parts...)), 16)
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
@@ -0,0 +1,271 @@
+package fakefastdelivery_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/config"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/fakefastdelivery"
+	fastdeliveryapi "github.com/jeancarloshp/desafio-frete-rapido/pkg/fastdelivery_api"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/fastdelivery_api/models"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/money"
+)
+
+func quoteRequest() models.QuoteRequest {
+	return models.QuoteRequest{
+		Shipper: models.Shipper{
+			RegisteredNumber: "25438296000158",
+			Token:            "1d52a9b6b78cf07b08586152459a5c90",
+			PlatformCode:     "5AKVkHqCn",
+		},
+		Recipient: models.Recipient{Country: "BRA", Zipcode: 1311000},
+		Dispatchers: []models.Dispatcher{
+			{
+				RegisteredNumber: "25438296000158",
+				Zipcode:          29161376,
+				Volumes: []models.Volume{
+					{Amount: 2, Category: "7", UnitaryPrice: money.FromCents(10000), UnitaryWeight: 5, Height: 0.2, Width: 0.2, Length: 0.2},
+				},
+			},
+		},
+		SimulationType: []int{0},
+	}
+}
+
+func newClient(t *testing.T, cfg fakefastdelivery.Config) *fastdeliveryapi.FastDeliveryAPI {
+	t.Helper()
+
+	srv := httptest.NewServer(fakefastdelivery.New(cfg))
+	t.Cleanup(srv.Close)
+
+	return fastdeliveryapi.New(&config.Config{
+		FastDeliveryAPIBaseURL:         srv.URL + "/api/v3",
+		FastDeliveryAPITimeout:         time.Second,
+		FastDeliveryAPIBreakerFailures: 100,
+		FastDeliveryAPIBreakerCooldown: time.Minute,
+		FastDeliveryAPIMaxConcurrency:  4,
+	})
+}
+
+// O cliente real deve conseguir decodificar e validar a resposta do servidor falso
+func TestSimulate_ResponseMatchesClientSchema(t *testing.T) {
+	api := newClient(t, fakefastdelivery.Config{})
+
+	resp, err := api.SimulateQuote(context.Background(), quoteRequest())
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if len(resp.Dispatchers) != 1 {
+		t.Fatalf("Expected 1 dispatcher, got: %d", len(resp.Dispatchers))
+	}
+
+	offers := resp.Dispatchers[0].Offers
+	if len(offers) == 0 {
+		t.Fatal("Expected offers, got none")
+	}
+
+	for _, offer := range offers {
+		if offer.FinalPrice <= 0 || offer.DeliveryTime.Days <= 0 {
+			t.Errorf("Expected positive price and deadline, got: %v and %d", offer.FinalPrice, offer.DeliveryTime.Days)
+		}
+		if !offer.Expiration.After(time.Now()) {
+			t.Errorf("Expected offer to expire in the future, got: %v", offer.Expiration)
+		}
+	}
+}
+
+// A mesma requisição deve gerar sempre as mesmas ofertas
+func TestSimulate_IsDeterministic(t *testing.T) {
+	api := newClient(t, fakefastdelivery.Config{})
+
+	first, err := api.SimulateQuote(context.Background(), quoteRequest())
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	second, err := api.SimulateQuote(context.Background(), quoteRequest())
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	for i := range first.Dispatchers[0].Offers {
+		a, b := first.Dispatchers[0].Offers[i], second.Dispatchers[0].Offers[i]
+		a.Expiration, b.Expiration = time.Time{}, time.Time{}
+		if !reflect.DeepEqual(a, b) {
+			t.Errorf("Expected identical offers, got: %+v and %+v", a, b)
+		}
+	}
+
+	other := quoteRequest()
+	other.Dispatchers[0].Volumes[0].UnitaryWeight = 50
+
+	heavier, err := api.SimulateQuote(context.Background(), other)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if heavier.Dispatchers[0].Offers[0].FinalPrice <= first.Dispatchers[0].Offers[0].FinalPrice {
+		t.Errorf("Expected heavier volumes to cost more, got: %v and %v",
+			heavier.Dispatchers[0].Offers[0].FinalPrice, first.Dispatchers[0].Offers[0].FinalPrice)
+	}
+}
+
+// A composição só vem quando pedida, e suas taxas somam o preço final
+func TestSimulate_Composition(t *testing.T) {
+	api := newClient(t, fakefastdelivery.Config{})
+
+	plain, err := api.SimulateQuote(context.Background(), quoteRequest())
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if c := plain.Dispatchers[0].Offers[0].Composition; c != (models.Composition{}) {
+		t.Errorf("Expected no composition unless requested, got: %+v", c)
+	}
+
+	request := quoteRequest()
+	request.Returns.Composition = true
+
+	detailed, err := api.SimulateQuote(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	for _, offer := range detailed.Dispatchers[0].Offers {
+		c := offer.Composition
+		total := c.FreightWeight.Add(c.SubTotal1.AdValorem).Add(c.SubTotal1.Gris).Add(c.SubTotal1.Other)
+		if total != offer.FinalPrice {
+			t.Errorf("Expected fees to add up to %s, got: %s", offer.FinalPrice, total)
+		}
+	}
+}
+
+// Cada cenário pode ser escolhido pela configuração ou pelo header
+func TestSimulate_Scenarios(t *testing.T) {
+	tests := []struct {
+		name       string
+		cfg        fakefastdelivery.Config
+		header     string
+		wantStatus int
+		check      func(t *testing.T, body []byte)
+	}{
+		{
+			name:       "server error from header",
+			header:     string(fakefastdelivery.ScenarioServerError),
+			wantStatus: http.StatusInternalServerError,
+		},
+		{
+			name:       "malformed from config",
+			cfg:        fakefastdelivery.Config{Scenario: fakefastdelivery.ScenarioMalformed},
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				if json.Valid(body) {
+					t.Errorf("Expected malformed JSON, got: %s", body)
+				}
+			},
+		},
+		{
+			name:       "empty dispatchers",
+			header:     string(fakefastdelivery.ScenarioEmpty),
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				var resp models.QuoteResponse
+				if err := json.Unmarshal(body, &resp); err != nil {
+					t.Fatalf("Expected valid JSON, got: %v", err)
+				}
+				if len(resp.Dispatchers) != 0 {
+					t.Errorf("Expected no dispatchers, got: %d", len(resp.Dispatchers))
+				}
+			},
+		},
+		{
+			name:       "expired offers",
+			header:     string(fakefastdelivery.ScenarioExpired),
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				var resp models.QuoteResponse
+				if err := json.Unmarshal(body, &resp); err != nil {
+					t.Fatalf("Expected valid JSON, got: %v", err)
+				}
+				for _, offer := range resp.Dispatchers[0].Offers {
+					if offer.Expiration.After(time.Now()) {
+						t.Errorf("Expected expired offer, got: %v", offer.Expiration)
+					}
+				}
+			},
+		},
+		{
+			name:       "header overrides config",
+			cfg:        fakefastdelivery.Config{Scenario: fakefastdelivery.ScenarioServerError},
+			header:     string(fakefastdelivery.ScenarioOK),
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "unknown scenario",
+			header:     "flaky",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, _ := json.Marshal(quoteRequest())
+			req := httptest.NewRequest(http.MethodPost, fakefastdelivery.SimulatePath, bytes.NewReader(body))
+			if tt.header != "" {
+				req.Header.Set(fakefastdelivery.ScenarioHeader, tt.header)
+			}
+
+			rec := httptest.NewRecorder()
+			fakefastdelivery.New(tt.cfg).ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("Expected status %d, got: %d", tt.wantStatus, rec.Code)
+			}
+			if tt.check != nil {
+				tt.check(t, rec.Body.Bytes())
+			}
+		})
+	}
+}
+
+// O cenário de latência deve atrasar a resposta e respeitar o timeout do cliente
+func TestSimulate_LatencyScenario(t *testing.T) {
+	api := newClient(t, fakefastdelivery.Config{
+		Scenario: fakefastdelivery.ScenarioLatency,
+		Latency:  50 * time.Millisecond,
+	})
+
+	start := time.Now()
+	if _, err := api.SimulateQuote(context.Background(), quoteRequest()); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("Expected at least 50ms of latency, got: %v", elapsed)
+	}
+
+	api.SetTimeout(10 * time.Millisecond)
+	if _, err := api.SimulateQuote(context.Background(), quoteRequest()); err == nil {
+		t.Error("Expected timeout error, got: nil")
+	}
+}
+
+// Requisições sem os campos obrigatórios são rejeitadas como na API real
+func TestSimulate_RejectsInvalidRequest(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, fakefastdelivery.SimulatePath, strings.NewReader(`{"dispatchers":[]}`))
+	rec := httptest.NewRecorder()
+
+	fakefastdelivery.New(fakefastdelivery.Config{}).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got: %d", rec.Code)
+	}
+}
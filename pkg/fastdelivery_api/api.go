@@ -2,39 +2,141 @@ package fastdeliveryapi
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/jeancarloshp/desafio-frete-rapido/pkg/config"
 	"github.com/jeancarloshp/desafio-frete-rapido/pkg/fastdelivery_api/models"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/money"
 )
 
+// loggedResponseBytes bounds how much of a failed response is logged.
+const loggedResponseBytes = 1024
+
+// unavailableError marks failures of the Frete Rápido API itself: transport
+// errors, timeouts and 5xx responses. Only these count towards opening the
+// circuit breaker, since rejected requests and unreadable responses come from
+// an API that is up.
+type unavailableError struct {
+	err error
+}
+
+func (e unavailableError) Error() string {
+	return e.err.Error()
+}
+
+func (e unavailableError) Unwrap() error {
+	return e.err
+}
+
+func isUnavailable(err error) bool {
+	var unavailable unavailableError
+	return errors.As(err, &unavailable)
+}
+
 type FastDeliveryAPI struct {
-	cfg    *config.Config
-	client *http.Client
+	cfg     *config.Config
+	client  *http.Client
+	timeout atomic.Int64
+	breaker *breaker
+	slots   chan struct{}
 }
 
 func New(cfg *config.Config) *FastDeliveryAPI {
-	return &FastDeliveryAPI{
-		cfg: cfg,
-		client: &http.Client{
-			Timeout: 10 * time.Second,
-		},
+	api := &FastDeliveryAPI{
+		cfg:     cfg,
+		client:  &http.Client{},
+		breaker: newBreaker(cfg.FastDeliveryAPIBreakerFailures, cfg.FastDeliveryAPIBreakerCooldown),
+		slots:   make(chan struct{}, max(cfg.FastDeliveryAPIMaxConcurrency, 1)),
+	}
+	api.SetTimeout(cfg.FastDeliveryAPITimeout)
+
+	return api
+}
+
+func (api *FastDeliveryAPI) SetTimeout(timeout time.Duration) {
+	api.timeout.Store(int64(timeout))
+}
+
+func (api *FastDeliveryAPI) BreakerState() BreakerState {
+	return api.breaker.State()
+}
+
+func (api *FastDeliveryAPI) SimulateQuote(ctx context.Context, quoteRequest models.QuoteRequest) (*models.QuoteResponse, error) {
+	if err := api.breaker.allow(); err != nil {
+		return nil, err
+	}
+
+	select {
+	case api.slots <- struct{}{}:
+		defer func() { <-api.slots }()
+	case <-ctx.Done():
+		api.breaker.release()
+		return nil, ctx.Err()
+	}
+
+	quoteResponse, err := api.simulateQuote(ctx, quoteRequest)
+	switch {
+	case err != nil && ctx.Err() != nil:
+		api.breaker.release()
+		return nil, err
+	case isUnavailable(err):
+		api.breaker.record(err)
+	default:
+		api.breaker.record(nil)
 	}
+
+	return quoteResponse, err
+}
+
+// Probe sends the smallest possible simulation with the configured shipper
+// credentials, so rejected credentials and upstream outages are noticed
+// before a client quote fails.
+func (api *FastDeliveryAPI) Probe(ctx context.Context) error {
+	_, err := api.SimulateQuote(ctx, models.QuoteRequest{
+		Shipper: models.Shipper{
+			RegisteredNumber: api.cfg.FastDeliveryAPISenderCNPJ,
+			Token:            api.cfg.FastDeliveryAPIToken,
+			PlatformCode:     api.cfg.FastDeliveryAPIPlatformCode,
+		},
+		Recipient: models.Recipient{
+			Country: "BRA",
+			Zipcode: api.cfg.FastDeliveryAPIZipCode,
+		},
+		Dispatchers: []models.Dispatcher{
+			{
+				RegisteredNumber: api.cfg.FastDeliveryAPISenderCNPJ,
+				Zipcode:          api.cfg.FastDeliveryAPIZipCode,
+				Volumes: []models.Volume{
+					{Amount: 1, Category: "7", UnitaryPrice: money.FromCents(100), UnitaryWeight: 1, Height: 0.1, Width: 0.1, Length: 0.1},
+				},
+			},
+		},
+		SimulationType: []int{0},
+	})
+
+	return err
 }
 
-func (api *FastDeliveryAPI) SimulateQuote(quoteRequest models.QuoteRequest) (*models.QuoteResponse, error) {
+func (api *FastDeliveryAPI) simulateQuote(ctx context.Context, quoteRequest models.QuoteRequest) (*models.QuoteResponse, error) {
 	body, err := json.Marshal(quoteRequest)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(api.timeout.Load()))
+	defer cancel()
+
 	url := fmt.Sprintf("%s/quote/simulate", api.cfg.FastDeliveryAPIBaseURL)
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(body))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(body))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -43,23 +145,28 @@ func (api *FastDeliveryAPI) SimulateQuote(quoteRequest models.QuoteRequest) (*mo
 
 	slog.Debug("sending quote simulation request",
 		"url", url,
-		"body", string(body),
+		"body", redactedBody(quoteRequest),
 		"headers", req.Header,
 	)
 
 	resp, err := api.client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
+		return nil, unavailableError{fmt.Errorf("request failed: %w", err)}
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
+		responseBody, _ := io.ReadAll(io.LimitReader(resp.Body, loggedResponseBytes))
 		slog.Error("failed to get quote simulation",
 			"status_code", resp.StatusCode,
-			"body", string(body),
-			"response_body", resp.Body,
+			"body", redactedBody(quoteRequest),
+			"response_body", string(responseBody),
 		)
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		err := fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		if resp.StatusCode >= http.StatusInternalServerError {
+			return nil, unavailableError{err}
+		}
+		return nil, err
 	}
 
 	var quoteResponse models.QuoteResponse
@@ -74,3 +181,18 @@ func (api *FastDeliveryAPI) SimulateQuote(quoteRequest models.QuoteRequest) (*mo
 
 	return &quoteResponse, nil
 }
+
+// redactedBody is the request body for the logs. Shipper tokens belong to
+// each tenant and are never logged.
+func redactedBody(quoteRequest models.QuoteRequest) string {
+	if quoteRequest.Shipper.Token != "" {
+		quoteRequest.Shipper.Token = "[REDACTED]"
+	}
+
+	body, err := json.Marshal(quoteRequest)
+	if err != nil {
+		return ""
+	}
+
+	return string(body)
+}
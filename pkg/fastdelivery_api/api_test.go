@@ -0,0 +1,174 @@
+package fastdeliveryapi_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/config"
+	fastdeliveryapi "github.com/jeancarloshp/desafio-frete-rapido/pkg/fastdelivery_api"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/fastdelivery_api/models"
+)
+
+func TestSimulateQuote_LimitsUpstreamConcurrency(t *testing.T) {
+	var inFlight, peak atomic.Int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := inFlight.Add(1)
+		defer inFlight.Add(-1)
+
+		for {
+			p := peak.Load()
+			if current <= p || peak.CompareAndSwap(p, current) {
+				break
+			}
+		}
+
+		time.Sleep(20 * time.Millisecond)
+		w.Write([]byte(`{"dispatchers":[]}`))
+	}))
+	defer upstream.Close()
+
+	api := fastdeliveryapi.New(&config.Config{
+		FastDeliveryAPIBaseURL:         upstream.URL,
+		FastDeliveryAPITimeout:         time.Second,
+		FastDeliveryAPIBreakerFailures: 5,
+		FastDeliveryAPIBreakerCooldown: time.Minute,
+		FastDeliveryAPIMaxConcurrency:  2,
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := api.SimulateQuote(context.Background(), models.QuoteRequest{}); err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if peak.Load() > 2 {
+		t.Errorf("Expected at most 2 concurrent upstream requests, got: %d", peak.Load())
+	}
+}
+
+func TestSimulateQuote_BreakerCountsOnlyUpstreamFailures(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		body      string
+		wantState fastdeliveryapi.BreakerState
+	}{
+		{name: "credenciais rejeitadas", status: http.StatusBadRequest, body: `{"error":"invalid token"}`, wantState: fastdeliveryapi.BreakerClosed},
+		{name: "resposta ilegível", status: http.StatusOK, body: `{"dispatchers":`, wantState: fastdeliveryapi.BreakerClosed},
+		{name: "indisponibilidade", status: http.StatusServiceUnavailable, body: `{}`, wantState: fastdeliveryapi.BreakerOpen},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer upstream.Close()
+
+			api := fastdeliveryapi.New(&config.Config{
+				FastDeliveryAPIBaseURL:         upstream.URL,
+				FastDeliveryAPITimeout:         time.Second,
+				FastDeliveryAPIBreakerFailures: 2,
+				FastDeliveryAPIBreakerCooldown: time.Minute,
+				FastDeliveryAPIMaxConcurrency:  1,
+			})
+
+			for range 5 {
+				_, err := api.SimulateQuote(context.Background(), models.QuoteRequest{})
+				if err == nil {
+					t.Fatal("Expected an error")
+				}
+			}
+
+			if api.BreakerState() != tt.wantState {
+				t.Errorf("Expected breaker %s, got: %s", tt.wantState, api.BreakerState())
+			}
+
+			// Com o circuito fechado, a API continua sendo chamada
+			_, err := api.SimulateQuote(context.Background(), models.QuoteRequest{})
+			if opened := errors.Is(err, fastdeliveryapi.ErrCircuitOpen); opened != (tt.wantState == fastdeliveryapi.BreakerOpen) {
+				t.Errorf("Expected ErrCircuitOpen only when open, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestSimulateQuote_TransportErrorsOpenBreaker(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	upstream.Close()
+
+	api := fastdeliveryapi.New(&config.Config{
+		FastDeliveryAPIBaseURL:         upstream.URL,
+		FastDeliveryAPITimeout:         time.Second,
+		FastDeliveryAPIBreakerFailures: 2,
+		FastDeliveryAPIBreakerCooldown: time.Minute,
+		FastDeliveryAPIMaxConcurrency:  1,
+	})
+
+	for range 2 {
+		api.SimulateQuote(context.Background(), models.QuoteRequest{})
+	}
+
+	if api.BreakerState() != fastdeliveryapi.BreakerOpen {
+		t.Errorf("Expected breaker open after connection failures, got: %s", api.BreakerState())
+	}
+}
+
+// O token do embarcador nunca vai para os logs, e o corpo da resposta de erro
+// é registrado como texto
+func TestSimulateQuote_RedactsShipperTokenInLogs(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":"invalid zipcode"}` + strings.Repeat(" ", 4096)))
+	}))
+	defer upstream.Close()
+
+	var logs bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	defer slog.SetDefault(previous)
+
+	api := fastdeliveryapi.New(&config.Config{
+		FastDeliveryAPIBaseURL:         upstream.URL,
+		FastDeliveryAPITimeout:         time.Second,
+		FastDeliveryAPIBreakerFailures: 5,
+		FastDeliveryAPIBreakerCooldown: time.Minute,
+	})
+
+	token := "1d52a9b6b78cf07b08586152459a5c90"
+	if _, err := api.SimulateQuote(context.Background(), models.QuoteRequest{Shipper: models.Shipper{Token: token}}); err == nil {
+		t.Fatal("Expected an error for status 400")
+	}
+
+	if strings.Contains(logs.String(), token) {
+		t.Errorf("Expected the shipper token to be redacted, got: %s", logs.String())
+	}
+
+	if strings.Count(logs.String(), "[REDACTED]") != 2 {
+		t.Errorf("Expected both request logs to be redacted, got: %s", logs.String())
+	}
+
+	if !strings.Contains(logs.String(), "invalid zipcode") {
+		t.Errorf("Expected the response body to be logged, got: %s", logs.String())
+	}
+
+	if len(logs.String()) > 4096 {
+		t.Errorf("Expected the logged response body to be bounded, got %d bytes of logs", len(logs.String()))
+	}
+}
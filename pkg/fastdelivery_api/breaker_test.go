@@ -0,0 +1,84 @@
+package fastdeliveryapi
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func newTestBreaker(threshold int, cooldown time.Duration) (*breaker, *time.Time) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	b := newBreaker(threshold, cooldown)
+	b.now = func() time.Time { return now }
+
+	return b, &now
+}
+
+func TestBreaker_OpensAfterConsecutiveFailures(t *testing.T) {
+	b, _ := newTestBreaker(3, time.Minute)
+	failure := errors.New("unexpected status code: 502")
+
+	for i := 0; i < 3; i++ {
+		if err := b.allow(); err != nil {
+			t.Fatalf("Expected request %d to be allowed, got: %v", i+1, err)
+		}
+		b.record(failure)
+	}
+
+	if b.State() != BreakerOpen {
+		t.Fatalf("Expected breaker open, got: %s", b.State())
+	}
+
+	if err := b.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Expected ErrCircuitOpen, got: %v", err)
+	}
+}
+
+func TestBreaker_SuccessResetsFailures(t *testing.T) {
+	b, _ := newTestBreaker(2, time.Minute)
+
+	b.record(errors.New("timeout"))
+	b.record(nil)
+	b.record(errors.New("timeout"))
+
+	if b.State() != BreakerClosed {
+		t.Errorf("Expected breaker closed, got: %s", b.State())
+	}
+}
+
+func TestBreaker_HalfOpenAllowsSingleTrial(t *testing.T) {
+	b, now := newTestBreaker(1, time.Minute)
+	b.record(errors.New("timeout"))
+
+	*now = now.Add(time.Minute)
+
+	if b.State() != BreakerHalfOpen {
+		t.Fatalf("Expected breaker half-open, got: %s", b.State())
+	}
+
+	if err := b.allow(); err != nil {
+		t.Fatalf("Expected trial request to be allowed, got: %v", err)
+	}
+	if err := b.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Expected concurrent request to be rejected during the trial, got: %v", err)
+	}
+
+	b.record(nil)
+
+	if b.State() != BreakerClosed {
+		t.Errorf("Expected breaker closed after a successful trial, got: %s", b.State())
+	}
+}
+
+func TestBreaker_FailedTrialReopens(t *testing.T) {
+	b, now := newTestBreaker(1, time.Minute)
+	b.record(errors.New("timeout"))
+
+	*now = now.Add(time.Minute)
+	_ = b.allow()
+	b.record(errors.New("timeout"))
+
+	if b.State() != BreakerOpen {
+		t.Errorf("Expected breaker open after a failed trial, got: %s", b.State())
+	}
+}
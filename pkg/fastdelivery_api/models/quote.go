@@ -1,6 +1,10 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/money"
+)
 
 type QuoteRequest struct {
 	Shipper        Shipper      `json:"shipper"`
@@ -30,27 +34,27 @@ type Recipient struct {
 }
 
 type Dispatcher struct {
-	RegisteredNumber string   `json:"registered_number"`
-	Zipcode          int      `json:"zipcode"`
-	TotalPrice       float64  `json:"total_price"`
-	Volumes          []Volume `json:"volumes"`
+	RegisteredNumber string      `json:"registered_number"`
+	Zipcode          int         `json:"zipcode"`
+	TotalPrice       money.Money `json:"total_price"`
+	Volumes          []Volume    `json:"volumes"`
 }
 
 type Volume struct {
-	Amount        int     `json:"amount"`
-	AmountVolumes int     `json:"amount_volumes"`
-	Category      string  `json:"category"`
-	SKU           string  `json:"sku"`
-	Tag           string  `json:"tag"`
-	Description   string  `json:"description"`
-	Height        float64 `json:"height"`
-	Width         float64 `json:"width"`
-	Length        float64 `json:"length"`
-	UnitaryPrice  float64 `json:"unitary_price"`
-	UnitaryWeight float64 `json:"unitary_weight"`
-	Consolidate   bool    `json:"consolidate"`
-	Overlaid      bool    `json:"overlaid"`
-	Rotate        bool    `json:"rotate"`
+	Amount        int         `json:"amount"`
+	AmountVolumes int         `json:"amount_volumes"`
+	Category      string      `json:"category"`
+	SKU           string      `json:"sku"`
+	Tag           string      `json:"tag"`
+	Description   string      `json:"description"`
+	Height        float64     `json:"height"`
+	Width         float64     `json:"width"`
+	Length        float64     `json:"length"`
+	UnitaryPrice  money.Money `json:"unitary_price"`
+	UnitaryWeight float64     `json:"unitary_weight"`
+	Consolidate   bool        `json:"consolidate"`
+	Overlaid      bool        `json:"overlaid"`
+	Rotate        bool        `json:"rotate"`
 }
 
 type Returns struct {
@@ -82,8 +86,8 @@ type Offer struct {
 	ServiceDescription           string       `json:"service_description"`
 	DeliveryTime                 DeliveryTime `json:"delivery_time"`
 	Expiration                   time.Time    `json:"expiration"`
-	CostPrice                    float64      `json:"cost_price" validate:"required"`
-	FinalPrice                   float64      `json:"final_price"`
+	CostPrice                    money.Money  `json:"cost_price" validate:"required"`
+	FinalPrice                   money.Money  `json:"final_price"`
 	Weights                      Weights      `json:"weights"`
 	Composition                  Composition  `json:"composition"`
 	OriginalDeliveryTime         DeliveryTime `json:"original_delivery_time"`
@@ -117,49 +121,49 @@ type Weights struct {
 }
 
 type Composition struct {
-	FreightWeight       float64   `json:"freight_weight"`
-	FreightWeightExcess float64   `json:"freight_weight_excess"`
-	FreightWeightVolume float64   `json:"freight_weight_volume"`
-	FreightVolume       float64   `json:"freight_volume"`
-	FreightMinimum      float64   `json:"freight_minimum"`
-	FreightInvoice      float64   `json:"freight_invoice"`
-	SubTotal1           SubTotal1 `json:"sub_total1"`
-	SubTotal2           SubTotal2 `json:"sub_total2"`
-	SubTotal3           SubTotal3 `json:"sub_total3"`
+	FreightWeight       money.Money `json:"freight_weight"`
+	FreightWeightExcess money.Money `json:"freight_weight_excess"`
+	FreightWeightVolume money.Money `json:"freight_weight_volume"`
+	FreightVolume       money.Money `json:"freight_volume"`
+	FreightMinimum      money.Money `json:"freight_minimum"`
+	FreightInvoice      money.Money `json:"freight_invoice"`
+	SubTotal1           SubTotal1   `json:"sub_total1"`
+	SubTotal2           SubTotal2   `json:"sub_total2"`
+	SubTotal3           SubTotal3   `json:"sub_total3"`
 }
 
 type SubTotal1 struct {
-	Daily           int `json:"daily"`
-	Collect         int `json:"collect"`
-	Dispatch        int `json:"dispatch"`
-	Delivery        int `json:"delivery"`
-	Ferry           int `json:"ferry"`
-	Suframa         int `json:"suframa"`
-	Tas             int `json:"tas"`
-	SecCat          int `json:"sec_cat"`
-	Dat             int `json:"dat"`
-	AdValorem       int `json:"ad_valorem"`
-	Ademe           int `json:"ademe"`
-	Gris            int `json:"gris"`
-	Emex            int `json:"emex"`
-	Interior        int `json:"interior"`
-	Capatazia       int `json:"capatazia"`
-	River           int `json:"river"`
-	RiverInsurance  int `json:"river_insurance"`
-	Toll            int `json:"toll"`
-	Other           int `json:"other"`
-	OtherPerProduct int `json:"other_per_product"`
+	Daily           money.Money `json:"daily"`
+	Collect         money.Money `json:"collect"`
+	Dispatch        money.Money `json:"dispatch"`
+	Delivery        money.Money `json:"delivery"`
+	Ferry           money.Money `json:"ferry"`
+	Suframa         money.Money `json:"suframa"`
+	Tas             money.Money `json:"tas"`
+	SecCat          money.Money `json:"sec_cat"`
+	Dat             money.Money `json:"dat"`
+	AdValorem       money.Money `json:"ad_valorem"`
+	Ademe           money.Money `json:"ademe"`
+	Gris            money.Money `json:"gris"`
+	Emex            money.Money `json:"emex"`
+	Interior        money.Money `json:"interior"`
+	Capatazia       money.Money `json:"capatazia"`
+	River           money.Money `json:"river"`
+	RiverInsurance  money.Money `json:"river_insurance"`
+	Toll            money.Money `json:"toll"`
+	Other           money.Money `json:"other"`
+	OtherPerProduct money.Money `json:"other_per_product"`
 }
 
 type SubTotal2 struct {
-	Trt        int `json:"trt"`
-	Tda        int `json:"tda"`
-	Tde        int `json:"tde"`
-	Scheduling int `json:"scheduling"`
+	Trt        money.Money `json:"trt"`
+	Tda        money.Money `json:"tda"`
+	Tde        money.Money `json:"tde"`
+	Scheduling money.Money `json:"scheduling"`
 }
 
 type SubTotal3 struct {
-	ICMS int `json:"icms"`
+	ICMS money.Money `json:"icms"`
 }
 
 type ESG struct {
@@ -177,7 +181,7 @@ type VolumeResponse struct {
 	Height        float64       `json:"height"`
 	Length        float64       `json:"length"`
 	UnitaryWeight float64       `json:"unitary_weight"`
-	UnitaryPrice  float64       `json:"unitary_price"`
+	UnitaryPrice  money.Money   `json:"unitary_price"`
 	AmountVolumes float64       `json:"amount_volumes"`
 	Consolidate   bool          `json:"consolidate"`
 	Overlaid      bool          `json:"overlaid"`
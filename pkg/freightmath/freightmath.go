@@ -0,0 +1,125 @@
+// Package freightmath computes the weight a carrier bills for a shipment:
+// the greater of the real weight and the cubed weight, where the cubed
+// weight converts the volume into kilograms with a cubage factor.
+package freightmath
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// DefaultCubageFactor is the road freight convention of 300 kg per m³.
+const DefaultCubageFactor = 300.0
+
+// Tolerance is how far apart, in kilograms, our billed weight and the
+// upstream one may be before they are flagged as a discrepancy.
+const Tolerance = 0.01
+
+// Item is one line of a shipment. Dimensions are in meters and the weight of
+// a single unit in kilograms, as in the Frete Rápido API.
+type Item struct {
+	Amount        int
+	UnitaryWeight float64
+	Height        float64
+	Width         float64
+	Length        float64
+}
+
+type Weights struct {
+	Real         float64
+	Cubed        float64
+	Billed       float64
+	CubageFactor float64
+}
+
+// Factors holds the cubage factor, in kg/m³, for each carrier and modal.
+// A carrier factor wins over a modal factor, which wins over Default. Keys
+// are matched case-insensitively.
+type Factors struct {
+	Default float64
+	Modal   map[string]float64
+	Carrier map[string]float64
+}
+
+func (f Factors) For(carrier, modal string) float64 {
+	if factor, ok := f.Carrier[normalize(carrier)]; ok {
+		return factor
+	}
+
+	if factor, ok := f.Modal[normalize(modal)]; ok {
+		return factor
+	}
+
+	if f.Default > 0 {
+		return f.Default
+	}
+
+	return DefaultCubageFactor
+}
+
+// Compute weighs items with the given cubage factor. Amounts below one count
+// as a single unit. Weights are rounded to 10 grams.
+func Compute(items []Item, cubageFactor float64) Weights {
+	var realWeight, cubedWeight float64
+	for _, item := range items {
+		amount := float64(max(item.Amount, 1))
+		realWeight += amount * item.UnitaryWeight
+		cubedWeight += amount * item.Height * item.Width * item.Length * cubageFactor
+	}
+
+	realWeight, cubedWeight = round(realWeight), round(cubedWeight)
+
+	return Weights{
+		Real:         realWeight,
+		Cubed:        cubedWeight,
+		Billed:       math.Max(realWeight, cubedWeight),
+		CubageFactor: cubageFactor,
+	}
+}
+
+// Differs reports whether an upstream billed weight disagrees with ours.
+// Upstream weights of zero mean the carrier did not report one.
+func Differs(billed, upstream float64) bool {
+	if upstream <= 0 {
+		return false
+	}
+
+	return math.Abs(billed-upstream) > Tolerance+1e-9
+}
+
+// ParseFactors reads a comma-separated list of "name=factor" pairs, such as
+// "Aéreo=166.67,Rodoviário=300".
+func ParseFactors(spec string) (map[string]float64, error) {
+	factors := make(map[string]float64)
+
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		name, value, ok := strings.Cut(entry, "=")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("cubage factor %q must be in the form \"name=factor\"", entry)
+		}
+
+		factor, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || factor <= 0 {
+			return nil, fmt.Errorf("cubage factor for %q must be a positive number, got %q", name, value)
+		}
+
+		factors[normalize(name)] = factor
+	}
+
+	return factors, nil
+}
+
+func normalize(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+func round(value float64) float64 {
+	return math.Round(value*100) / 100
+}
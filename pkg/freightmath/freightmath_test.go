@@ -0,0 +1,120 @@
+package freightmath_test
+
+import (
+	"testing"
+
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/freightmath"
+)
+
+func TestCompute(t *testing.T) {
+	tests := []struct {
+		name   string
+		items  []freightmath.Item
+		factor float64
+		want   freightmath.Weights
+	}{
+		{
+			name:   "real weight wins",
+			items:  []freightmath.Item{{Amount: 1, UnitaryWeight: 5, Height: 0.2, Width: 0.2, Length: 0.2}},
+			factor: 300,
+			want:   freightmath.Weights{Real: 5, Cubed: 2.4, Billed: 5, CubageFactor: 300},
+		},
+		{
+			name:   "cubed weight wins",
+			items:  []freightmath.Item{{Amount: 2, UnitaryWeight: 1, Height: 0.5, Width: 0.4, Length: 0.3}},
+			factor: 300,
+			want:   freightmath.Weights{Real: 2, Cubed: 36, Billed: 36, CubageFactor: 300},
+		},
+		{
+			name:   "air factor",
+			items:  []freightmath.Item{{Amount: 1, UnitaryWeight: 1, Height: 0.3, Width: 0.3, Length: 0.3}},
+			factor: 166.67,
+			want:   freightmath.Weights{Real: 1, Cubed: 4.5, Billed: 4.5, CubageFactor: 166.67},
+		},
+		{
+			// Quantidade zero conta como uma unidade, como na API do Frete Rápido
+			name:   "zero amount",
+			items:  []freightmath.Item{{UnitaryWeight: 3, Height: 0.1, Width: 0.1, Length: 0.1}},
+			factor: 300,
+			want:   freightmath.Weights{Real: 3, Cubed: 0.3, Billed: 3, CubageFactor: 300},
+		},
+		{
+			name:   "several items",
+			items:  []freightmath.Item{{Amount: 5, UnitaryWeight: 2.5}, {Amount: 3, UnitaryWeight: 1.8}},
+			factor: 300,
+			want:   freightmath.Weights{Real: 17.9, Billed: 17.9, CubageFactor: 300},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := freightmath.Compute(tt.items, tt.factor); got != tt.want {
+				t.Errorf("Expected %+v, got: %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestFactors_For(t *testing.T) {
+	factors := freightmath.Factors{
+		Default: 250,
+		Modal:   map[string]float64{"aéreo": 166.67},
+		Carrier: map[string]float64{"jadlog": 200},
+	}
+
+	tests := []struct {
+		carrier, modal string
+		want           float64
+	}{
+		{carrier: "JADLOG", modal: "Aéreo", want: 200},
+		{carrier: "LATAM CARGO", modal: "AÉREO", want: 166.67},
+		{carrier: "CORREIOS", modal: "Rodoviário", want: 250},
+	}
+
+	for _, tt := range tests {
+		if got := factors.For(tt.carrier, tt.modal); got != tt.want {
+			t.Errorf("Expected factor %v for %s/%s, got: %v", tt.want, tt.carrier, tt.modal, got)
+		}
+	}
+
+	if got := (freightmath.Factors{}).For("CORREIOS", ""); got != freightmath.DefaultCubageFactor {
+		t.Errorf("Expected the default factor, got: %v", got)
+	}
+}
+
+func TestDiffers(t *testing.T) {
+	tests := []struct {
+		billed, upstream float64
+		want             bool
+	}{
+		{billed: 5, upstream: 5, want: false},
+		{billed: 5, upstream: 5.01, want: false},
+		{billed: 5, upstream: 5.5, want: true},
+		{billed: 36, upstream: 2, want: true},
+		// Sem peso informado pela transportadora não há o que comparar
+		{billed: 5, upstream: 0, want: false},
+	}
+
+	for _, tt := range tests {
+		if got := freightmath.Differs(tt.billed, tt.upstream); got != tt.want {
+			t.Errorf("Expected Differs(%v, %v) = %t, got: %t", tt.billed, tt.upstream, tt.want, got)
+		}
+	}
+}
+
+func TestParseFactors(t *testing.T) {
+	factors, err := freightmath.ParseFactors(" Aéreo=166.67, Rodoviário = 300 ,")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if len(factors) != 2 || factors["aéreo"] != 166.67 || factors["rodoviário"] != 300 {
+		t.Errorf("Expected 2 normalized factors, got: %v", factors)
+	}
+
+	for _, spec := range []string{"Aéreo", "=300", "Aéreo=abc", "Aéreo=0", "Aéreo=-1"} {
+		if _, err := freightmath.ParseFactors(spec); err == nil {
+			t.Errorf("Expected error for %q", spec)
+		}
+	}
+}
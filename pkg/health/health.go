@@ -0,0 +1,151 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	LivenessPath  = "/livez"
+	ReadinessPath = "/readyz"
+)
+
+type Status string
+
+const (
+	StatusUp       Status = "up"
+	StatusDegraded Status = "degraded"
+	StatusDown     Status = "down"
+)
+
+type CheckFunc func(ctx context.Context) error
+
+type CheckResult struct {
+	Status    Status `json:"status"`
+	Critical  bool   `json:"critical"`
+	LatencyMS int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+type Report struct {
+	Status Status                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks"`
+}
+
+type check struct {
+	name     string
+	critical bool
+	fn       CheckFunc
+}
+
+// Checker runs dependency checks for the readiness probe. A failing critical
+// check marks the instance as down; any other failing check only degrades it.
+type Checker struct {
+	timeout time.Duration
+	checks  []check
+}
+
+func NewChecker(timeout time.Duration) *Checker {
+	return &Checker{timeout: timeout}
+}
+
+func (hc *Checker) Add(name string, critical bool, fn CheckFunc) {
+	hc.checks = append(hc.checks, check{name: name, critical: critical, fn: fn})
+}
+
+func (hc *Checker) Check(ctx context.Context) Report {
+	report := Report{
+		Status: StatusUp,
+		Checks: make(map[string]CheckResult, len(hc.checks)),
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, ch := range hc.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			result := hc.run(ctx, ch)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			report.Checks[ch.name] = result
+			if result.Status == StatusUp {
+				return
+			}
+
+			if ch.critical {
+				report.Status = StatusDown
+			} else if report.Status == StatusUp {
+				report.Status = StatusDegraded
+			}
+		}()
+	}
+	wg.Wait()
+
+	return report
+}
+
+func (hc *Checker) run(ctx context.Context, ch check) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, hc.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := ch.fn(ctx)
+
+	result := CheckResult{
+		Status:    StatusUp,
+		Critical:  ch.critical,
+		LatencyMS: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+
+	return result
+}
+
+func (hc *Checker) ReadinessHandler(c *fiber.Ctx) error {
+	report := hc.Check(c.UserContext())
+
+	status := fiber.StatusOK
+	if report.Status == StatusDown {
+		status = fiber.StatusServiceUnavailable
+	}
+
+	return c.Status(status).JSON(report)
+}
+
+func LivenessHandler(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": StatusUp,
+	})
+}
+
+// Cached reuses the outcome of fn for ttl, so that expensive checks such as
+// an upstream call are not repeated on every probe.
+func Cached(fn CheckFunc, ttl time.Duration) CheckFunc {
+	var mu sync.Mutex
+	var checkedAt time.Time
+	var last error
+
+	return func(ctx context.Context) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if !checkedAt.IsZero() && time.Since(checkedAt) < ttl {
+			return last
+		}
+
+		last = fn(ctx)
+		checkedAt = time.Now()
+
+		return last
+	}
+}
@@ -0,0 +1,136 @@
+package health_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/health"
+)
+
+func up(context.Context) error   { return nil }
+func down(context.Context) error { return errors.New("connection refused") }
+
+func readiness(t *testing.T, checker *health.Checker) (int, health.Report) {
+	t.Helper()
+
+	app := fiber.New()
+	app.Get(health.ReadinessPath, checker.ReadinessHandler)
+
+	resp, err := app.Test(httptest.NewRequest("GET", health.ReadinessPath, nil))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var report health.Report
+	if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+		t.Fatalf("Expected JSON report, got: %v", err)
+	}
+
+	return resp.StatusCode, report
+}
+
+func TestReadiness_AllChecksUp(t *testing.T) {
+	checker := health.NewChecker(time.Second)
+	checker.Add("database", true, up)
+	checker.Add("upstream", false, up)
+
+	status, report := readiness(t, checker)
+
+	if status != fiber.StatusOK {
+		t.Errorf("Expected status 200, got: %d", status)
+	}
+	if report.Status != health.StatusUp {
+		t.Errorf("Expected status up, got: %s", report.Status)
+	}
+	if len(report.Checks) != 2 {
+		t.Errorf("Expected 2 checks in the report, got: %d", len(report.Checks))
+	}
+}
+
+func TestReadiness_CriticalCheckDown(t *testing.T) {
+	checker := health.NewChecker(time.Second)
+	checker.Add("database", true, down)
+	checker.Add("upstream", false, up)
+
+	status, report := readiness(t, checker)
+
+	if status != fiber.StatusServiceUnavailable {
+		t.Errorf("Expected status 503, got: %d", status)
+	}
+	if report.Status != health.StatusDown {
+		t.Errorf("Expected status down, got: %s", report.Status)
+	}
+
+	database := report.Checks["database"]
+	if database.Status != health.StatusDown || database.Error != "connection refused" {
+		t.Errorf("Expected database check down with its error, got: %+v", database)
+	}
+}
+
+func TestReadiness_NonCriticalCheckDegrades(t *testing.T) {
+	checker := health.NewChecker(time.Second)
+	checker.Add("database", true, up)
+	checker.Add("circuit_breaker", false, down)
+
+	status, report := readiness(t, checker)
+
+	// Uma dependência não crítica fora do ar não deve tirar a instância do balanceador
+	if status != fiber.StatusOK {
+		t.Errorf("Expected status 200, got: %d", status)
+	}
+	if report.Status != health.StatusDegraded {
+		t.Errorf("Expected status degraded, got: %s", report.Status)
+	}
+}
+
+func TestReadiness_CheckTimeout(t *testing.T) {
+	checker := health.NewChecker(10 * time.Millisecond)
+	checker.Add("database", true, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	status, _ := readiness(t, checker)
+
+	if status != fiber.StatusServiceUnavailable {
+		t.Errorf("Expected status 503 for a check that times out, got: %d", status)
+	}
+}
+
+func TestLiveness(t *testing.T) {
+	app := fiber.New()
+	app.Get(health.LivenessPath, health.LivenessHandler)
+
+	resp, err := app.Test(httptest.NewRequest("GET", health.LivenessPath, nil))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if resp.StatusCode != fiber.StatusOK {
+		t.Errorf("Expected status 200, got: %d", resp.StatusCode)
+	}
+}
+
+func TestCached(t *testing.T) {
+	calls := 0
+	check := health.Cached(func(context.Context) error {
+		calls++
+		return nil
+	}, time.Minute)
+
+	for i := 0; i < 3; i++ {
+		if err := check(context.Background()); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+	}
+
+	if calls != 1 {
+		t.Errorf("Expected the check to run once within the ttl, got: %d", calls)
+	}
+}
@@ -0,0 +1,58 @@
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Group runs background workers that share a context which is cancelled on
+// shutdown, and waits for all of them to return.
+type Group struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewGroup(parent context.Context) *Group {
+	ctx, cancel := context.WithCancel(parent)
+
+	return &Group{
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+func (g *Group) Go(name string, fn func(ctx context.Context) error) {
+	g.wg.Add(1)
+
+	go func() {
+		defer g.wg.Done()
+
+		slog.Debug("background worker started", "worker", name)
+		if err := fn(g.ctx); err != nil {
+			slog.Error("background worker stopped with error", "worker", name, "error", err)
+			return
+		}
+		slog.Debug("background worker stopped", "worker", name)
+	}()
+}
+
+func (g *Group) Shutdown(timeout time.Duration) error {
+	g.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		g.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-time.After(timeout):
+		return fmt.Errorf("background workers did not stop within %s", timeout)
+	}
+}
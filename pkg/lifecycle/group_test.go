@@ -0,0 +1,48 @@
+package lifecycle_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/lifecycle"
+)
+
+func TestGroup_ShutdownWaitsForWorkers(t *testing.T) {
+	group := lifecycle.NewGroup(context.Background())
+
+	var stopped atomic.Int32
+	for i := 0; i < 3; i++ {
+		group.Go("worker", func(ctx context.Context) error {
+			<-ctx.Done()
+			time.Sleep(10 * time.Millisecond)
+			stopped.Add(1)
+			return nil
+		})
+	}
+
+	if err := group.Shutdown(time.Second); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if stopped.Load() != 3 {
+		t.Errorf("Expected 3 workers stopped, got: %d", stopped.Load())
+	}
+}
+
+func TestGroup_ShutdownTimeout(t *testing.T) {
+	group := lifecycle.NewGroup(context.Background())
+
+	release := make(chan struct{})
+	defer close(release)
+
+	group.Go("stuck", func(ctx context.Context) error {
+		<-release
+		return nil
+	})
+
+	if err := group.Shutdown(10 * time.Millisecond); err == nil {
+		t.Fatal("Expected timeout error for a worker that ignores cancellation")
+	}
+}
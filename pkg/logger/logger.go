@@ -7,12 +7,16 @@ import (
 	"github.com/jeancarloshp/desafio-frete-rapido/pkg/config"
 )
 
+var level = new(slog.LevelVar)
+
 func New(config *config.Config) {
 	var logger *slog.Logger
 
-	handler := &slog.HandlerOptions{}
+	handler := &slog.HandlerOptions{
+		Level: level,
+	}
 
-	setLoggingLevel(handler, config.LoggingLevel)
+	SetLevel(config.LoggingLevel)
 
 	if config.LoggingJSONFormat {
 		logger = slog.New(slog.NewJSONHandler(os.Stdout, handler))
@@ -23,15 +27,15 @@ func New(config *config.Config) {
 	slog.SetDefault(logger)
 }
 
-func setLoggingLevel(handler *slog.HandlerOptions, level string) {
-	switch level {
+func SetLevel(name string) {
+	switch name {
 	case "DEBUG":
-		handler.Level = slog.LevelDebug
+		level.Set(slog.LevelDebug)
 	case "INFO":
-		handler.Level = slog.LevelInfo
+		level.Set(slog.LevelInfo)
 	case "WARNING":
-		handler.Level = slog.LevelWarn
+		level.Set(slog.LevelWarn)
 	case "ERROR":
-		handler.Level = slog.LevelError
+		level.Set(slog.LevelError)
 	}
 }
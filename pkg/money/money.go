@@ -0,0 +1,230 @@
+// Package money represents amounts in Brazilian reais as an exact number of
+// centavos. Every conversion that could produce fractions of a centavo rounds
+// half to even, so sums and averages reconcile with the values stored in the
+// database and reported by the carriers.
+package money
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+type Money int64
+
+const Zero Money = 0
+
+func FromCents(cents int64) Money {
+	return Money(cents)
+}
+
+// Parse reads a decimal amount such as "12.34", "-0.5" or "1e3". A comma is
+// accepted as the decimal separator, as exported by pt-BR spreadsheets.
+func Parse(value string) (Money, error) {
+	value = strings.TrimSpace(value)
+	if strings.Count(value, ",") == 1 && !strings.Contains(value, ".") {
+		value = strings.Replace(value, ",", ".", 1)
+	}
+
+	r, ok := new(big.Rat).SetString(value)
+	if !ok {
+		return Zero, fmt.Errorf("invalid amount %q", value)
+	}
+
+	return fromRat(r)
+}
+
+// FromFloat converts a float using its shortest decimal representation, so
+// 0.125 becomes 0.12 and 0.135 becomes 0.14 instead of inheriting binary
+// representation errors.
+func FromFloat(value float64) Money {
+	m, err := Parse(strconv.FormatFloat(value, 'f', -1, 64))
+	if err != nil {
+		return Zero
+	}
+
+	return m
+}
+
+func (m Money) Cents() int64 {
+	return int64(m)
+}
+
+func (m Money) Float64() float64 {
+	return float64(m) / 100
+}
+
+func (m Money) IsZero() bool {
+	return m == 0
+}
+
+func (m Money) Add(other Money) Money {
+	return m + other
+}
+
+func (m Money) Sub(other Money) Money {
+	return m - other
+}
+
+// Mul multiplies by n, saturating like MulRatio.
+func (m Money) Mul(n int64) Money {
+	return m.MulRatio(n, 1)
+}
+
+// MulRatio multiplies by num/den, e.g. MulRatio(3, 1000) for a 0.3% fee.
+// The product is computed without overflowing, and results beyond the range
+// of Money saturate at its bounds.
+func (m Money) MulRatio(num, den int64) Money {
+	product := new(big.Int).Mul(big.NewInt(int64(m)), big.NewInt(num))
+	d := big.NewInt(den)
+	if d.Sign() < 0 {
+		product.Neg(product)
+		d.Neg(d)
+	}
+
+	q, rem := new(big.Int).QuoRem(product, d, new(big.Int))
+	if rem.Sign() != 0 && roundAway(rem, d, q.Bit(0) == 1) {
+		q.Add(q, big.NewInt(int64(rem.Sign())))
+	}
+
+	switch {
+	case q.IsInt64():
+		return Money(q.Int64())
+	case q.Sign() > 0:
+		return Money(math.MaxInt64)
+	default:
+		return Money(math.MinInt64)
+	}
+}
+
+// Div splits the amount into n parts, as used for averages.
+func (m Money) Div(n int64) Money {
+	return Money(divRound(int64(m), n))
+}
+
+func (m Money) String() string {
+	sign := ""
+	cents := int64(m)
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+
+	return fmt.Sprintf("%s%d.%02d", sign, cents/100, cents%100)
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+func (m *Money) UnmarshalJSON(data []byte) error {
+	value := strings.Trim(string(data), `"`)
+	if value == "null" || value == "" {
+		*m = Zero
+		return nil
+	}
+
+	parsed, err := Parse(value)
+	if err != nil {
+		return err
+	}
+
+	*m = parsed
+
+	return nil
+}
+
+// Value stores the amount as a decimal string of reais, the one storage unit
+// for every driver: NUMERIC columns in Postgres and TEXT columns in SQLite
+// keep it exactly.
+func (m Money) Value() (driver.Value, error) {
+	return m.String(), nil
+}
+
+// Scan reads the decimal strings written by Value. Floats and integers are
+// refused, since their unit would be ambiguous and floats bring back binary
+// rounding.
+func (m *Money) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*m = Zero
+	case string:
+		return m.parseInto(v)
+	case []byte:
+		return m.parseInto(string(v))
+	default:
+		return fmt.Errorf("cannot scan %T into money", src)
+	}
+
+	return nil
+}
+
+func (m *Money) parseInto(value string) error {
+	parsed, err := Parse(value)
+	if err != nil {
+		return err
+	}
+
+	*m = parsed
+
+	return nil
+}
+
+func fromRat(r *big.Rat) (Money, error) {
+	cents := new(big.Rat).Mul(r, big.NewRat(100, 1))
+
+	q, rem := new(big.Int).QuoRem(cents.Num(), cents.Denom(), new(big.Int))
+	if rem.Sign() != 0 && roundAway(rem, cents.Denom(), q.Bit(0) == 1) {
+		q.Add(q, big.NewInt(int64(rem.Sign())))
+	}
+
+	if !q.IsInt64() {
+		return Zero, fmt.Errorf("amount %s is out of range", r.FloatString(2))
+	}
+
+	return Money(q.Int64()), nil
+}
+
+// roundAway reports whether a truncated quotient must move away from zero
+// under half-even rounding.
+func roundAway(rem, den *big.Int, odd bool) bool {
+	twice := new(big.Int).Abs(rem)
+	twice.Lsh(twice, 1)
+
+	switch twice.Cmp(den) {
+	case 1:
+		return true
+	case 0:
+		return odd
+	default:
+		return false
+	}
+}
+
+func divRound(num, den int64) int64 {
+	if den < 0 {
+		num, den = -num, -den
+	}
+
+	q, rem := num/den, num%den
+	if rem == 0 {
+		return q
+	}
+
+	twice := rem * 2
+	if twice < 0 {
+		twice = -twice
+	}
+
+	if twice > den || (twice == den && q%2 != 0) {
+		if rem < 0 {
+			return q - 1
+		}
+		return q + 1
+	}
+
+	return q
+}
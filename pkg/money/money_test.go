@@ -0,0 +1,212 @@
+package money_test
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/money"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    int64
+		wantErr bool
+	}{
+		{input: "12.34", want: 1234},
+		{input: "12", want: 1200},
+		{input: "0.5", want: 50},
+		{input: "12,34", want: 1234},
+		{input: "1e2", want: 10000},
+		{input: "-7.05", want: -705},
+		// Meio centavo arredonda para o par mais próximo
+		{input: "0.125", want: 12},
+		{input: "0.135", want: 14},
+		{input: "-0.125", want: -12},
+		{input: "0.1251", want: 13},
+		{input: "2.675", want: 268},
+		{input: "abc", wantErr: true},
+		{input: "1.234,56", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := money.Parse(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Expected error, got: %v", got)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			if got.Cents() != tt.want {
+				t.Errorf("Expected %d cents, got: %d", tt.want, got.Cents())
+			}
+		})
+	}
+}
+
+func TestFromFloat(t *testing.T) {
+	// 0.1 + 0.2 em float64 é 0.30000000000000004
+	if got := money.FromFloat(0.1 + 0.2); got.Cents() != 30 {
+		t.Errorf("Expected 30 cents, got: %d", got.Cents())
+	}
+
+	if got := money.FromFloat(1.005); got.Cents() != 100 {
+		t.Errorf("Expected 100 cents, got: %d", got.Cents())
+	}
+}
+
+func TestDiv(t *testing.T) {
+	tests := []struct {
+		cents int64
+		n     int64
+		want  int64
+	}{
+		{cents: 1000, n: 3, want: 333},
+		{cents: 2000, n: 3, want: 667},
+		{cents: 5, n: 2, want: 2},
+		{cents: 7, n: 2, want: 4},
+		{cents: -5, n: 2, want: -2},
+		{cents: -7, n: 2, want: -4},
+	}
+
+	for _, tt := range tests {
+		if got := money.FromCents(tt.cents).Div(tt.n); got.Cents() != tt.want {
+			t.Errorf("Expected %d / %d = %d, got: %d", tt.cents, tt.n, tt.want, got.Cents())
+		}
+	}
+}
+
+func TestMulRatio(t *testing.T) {
+	tests := []struct {
+		name     string
+		cents    int64
+		num, den int64
+		want     int64
+	}{
+		// 0,3% de R$ 349,00 = R$ 1,047
+		{name: "taxa", cents: 34900, num: 3, den: 1000, want: 105},
+		{name: "negativo", cents: -34900, num: 3, den: 1000, want: -105},
+		{name: "meio centavo par", cents: 25, num: 1, den: 10, want: 2},
+		{name: "meio centavo ímpar", cents: 35, num: 1, den: 10, want: 4},
+		{name: "denominador negativo", cents: 34900, num: 3, den: -1000, want: -105},
+		// O produto intermediário passa de int64, mas o resultado cabe
+		{name: "produto grande", cents: 9e16, num: 150, den: 100, want: 135e15},
+		{name: "satura no máximo", cents: math.MaxInt64, num: 2, den: 1, want: math.MaxInt64},
+		{name: "satura no mínimo", cents: math.MaxInt64, num: -2, den: 1, want: math.MinInt64},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := money.FromCents(tt.cents).MulRatio(tt.num, tt.den); got.Cents() != tt.want {
+				t.Errorf("Expected %d cents, got: %d", tt.want, got.Cents())
+			}
+		})
+	}
+}
+
+func TestString(t *testing.T) {
+	tests := map[int64]string{
+		0:      "0.00",
+		5:      "0.05",
+		2550:   "25.50",
+		-705:   "-7.05",
+		123456: "1234.56",
+	}
+
+	for cents, want := range tests {
+		if got := money.FromCents(cents).String(); got != want {
+			t.Errorf("Expected %q, got: %q", want, got)
+		}
+	}
+}
+
+func TestJSON(t *testing.T) {
+	var payload struct {
+		Price money.Money `json:"price"`
+	}
+
+	if err := json.Unmarshal([]byte(`{"price":25.555}`), &payload); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if payload.Price.Cents() != 2556 {
+		t.Errorf("Expected 2556 cents, got: %d", payload.Price.Cents())
+	}
+
+	if err := json.Unmarshal([]byte(`{"price":"10.10"}`), &payload); err != nil {
+		t.Fatalf("Expected quoted amounts to decode, got: %v", err)
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if string(data) != `{"price":10.10}` {
+		t.Errorf("Expected price encoded with centavos, got: %s", data)
+	}
+
+	if err := json.Unmarshal([]byte(`{"price":true}`), &payload); err == nil {
+		t.Error("Expected error for non numeric price")
+	}
+}
+
+func TestScan(t *testing.T) {
+	tests := []struct {
+		name string
+		src  any
+		want int64
+	}{
+		{name: "postgres numeric", src: "25.50", want: 2550},
+		{name: "sqlite text", src: "0.30", want: 30},
+		{name: "bytes", src: []byte("0.99"), want: 99},
+		{name: "null", src: nil, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var m money.Money
+			if err := m.Scan(tt.src); err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			if m.Cents() != tt.want {
+				t.Errorf("Expected %d cents, got: %d", tt.want, m.Cents())
+			}
+		})
+	}
+
+	// Floats e inteiros não têm unidade definida
+	for _, src := range []any{true, 25.5, int64(3)} {
+		var m money.Money
+		if err := m.Scan(src); err == nil {
+			t.Errorf("Expected error scanning %T", src)
+		}
+	}
+}
+
+// O valor gravado por Value é lido de volta sem perda
+func TestValueScanRoundTrip(t *testing.T) {
+	for _, cents := range []int64{0, 1, 10, 30, 2550, -1999, 99999999999} {
+		value, err := money.FromCents(cents).Value()
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+
+		var m money.Money
+		if err := m.Scan(value); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+
+		if m.Cents() != cents {
+			t.Errorf("Expected %d cents, got: %d", cents, m.Cents())
+		}
+	}
+}
@@ -0,0 +1,230 @@
+// Package pricing adjusts carrier prices with the commercial rules of the
+// shop: margins, subsidies, floors, ceilings and price endings. Rules are
+// evaluated in order over each offer, and every rule whose condition matches
+// changes the price left by the previous ones.
+package pricing
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/money"
+)
+
+type ActionType string
+
+const (
+	// ActionMarkup adds Percent to the price. Negative percents are discounts.
+	ActionMarkup ActionType = "markup"
+	// ActionFreeShipping zeroes the price and stops the evaluation.
+	ActionFreeShipping ActionType = "free_shipping"
+	// ActionFloor raises the price to at least Amount.
+	ActionFloor ActionType = "floor"
+	// ActionCeiling lowers the price to at most Amount.
+	ActionCeiling ActionType = "ceiling"
+	// ActionRoundTo raises the price to the next value whose centavos are
+	// those of Amount, such as 0.90 for 23.17 becoming 23.90.
+	ActionRoundTo ActionType = "round_to"
+)
+
+var ErrInvalidRules = errors.New("invalid pricing rules")
+
+type Rule struct {
+	Name string    `json:"name"`
+	When Condition `json:"when"`
+	Then Action    `json:"then"`
+}
+
+// Condition restricts a rule to some offers. Empty fields match everything,
+// carriers and services are compared case-insensitively and cart values are
+// inclusive.
+type Condition struct {
+	Carriers     []string    `json:"carriers,omitempty"`
+	Services     []string    `json:"services,omitempty"`
+	CartValueMin money.Money `json:"cart_value_min,omitempty"`
+	CartValueMax money.Money `json:"cart_value_max,omitempty"`
+}
+
+// Action changes the price. Percent has a precision of 0.01%.
+type Action struct {
+	Type    ActionType  `json:"type"`
+	Percent float64     `json:"percent,omitempty"`
+	Amount  money.Money `json:"amount,omitempty"`
+}
+
+// Offer is what rules are evaluated against. CartValue is the total value
+// of the goods being shipped.
+type Offer struct {
+	Carrier   string
+	Service   string
+	Price     money.Money
+	CartValue money.Money
+}
+
+// Applied records a rule that fired and the price it left.
+type Applied struct {
+	Rule  string
+	Price money.Money
+}
+
+type Result struct {
+	Price   money.Money
+	Applied []Applied
+}
+
+type Engine struct {
+	rules []Rule
+}
+
+// New validates rules and builds an engine that evaluates them in order.
+func New(rules []Rule) (*Engine, error) {
+	names := make(map[string]bool, len(rules))
+	for i, r := range rules {
+		if err := r.validate(); err != nil {
+			return nil, fmt.Errorf("%w: rule %d (%s): %v", ErrInvalidRules, i+1, r.Name, err)
+		}
+		if names[r.Name] {
+			return nil, fmt.Errorf("%w: rule %d: duplicate name %q", ErrInvalidRules, i+1, r.Name)
+		}
+		names[r.Name] = true
+	}
+
+	return &Engine{rules: rules}, nil
+}
+
+// Load reads a JSON file with a list of rules.
+func Load(path string) (*Engine, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var rules []Rule
+	decoder := json.NewDecoder(f)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&rules); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRules, err)
+	}
+
+	return New(rules)
+}
+
+func (r Rule) validate() error {
+	if strings.TrimSpace(r.Name) == "" {
+		return errors.New("name is required")
+	}
+
+	if r.When.CartValueMin < 0 || r.When.CartValueMax < 0 {
+		return errors.New("cart values cannot be negative")
+	}
+	if !r.When.CartValueMax.IsZero() && r.When.CartValueMax < r.When.CartValueMin {
+		return fmt.Errorf("cart_value_max %s is below cart_value_min %s", r.When.CartValueMax, r.When.CartValueMin)
+	}
+
+	switch r.Then.Type {
+	case ActionMarkup:
+		if r.Then.Percent == 0 || r.Then.Percent <= -100 {
+			return errors.New("markup percent must be non-zero and above -100")
+		}
+	case ActionFreeShipping:
+	case ActionFloor, ActionCeiling:
+		if r.Then.Amount <= 0 {
+			return fmt.Errorf("%s amount must be greater than zero", r.Then.Type)
+		}
+	case ActionRoundTo:
+		if r.Then.Amount < 0 || r.Then.Amount >= money.FromCents(100) {
+			return errors.New("round_to amount must be between 0.00 and 0.99")
+		}
+	default:
+		return fmt.Errorf("unknown action %q, use markup, free_shipping, floor, ceiling or round_to", r.Then.Type)
+	}
+
+	return nil
+}
+
+func (c Condition) matches(o Offer) bool {
+	if len(c.Carriers) > 0 && !contains(c.Carriers, o.Carrier) {
+		return false
+	}
+
+	if len(c.Services) > 0 && !contains(c.Services, o.Service) {
+		return false
+	}
+
+	if o.CartValue < c.CartValueMin {
+		return false
+	}
+
+	return c.CartValueMax.IsZero() || o.CartValue <= c.CartValueMax
+}
+
+func (a Action) apply(price money.Money) money.Money {
+	switch a.Type {
+	case ActionMarkup:
+		price = price.MulRatio(10000+int64(math.Round(a.Percent*100)), 10000)
+	case ActionFreeShipping:
+		price = 0
+	case ActionFloor:
+		price = max(price, a.Amount)
+	case ActionCeiling:
+		price = min(price, a.Amount)
+	case ActionRoundTo:
+		if price > 0 {
+			ending := a.Amount.Cents()
+			reais := price.Cents() / 100
+			if price.Cents()%100 > ending {
+				reais++
+			}
+			price = money.FromCents(reais*100 + ending)
+		}
+	}
+
+	return max(price, 0)
+}
+
+// Apply evaluates the rules over offer. A nil engine leaves prices as they
+// are.
+func (e *Engine) Apply(offer Offer) Result {
+	result := Result{Price: offer.Price}
+	if e == nil {
+		return result
+	}
+
+	for _, rule := range e.rules {
+		if !rule.When.matches(offer) {
+			continue
+		}
+
+		result.Price = rule.Then.apply(result.Price)
+		result.Applied = append(result.Applied, Applied{Rule: rule.Name, Price: result.Price})
+
+		if rule.Then.Type == ActionFreeShipping {
+			break
+		}
+	}
+
+	return result
+}
+
+func (e *Engine) Len() int {
+	if e == nil {
+		return 0
+	}
+
+	return len(e.rules)
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if strings.EqualFold(strings.TrimSpace(n), strings.TrimSpace(name)) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,228 @@
+package pricing_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/money"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/pricing"
+)
+
+func price(value string) money.Money {
+	m, err := money.Parse(value)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name        string
+		rules       []pricing.Rule
+		offer       pricing.Offer
+		wantPrice   string
+		wantApplied []pricing.Applied
+	}{
+		{
+			name:      "no rules",
+			offer:     pricing.Offer{Carrier: "JADLOG", Price: price("20.00")},
+			wantPrice: "20.00",
+		},
+		{
+			name:        "markup per carrier",
+			rules:       []pricing.Rule{{Name: "jadlog-margin", When: pricing.Condition{Carriers: []string{"jadlog"}}, Then: pricing.Action{Type: pricing.ActionMarkup, Percent: 12.5}}},
+			offer:       pricing.Offer{Carrier: "JADLOG", Price: price("20.00")},
+			wantPrice:   "22.50",
+			wantApplied: []pricing.Applied{{Rule: "jadlog-margin", Price: price("22.50")}},
+		},
+		{
+			name:      "markup for another carrier",
+			rules:     []pricing.Rule{{Name: "jadlog-margin", When: pricing.Condition{Carriers: []string{"JADLOG"}}, Then: pricing.Action{Type: pricing.ActionMarkup, Percent: 12.5}}},
+			offer:     pricing.Offer{Carrier: "CORREIOS", Price: price("20.00")},
+			wantPrice: "20.00",
+		},
+		{
+			name:        "discount rounds half to even",
+			rules:       []pricing.Rule{{Name: "subsidy", Then: pricing.Action{Type: pricing.ActionMarkup, Percent: -10}}},
+			offer:       pricing.Offer{Price: price("10.05")},
+			wantPrice:   "9.04",
+			wantApplied: []pricing.Applied{{Rule: "subsidy", Price: price("9.04")}},
+		},
+		{
+			name: "free shipping above cart value stops the evaluation",
+			rules: []pricing.Rule{
+				{Name: "free-above-300", When: pricing.Condition{CartValueMin: price("300.00")}, Then: pricing.Action{Type: pricing.ActionFreeShipping}},
+				{Name: "floor", Then: pricing.Action{Type: pricing.ActionFloor, Amount: price("9.90")}},
+			},
+			offer:       pricing.Offer{Price: price("35.00"), CartValue: price("300.00")},
+			wantPrice:   "0.00",
+			wantApplied: []pricing.Applied{{Rule: "free-above-300", Price: 0}},
+		},
+		{
+			name: "cart below free shipping",
+			rules: []pricing.Rule{
+				{Name: "free-above-300", When: pricing.Condition{CartValueMin: price("300.00")}, Then: pricing.Action{Type: pricing.ActionFreeShipping}},
+			},
+			offer:     pricing.Offer{Price: price("35.00"), CartValue: price("299.99")},
+			wantPrice: "35.00",
+		},
+		{
+			name: "floor and ceiling",
+			rules: []pricing.Rule{
+				{Name: "floor", Then: pricing.Action{Type: pricing.ActionFloor, Amount: price("9.90")}},
+				{Name: "ceiling", Then: pricing.Action{Type: pricing.ActionCeiling, Amount: price("99.90")}},
+			},
+			offer:       pricing.Offer{Price: price("4.20")},
+			wantPrice:   "9.90",
+			wantApplied: []pricing.Applied{{Rule: "floor", Price: price("9.90")}, {Rule: "ceiling", Price: price("9.90")}},
+		},
+		{
+			name: "rules see the price left by the previous ones",
+			rules: []pricing.Rule{
+				{Name: "margin", Then: pricing.Action{Type: pricing.ActionMarkup, Percent: 10}},
+				{Name: "ending", Then: pricing.Action{Type: pricing.ActionRoundTo, Amount: price("0.90")}},
+			},
+			offer:       pricing.Offer{Service: "PAC", Price: price("21.10")},
+			wantPrice:   "23.90",
+			wantApplied: []pricing.Applied{{Rule: "margin", Price: price("23.21")}, {Rule: "ending", Price: price("23.90")}},
+		},
+		{
+			name:        "round to the next ending",
+			rules:       []pricing.Rule{{Name: "ending", Then: pricing.Action{Type: pricing.ActionRoundTo, Amount: price("0.90")}}},
+			offer:       pricing.Offer{Price: price("23.95")},
+			wantPrice:   "24.90",
+			wantApplied: []pricing.Applied{{Rule: "ending", Price: price("24.90")}},
+		},
+		{
+			name:        "round keeps free shipping free",
+			rules:       []pricing.Rule{{Name: "ending", Then: pricing.Action{Type: pricing.ActionRoundTo, Amount: price("0.90")}}},
+			offer:       pricing.Offer{Price: 0},
+			wantPrice:   "0.00",
+			wantApplied: []pricing.Applied{{Rule: "ending", Price: 0}},
+		},
+		{
+			name:      "service and cart range",
+			rules:     []pricing.Rule{{Name: "sedex", When: pricing.Condition{Services: []string{"SEDEX"}, CartValueMax: price("100.00")}, Then: pricing.Action{Type: pricing.ActionMarkup, Percent: 5}}},
+			offer:     pricing.Offer{Service: "SEDEX", Price: price("20.00"), CartValue: price("150.00")},
+			wantPrice: "20.00",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine, err := pricing.New(tt.rules)
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			result := engine.Apply(tt.offer)
+
+			if result.Price.String() != tt.wantPrice {
+				t.Errorf("Expected price %s, got: %s", tt.wantPrice, result.Price)
+			}
+			if !reflect.DeepEqual(result.Applied, tt.wantApplied) {
+				t.Errorf("Expected applied rules %+v, got: %+v", tt.wantApplied, result.Applied)
+			}
+		})
+	}
+}
+
+// Sem regras configuradas, o preço do Frete Rápido é mantido
+func TestApply_NilEngine(t *testing.T) {
+	var engine *pricing.Engine
+
+	if result := engine.Apply(pricing.Offer{Price: price("12.34")}); result.Price != price("12.34") || result.Applied != nil {
+		t.Errorf("Expected price to be unchanged, got: %+v", result)
+	}
+}
+
+func TestNew_RejectsInvalidRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		rules   []pricing.Rule
+		wantErr string
+	}{
+		{
+			name:    "missing name",
+			rules:   []pricing.Rule{{Then: pricing.Action{Type: pricing.ActionFreeShipping}}},
+			wantErr: "name is required",
+		},
+		{
+			name: "duplicate name",
+			rules: []pricing.Rule{
+				{Name: "free", Then: pricing.Action{Type: pricing.ActionFreeShipping}},
+				{Name: "free", Then: pricing.Action{Type: pricing.ActionFreeShipping}},
+			},
+			wantErr: `duplicate name "free"`,
+		},
+		{
+			name:    "unknown action",
+			rules:   []pricing.Rule{{Name: "x", Then: pricing.Action{Type: "double"}}},
+			wantErr: `unknown action "double"`,
+		},
+		{
+			name:    "markup without percent",
+			rules:   []pricing.Rule{{Name: "x", Then: pricing.Action{Type: pricing.ActionMarkup}}},
+			wantErr: "markup percent",
+		},
+		{
+			name:    "floor without amount",
+			rules:   []pricing.Rule{{Name: "x", Then: pricing.Action{Type: pricing.ActionFloor}}},
+			wantErr: "floor amount",
+		},
+		{
+			name:    "round to a whole real",
+			rules:   []pricing.Rule{{Name: "x", Then: pricing.Action{Type: pricing.ActionRoundTo, Amount: price("1.00")}}},
+			wantErr: "round_to amount",
+		},
+		{
+			name:    "inverted cart range",
+			rules:   []pricing.Rule{{Name: "x", When: pricing.Condition{CartValueMin: price("200.00"), CartValueMax: price("100.00")}, Then: pricing.Action{Type: pricing.ActionFreeShipping}}},
+			wantErr: "cart_value_max",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := pricing.New(tt.rules)
+			if !errors.Is(err, pricing.ErrInvalidRules) || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.json")
+	rules := `[
+		{"name": "jadlog-margin", "when": {"carriers": ["JADLOG"]}, "then": {"type": "markup", "percent": 10}},
+		{"name": "free-above-300", "when": {"cart_value_min": "300.00"}, "then": {"type": "free_shipping"}},
+		{"name": "ending", "then": {"type": "round_to", "amount": 0.90}}
+	]`
+	if err := os.WriteFile(path, []byte(rules), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	engine, err := pricing.Load(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if engine.Len() != 3 {
+		t.Errorf("Expected 3 rules, got: %d", engine.Len())
+	}
+
+	// Campos desconhecidos costumam ser erros de digitação na regra
+	if err := os.WriteFile(path, []byte(`[{"name": "x", "then": {"type": "markup", "percentage": 10}}]`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := pricing.Load(path); !errors.Is(err, pricing.ErrInvalidRules) {
+		t.Errorf("Expected unknown fields to be rejected, got: %v", err)
+	}
+}
@@ -0,0 +1,132 @@
+package ratelimit
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+const idleBucketTTL = 10 * time.Minute
+
+type Decision struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+type KeyStats struct {
+	Key       string    `json:"key"`
+	Allowed   int64     `json:"allowed"`
+	Rejected  int64     `json:"rejected"`
+	Remaining int       `json:"remaining"`
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+type bucket struct {
+	tokens   float64
+	last     time.Time
+	allowed  int64
+	rejected int64
+}
+
+// Limiter is an in-memory token bucket per key. Each bucket holds up to
+// burst tokens and refills at rate tokens per second.
+type Limiter struct {
+	rate  float64
+	burst int
+	now   func() time.Time
+
+	mu          sync.Mutex
+	buckets     map[string]*bucket
+	lastCleanup time.Time
+}
+
+func New(requestsPerMinute, burst int) *Limiter {
+	return &Limiter{
+		rate:    float64(requestsPerMinute) / 60,
+		burst:   burst,
+		now:     time.Now,
+		buckets: make(map[string]*bucket),
+	}
+}
+
+func (l *Limiter) Allow(key string) Decision {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.cleanup(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(l.burst), last: now}
+		l.buckets[key] = b
+	}
+
+	b.tokens = math.Min(float64(l.burst), b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+
+	decision := Decision{Limit: l.burst}
+
+	if b.tokens >= 1 {
+		b.tokens--
+		b.allowed++
+		decision.Allowed = true
+	} else {
+		b.rejected++
+		decision.RetryAfter = l.durationFor(1 - b.tokens)
+	}
+
+	decision.Remaining = int(b.tokens)
+	decision.Reset = l.durationFor(float64(l.burst) - b.tokens)
+
+	return decision
+}
+
+func (l *Limiter) Stats() []KeyStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	stats := make([]KeyStats, 0, len(l.buckets))
+	for key, b := range l.buckets {
+		stats = append(stats, KeyStats{
+			Key:       key,
+			Allowed:   b.allowed,
+			Rejected:  b.rejected,
+			Remaining: int(b.tokens),
+			LastSeen:  b.last,
+		})
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Key < stats[j].Key
+	})
+
+	return stats
+}
+
+func (l *Limiter) durationFor(tokens float64) time.Duration {
+	if tokens <= 0 || l.rate <= 0 {
+		return 0
+	}
+
+	return time.Duration(math.Ceil(tokens / l.rate * float64(time.Second)))
+}
+
+// cleanup drops buckets that have been idle long enough to be full again,
+// so the map does not grow with every client IP ever seen.
+func (l *Limiter) cleanup(now time.Time) {
+	if now.Sub(l.lastCleanup) < idleBucketTTL {
+		return
+	}
+	l.lastCleanup = now
+
+	for key, b := range l.buckets {
+		if now.Sub(b.last) > idleBucketTTL {
+			delete(l.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func newTestLimiter(requestsPerMinute, burst int) (*Limiter, *time.Time) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	l := New(requestsPerMinute, burst)
+	l.now = func() time.Time { return now }
+
+	return l, &now
+}
+
+func TestAllow_ConsumesBurstThenRejects(t *testing.T) {
+	l, _ := newTestLimiter(60, 3)
+
+	for i := 0; i < 3; i++ {
+		d := l.Allow("tenant:a")
+		if !d.Allowed {
+			t.Fatalf("Expected request %d to be allowed", i+1)
+		}
+		if d.Remaining != 2-i {
+			t.Errorf("Expected remaining %d, got: %d", 2-i, d.Remaining)
+		}
+	}
+
+	d := l.Allow("tenant:a")
+	if d.Allowed {
+		t.Fatal("Expected request beyond burst to be rejected")
+	}
+
+	if d.RetryAfter != time.Second {
+		t.Errorf("Expected retry after 1s at 60 rpm, got: %s", d.RetryAfter)
+	}
+}
+
+func TestAllow_RefillsOverTime(t *testing.T) {
+	l, now := newTestLimiter(60, 1)
+
+	l.Allow("ip:10.0.0.1")
+	if l.Allow("ip:10.0.0.1").Allowed {
+		t.Fatal("Expected bucket to be empty")
+	}
+
+	*now = now.Add(time.Second)
+
+	if !l.Allow("ip:10.0.0.1").Allowed {
+		t.Error("Expected bucket to refill after one second")
+	}
+}
+
+func TestAllow_KeysAreIndependent(t *testing.T) {
+	l, _ := newTestLimiter(60, 1)
+
+	l.Allow("tenant:a")
+
+	if !l.Allow("tenant:b").Allowed {
+		t.Error("Expected another key to have its own bucket")
+	}
+}
+
+func TestStats(t *testing.T) {
+	l, _ := newTestLimiter(60, 1)
+
+	l.Allow("tenant:a")
+	l.Allow("tenant:a")
+
+	stats := l.Stats()
+	if len(stats) != 1 {
+		t.Fatalf("Expected 1 key, got: %d", len(stats))
+	}
+
+	if stats[0].Allowed != 1 || stats[0].Rejected != 1 {
+		t.Errorf("Expected 1 allowed and 1 rejected, got: %+v", stats[0])
+	}
+}
@@ -0,0 +1,304 @@
+// Package ratetable quotes shipments offline from the tables contracted with
+// each carrier. It answers with the same models as the Frete Rápido API so it
+// can stand in for it when the API is unavailable.
+package ratetable
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/fastdelivery_api/models"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/freightmath"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/money"
+)
+
+// DispatcherID identifies the offers built from the rate tables.
+const DispatcherID = "rate-table"
+
+var ErrInvalidTable = errors.New("invalid rate table")
+
+var columns = []string{"carrier", "service", "modal", "zipcode_start", "zipcode_end", "weight_min", "weight_max", "price", "deadline"}
+
+// Rate prices one weight band of a CEP range. Both ranges are inclusive and
+// weights are the billed weight in kilograms.
+type Rate struct {
+	Carrier      string      `json:"carrier"`
+	Service      string      `json:"service"`
+	Modal        string      `json:"modal"`
+	ZipCodeStart int         `json:"zipcode_start"`
+	ZipCodeEnd   int         `json:"zipcode_end"`
+	WeightMin    float64     `json:"weight_min"`
+	WeightMax    float64     `json:"weight_max"`
+	Price        money.Money `json:"price"`
+	Deadline     int         `json:"deadline"`
+}
+
+func (r Rate) matches(zipcode int, weight float64) bool {
+	return zipcode >= r.ZipCodeStart && zipcode <= r.ZipCodeEnd &&
+		weight >= r.WeightMin && weight <= r.WeightMax
+}
+
+func (r Rate) validate() error {
+	switch {
+	case r.Carrier == "" || r.Service == "":
+		return errors.New("carrier and service are required")
+	case r.ZipCodeStart <= 0 || r.ZipCodeEnd < r.ZipCodeStart || r.ZipCodeEnd > 99999999:
+		return fmt.Errorf("invalid CEP range %d-%d", r.ZipCodeStart, r.ZipCodeEnd)
+	case r.WeightMin < 0 || r.WeightMax < r.WeightMin:
+		return fmt.Errorf("invalid weight band %v-%v", r.WeightMin, r.WeightMax)
+	case r.Price <= 0:
+		return errors.New("price must be greater than zero")
+	case r.Deadline <= 0:
+		return errors.New("deadline must be greater than zero")
+	}
+
+	return nil
+}
+
+type Table struct {
+	rates   []Rate
+	factors freightmath.Factors
+}
+
+// New builds a table from rates. Billed weights are computed with factors,
+// looked up by the carrier and modal of each rate.
+func New(rates []Rate, factors freightmath.Factors) (*Table, error) {
+	for i, r := range rates {
+		if err := r.validate(); err != nil {
+			return nil, fmt.Errorf("%w: rate %d (%s %s): %v", ErrInvalidTable, i+1, r.Carrier, r.Service, err)
+		}
+	}
+
+	return &Table{rates: rates, factors: factors}, nil
+}
+
+// Load reads a .csv or .json file, or every such file in a directory, in
+// name order.
+func Load(path string, factors freightmath.Factors) (*Table, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	files := []string{path}
+	if info.IsDir() {
+		files = nil
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if ext := filepath.Ext(entry.Name()); !entry.IsDir() && (ext == ".csv" || ext == ".json") {
+				files = append(files, filepath.Join(path, entry.Name()))
+			}
+		}
+		sort.Strings(files)
+	}
+
+	var rates []Rate
+	for _, file := range files {
+		loaded, err := loadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		rates = append(rates, loaded...)
+	}
+
+	if len(rates) == 0 {
+		return nil, fmt.Errorf("%w: no rates found in %s", ErrInvalidTable, path)
+	}
+
+	return New(rates, factors)
+}
+
+func loadFile(path string) ([]Rate, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	switch filepath.Ext(path) {
+	case ".csv":
+		return ParseCSV(f)
+	case ".json":
+		return ParseJSON(f)
+	default:
+		return nil, fmt.Errorf("%w: unsupported file type %q, use .csv or .json", ErrInvalidTable, filepath.Ext(path))
+	}
+}
+
+func ParseJSON(r io.Reader) ([]Rate, error) {
+	var rates []Rate
+	if err := json.NewDecoder(r).Decode(&rates); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTable, err)
+	}
+
+	return rates, nil
+}
+
+// ParseCSV reads a table with a header naming every column. Numbers may use
+// a comma as the decimal separator when fields are separated by ";".
+func ParseCSV(r io.Reader) ([]Rate, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTable, err)
+	}
+
+	if len(header) == 1 && strings.Contains(header[0], ";") {
+		reader.Comma = ';'
+		header = strings.Split(header[0], ";")
+		reader.FieldsPerRecord = len(header)
+	}
+
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		index[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+
+	for _, name := range columns {
+		if _, ok := index[name]; !ok {
+			return nil, fmt.Errorf("%w: missing column %q", ErrInvalidTable, name)
+		}
+	}
+
+	var rates []Rate
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidTable, err)
+		}
+
+		rate, err := parseRecord(record, index)
+		if err != nil {
+			return nil, fmt.Errorf("%w: row %d: %v", ErrInvalidTable, line, err)
+		}
+		rates = append(rates, rate)
+	}
+
+	return rates, nil
+}
+
+func parseRecord(record []string, index map[string]int) (Rate, error) {
+	field := func(name string) string {
+		if i := index[name]; i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	var errs []error
+	integer := func(name string) int {
+		n, err := strconv.Atoi(field(name))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s must be an integer, got %q", name, field(name)))
+		}
+		return n
+	}
+	number := func(name string) float64 {
+		n, err := strconv.ParseFloat(strings.Replace(field(name), ",", ".", 1), 64)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s must be a number, got %q", name, field(name)))
+		}
+		return n
+	}
+
+	price, err := money.Parse(field("price"))
+	if err != nil {
+		errs = append(errs, fmt.Errorf("price must be an amount, got %q", field("price")))
+	}
+
+	rate := Rate{
+		Carrier:      field("carrier"),
+		Service:      field("service"),
+		Modal:        field("modal"),
+		ZipCodeStart: integer("zipcode_start"),
+		ZipCodeEnd:   integer("zipcode_end"),
+		WeightMin:    number("weight_min"),
+		WeightMax:    number("weight_max"),
+		Price:        price,
+		Deadline:     integer("deadline"),
+	}
+
+	return rate, errors.Join(errs...)
+}
+
+// SimulateQuote answers like the Frete Rápido API: one dispatcher per
+// requested dispatcher, with the first matching rate of each carrier service
+// as an offer. Dispatchers whose weight or CEP no table covers have no offers.
+func (t *Table) SimulateQuote(ctx context.Context, request models.QuoteRequest) (*models.QuoteResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	response := &models.QuoteResponse{Dispatchers: make([]models.DispatcherResponse, len(request.Dispatchers))}
+
+	for i, d := range request.Dispatchers {
+		items := make([]freightmath.Item, len(d.Volumes))
+		for j, v := range d.Volumes {
+			items[j] = freightmath.Item{
+				Amount:        v.Amount,
+				UnitaryWeight: v.UnitaryWeight,
+				Height:        v.Height,
+				Width:         v.Width,
+				Length:        v.Length,
+			}
+		}
+
+		dispatcher := models.DispatcherResponse{
+			ID:                         DispatcherID,
+			RegisteredNumberShipper:    request.Shipper.RegisteredNumber,
+			RegisteredNumberDispatcher: d.RegisteredNumber,
+			ZipcodeOrigin:              d.Zipcode,
+		}
+
+		seen := make(map[string]bool)
+		for _, rate := range t.rates {
+			key := rate.Carrier + "\x00" + rate.Service
+			if seen[key] {
+				continue
+			}
+
+			weights := freightmath.Compute(items, t.factors.For(rate.Carrier, rate.Modal))
+			if !rate.matches(request.Recipient.Zipcode, weights.Billed) {
+				continue
+			}
+			seen[key] = true
+
+			dispatcher.Offers = append(dispatcher.Offers, models.Offer{
+				Offer:              len(dispatcher.Offers) + 1,
+				Carrier:            models.Carrier{Name: rate.Carrier},
+				Service:            rate.Service,
+				ServiceDescription: rate.Service,
+				DeliveryTime:       models.DeliveryTime{Days: rate.Deadline},
+				CostPrice:          rate.Price,
+				FinalPrice:         rate.Price,
+				Weights:            models.Weights{Real: weights.Real, Cubed: weights.Cubed, Used: weights.Billed},
+				Modal:              rate.Modal,
+			})
+		}
+
+		response.Dispatchers[i] = dispatcher
+	}
+
+	return response, nil
+}
+
+func (t *Table) Len() int {
+	return len(t.rates)
+}
@@ -0,0 +1,255 @@
+package ratetable_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/fastdelivery_api/models"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/freightmath"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/money"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/ratetable"
+)
+
+const tableCSV = `carrier,service,modal,zipcode_start,zipcode_end,weight_min,weight_max,price,deadline
+CORREIOS,PAC,Rodoviário,1000000,19999999,0,5,25.90,6
+CORREIOS,PAC,Rodoviário,1000000,19999999,5.01,30,48.50,6
+CORREIOS,SEDEX,Aéreo,1000000,19999999,0,30,62.00,2
+JADLOG,.Package,Rodoviário,20000000,29999999,0,30,31.00,5
+`
+
+func quoteRequest(zipcode int, weight float64) models.QuoteRequest {
+	return models.QuoteRequest{
+		Shipper:   models.Shipper{RegisteredNumber: "25438296000158"},
+		Recipient: models.Recipient{Country: "BRA", Zipcode: zipcode},
+		Dispatchers: []models.Dispatcher{
+			{
+				RegisteredNumber: "25438296000158",
+				Zipcode:          29161376,
+				Volumes: []models.Volume{
+					{Amount: 1, UnitaryWeight: weight, Height: 0.1, Width: 0.1, Length: 0.1},
+				},
+			},
+		},
+	}
+}
+
+func newTable(t *testing.T) *ratetable.Table {
+	t.Helper()
+
+	rates, err := ratetable.ParseCSV(strings.NewReader(tableCSV))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	table, err := ratetable.New(rates, freightmath.Factors{Modal: map[string]float64{"aéreo": 6000}})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	return table
+}
+
+func TestSimulateQuote(t *testing.T) {
+	tests := []struct {
+		name       string
+		zipcode    int
+		weight     float64
+		wantOffers map[string]string
+	}{
+		{
+			name:       "first weight band",
+			zipcode:    1311000,
+			weight:     2,
+			wantOffers: map[string]string{"PAC": "25.90", "SEDEX": "62.00"},
+		},
+		{
+			name:       "second weight band",
+			zipcode:    1311000,
+			weight:     10,
+			wantOffers: map[string]string{"PAC": "48.50", "SEDEX": "62.00"},
+		},
+		{
+			// O fator aéreo de 6000 kg/m³ leva o peso taxado do SEDEX para 6 kg
+			name:       "billed weight per modal",
+			zipcode:    1311000,
+			weight:     1,
+			wantOffers: map[string]string{"PAC": "25.90", "SEDEX": "62.00"},
+		},
+		{
+			name:       "other CEP range",
+			zipcode:    29161376,
+			weight:     2,
+			wantOffers: map[string]string{".Package": "31.00"},
+		},
+		{
+			name:       "no band covers the weight",
+			zipcode:    1311000,
+			weight:     50,
+			wantOffers: map[string]string{},
+		},
+		{
+			name:       "no range covers the CEP",
+			zipcode:    90000000,
+			weight:     2,
+			wantOffers: map[string]string{},
+		},
+	}
+
+	table := newTable(t)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := table.SimulateQuote(context.Background(), quoteRequest(tt.zipcode, tt.weight))
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			if len(resp.Dispatchers) != 1 || resp.Dispatchers[0].ID != ratetable.DispatcherID {
+				t.Fatalf("Expected one %s dispatcher, got: %+v", ratetable.DispatcherID, resp.Dispatchers)
+			}
+
+			offers := resp.Dispatchers[0].Offers
+			if len(offers) != len(tt.wantOffers) {
+				t.Fatalf("Expected %d offers, got: %+v", len(tt.wantOffers), offers)
+			}
+
+			for _, offer := range offers {
+				want, ok := tt.wantOffers[offer.Service]
+				if !ok || offer.FinalPrice.String() != want {
+					t.Errorf("Expected %s to cost %s, got: %s", offer.Service, want, offer.FinalPrice)
+				}
+				if offer.DeliveryTime.Days <= 0 {
+					t.Errorf("Expected a deadline for %s, got: %d", offer.Service, offer.DeliveryTime.Days)
+				}
+			}
+		})
+	}
+}
+
+// O peso usado na faixa é o taxado, não o real
+func TestSimulateQuote_UsesBilledWeight(t *testing.T) {
+	table := newTable(t)
+
+	resp, err := table.SimulateQuote(context.Background(), quoteRequest(1311000, 1))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	for _, offer := range resp.Dispatchers[0].Offers {
+		if offer.Service == "SEDEX" && offer.Weights.Used != 6 {
+			t.Errorf("Expected SEDEX to bill 6 kg, got: %v", offer.Weights.Used)
+		}
+		if offer.Service == "PAC" && offer.Weights.Used != 1 {
+			t.Errorf("Expected PAC to bill 1 kg, got: %v", offer.Weights.Used)
+		}
+	}
+}
+
+func TestParseCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    ratetable.Rate
+		wantErr string
+	}{
+		{
+			name:  "comma separated",
+			input: "carrier,service,modal,zipcode_start,zipcode_end,weight_min,weight_max,price,deadline\nCORREIOS,PAC,,1000000,1999999,0,5.5,25.90,6\n",
+			want:  ratetable.Rate{Carrier: "CORREIOS", Service: "PAC", ZipCodeStart: 1000000, ZipCodeEnd: 1999999, WeightMax: 5.5, Price: money.FromCents(2590), Deadline: 6},
+		},
+		{
+			// Planilhas exportadas em português usam ";" e vírgula decimal
+			name:  "semicolon separated with decimal comma",
+			input: "\ufeffdeadline;price;weight_max;weight_min;zipcode_end;zipcode_start;modal;service;carrier\n6;25,90;5,5;0;1999999;1000000;;PAC;CORREIOS\n",
+			want:  ratetable.Rate{Carrier: "CORREIOS", Service: "PAC", ZipCodeStart: 1000000, ZipCodeEnd: 1999999, WeightMax: 5.5, Price: money.FromCents(2590), Deadline: 6},
+		},
+		{
+			name:    "missing column",
+			input:   "carrier,service,zipcode_start,zipcode_end,weight_min,weight_max,price,deadline\n",
+			wantErr: `missing column "modal"`,
+		},
+		{
+			name:    "invalid number",
+			input:   "carrier,service,modal,zipcode_start,zipcode_end,weight_min,weight_max,price,deadline\nCORREIOS,PAC,,1000000,1999999,0,cinco,25.90,6\n",
+			wantErr: "row 2: weight_max must be a number",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rates, err := ratetable.ParseCSV(strings.NewReader(tt.input))
+			if tt.wantErr != "" {
+				if !errors.Is(err, ratetable.ErrInvalidTable) || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Expected error containing %q, got: %v", tt.wantErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if len(rates) != 1 || rates[0] != tt.want {
+				t.Errorf("Expected %+v, got: %+v", tt.want, rates)
+			}
+		})
+	}
+}
+
+func TestNew_RejectsInvalidRates(t *testing.T) {
+	valid := ratetable.Rate{Carrier: "CORREIOS", Service: "PAC", ZipCodeStart: 1000000, ZipCodeEnd: 1999999, WeightMax: 5, Price: money.FromCents(2590), Deadline: 6}
+
+	tests := []struct {
+		name   string
+		mutate func(r *ratetable.Rate)
+	}{
+		{name: "missing carrier", mutate: func(r *ratetable.Rate) { r.Carrier = "" }},
+		{name: "inverted CEP range", mutate: func(r *ratetable.Rate) { r.ZipCodeEnd = 999999 }},
+		{name: "inverted weight band", mutate: func(r *ratetable.Rate) { r.WeightMin = 10 }},
+		{name: "zero price", mutate: func(r *ratetable.Rate) { r.Price = 0 }},
+		{name: "zero deadline", mutate: func(r *ratetable.Rate) { r.Deadline = 0 }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rate := valid
+			tt.mutate(&rate)
+
+			if _, err := ratetable.New([]ratetable.Rate{valid, rate}, freightmath.Factors{}); !errors.Is(err, ratetable.ErrInvalidTable) || !strings.Contains(err.Error(), "rate 2") {
+				t.Errorf("Expected rate 2 to be rejected, got: %v", err)
+			}
+		})
+	}
+}
+
+// Um diretório junta as tabelas CSV e JSON e ignora os demais arquivos
+func TestLoad_Directory(t *testing.T) {
+	dir := t.TempDir()
+
+	files := map[string]string{
+		"correios.csv": tableCSV,
+		"azul.json":    `[{"carrier":"AZUL CARGO","service":"Amanhã","modal":"Aéreo","zipcode_start":1000000,"zipcode_end":19999999,"weight_min":0,"weight_max":10,"price":"89.90","deadline":1}]`,
+		"README.md":    "tabelas contratadas",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	table, err := ratetable.Load(dir, freightmath.Factors{})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if table.Len() != 5 {
+		t.Errorf("Expected 5 rates, got: %d", table.Len())
+	}
+
+	if _, err := ratetable.Load(t.TempDir(), freightmath.Factors{}); !errors.Is(err, ratetable.ErrInvalidTable) {
+		t.Errorf("Expected an empty directory to be rejected, got: %v", err)
+	}
+}
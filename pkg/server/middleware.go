@@ -0,0 +1,84 @@
+package server
+
+import (
+	"log/slog"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/cors"
+	"github.com/gofiber/fiber/v2/middleware/helmet"
+	"github.com/gofiber/fiber/v2/middleware/timeout"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/config"
+)
+
+type corsMiddleware struct {
+	handler atomic.Pointer[fiber.Handler]
+}
+
+func newCORS(cfg *config.Config) *corsMiddleware {
+	m := &corsMiddleware{}
+	m.update(cfg)
+
+	return m
+}
+
+func (m *corsMiddleware) update(cfg *config.Config) {
+	handler := func(c *fiber.Ctx) error {
+		return c.Next()
+	}
+
+	// An empty origin list disables CORS entirely instead of falling back to
+	// the fiber default, which would allow every origin.
+	if strings.TrimSpace(cfg.HTTPCorsAllowedOrigins) != "" {
+		handler = cors.New(cors.Config{
+			AllowOrigins: cfg.HTTPCorsAllowedOrigins,
+			AllowMethods: cfg.HTTPCorsAllowedMethods,
+			AllowHeaders: cfg.HTTPCorsAllowedHeaders,
+		})
+	}
+
+	m.handler.Store(&handler)
+	slog.Debug("cors settings applied", "origins", cfg.HTTPCorsAllowedOrigins)
+}
+
+func (m *corsMiddleware) handle(c *fiber.Ctx) error {
+	return (*m.handler.Load())(c)
+}
+
+func securityHeaders(cfg *config.Config) fiber.Handler {
+	return helmet.New(helmet.Config{
+		XFrameOptions:         "DENY",
+		ContentSecurityPolicy: "default-src 'none'; frame-ancestors 'none'",
+		HSTSMaxAge:            cfg.HTTPHSTSMaxAge,
+	})
+}
+
+func Timeout(cfg *config.Config, route string, handler fiber.Handler) fiber.Handler {
+	return RouteTimeout(cfg, route, cfg.HTTPRequestTimeout, handler)
+}
+
+// RouteTimeout is Timeout for routes that need a default other than
+// HTTP_REQUEST_TIMEOUT. An entry in HTTP_ROUTE_TIMEOUTS still wins.
+func RouteTimeout(cfg *config.Config, route string, d time.Duration, handler fiber.Handler) fiber.Handler {
+	// Invalid entries are rejected by config validation at startup.
+	if timeouts, err := cfg.RouteTimeouts(); err == nil {
+		if routeTimeout, ok := timeouts[route]; ok {
+			d = routeTimeout
+		}
+	}
+
+	return timeout.NewWithContext(handler, d)
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
@@ -0,0 +1,74 @@
+package server
+
+import (
+	"crypto/subtle"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/config"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/ratelimit"
+)
+
+// RateLimitByIP limits requests per client IP. It runs before
+// authentication, so requests with a missing or invalid API key are
+// throttled before they reach the database.
+func RateLimitByIP(limiter *ratelimit.Limiter) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		return rateLimit(c, limiter, "ip:"+c.IP())
+	}
+}
+
+// RateLimitByKey limits authenticated requests per API key. It runs after
+// authentication; requests without a key pass through.
+func RateLimitByKey(limiter *ratelimit.Limiter) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		prefix, ok := c.Locals("api_key_prefix").(string)
+		if !ok {
+			return c.Next()
+		}
+
+		return rateLimit(c, limiter, "key:"+prefix)
+	}
+}
+
+func rateLimit(c *fiber.Ctx, limiter *ratelimit.Limiter, key string) error {
+	decision := limiter.Allow(key)
+
+	c.Set("RateLimit-Limit", strconv.Itoa(decision.Limit))
+	c.Set("RateLimit-Remaining", strconv.Itoa(decision.Remaining))
+	c.Set("RateLimit-Reset", strconv.Itoa(seconds(decision.Reset)))
+
+	if !decision.Allowed {
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(seconds(decision.RetryAfter)))
+		return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+			"error": "rate limit exceeded",
+		})
+	}
+
+	return c.Next()
+}
+
+func RateLimitStatsHandler(limiter *ratelimit.Limiter) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		return c.Status(fiber.StatusOK).JSON(limiter.Stats())
+	}
+}
+
+func AdminAuth(cfg *config.Config) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		key := c.Get("X-Admin-Key")
+		if cfg.AdminAPIKey == "" || subtle.ConstantTimeCompare([]byte(key), []byte(cfg.AdminAPIKey)) != 1 {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "invalid admin key",
+			})
+		}
+
+		return c.Next()
+	}
+}
+
+func seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,14 @@
+package server
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/config"
+)
+
+// ConfigReloadsHandler lists the most recent config reloads, oldest first,
+// with the keys each one applied or ignored and why a rejected file failed.
+func ConfigReloadsHandler(watcher *config.Watcher) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		return c.Status(fiber.StatusOK).JSON(watcher.History())
+	}
+}
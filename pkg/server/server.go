@@ -6,22 +6,33 @@ import (
 	"runtime"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/gofiber/fiber/v2/middleware/healthcheck"
 	"github.com/gofiber/fiber/v2/middleware/logger"
 	"github.com/gofiber/fiber/v2/middleware/recover"
 	"github.com/jeancarloshp/desafio-frete-rapido/pkg/config"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/health"
 )
 
-func New(config *config.Config) *fiber.App {
+func New(watcher *config.Watcher) *fiber.App {
+	config := watcher.Current()
+
 	cfg := fiber.Config{
 		DisableStartupMessage: false,
 		ErrorHandler:          errorHandler,
+		BodyLimit:             config.HTTPBodyLimit,
+	}
+
+	trustedProxies := splitList(config.HTTPTrustedProxies)
+	if len(trustedProxies) > 0 {
+		cfg.EnableTrustedProxyCheck = true
+		cfg.TrustedProxies = trustedProxies
+		cfg.ProxyHeader = config.HTTPProxyHeader
+		cfg.EnableIPValidation = true
 	}
 
 	app := fiber.New(cfg)
 
 	app.Use(recover.New(recover.Config{
-		EnableStackTrace: true,
+		EnableStackTrace: config.HTTPRecoverStackTrace,
 		StackTraceHandler: func(c *fiber.Ctx, e any) {
 			buf := make([]byte, 4096)
 			buf = buf[:runtime.Stack(buf, false)]
@@ -29,8 +40,17 @@ func New(config *config.Config) *fiber.App {
 		},
 	}))
 
-	app.Use(healthcheck.New())
-	app.Use(logger.New())
+	app.Get(health.LivenessPath, health.LivenessHandler)
+	app.Use(logger.New(logger.Config{
+		Next: func(c *fiber.Ctx) bool {
+			return c.Path() == health.ReadinessPath
+		},
+	}))
+	app.Use(securityHeaders(config))
+
+	cors := newCORS(config)
+	watcher.Subscribe(cors.update)
+	app.Use(cors.handle)
 
 	return app
 }
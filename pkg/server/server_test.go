@@ -0,0 +1,471 @@
+package server_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/config"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/ratelimit"
+	"github.com/jeancarloshp/desafio-frete-rapido/pkg/server"
+)
+
+func testConfig() *config.Config {
+	return &config.Config{
+		HTTPCorsAllowedOrigins: "https://loja.com.br",
+		HTTPCorsAllowedMethods: "GET,POST",
+		HTTPCorsAllowedHeaders: "Content-Type",
+		HTTPBodyLimit:          64,
+		HTTPRequestTimeout:     time.Second,
+		HTTPProxyHeader:        "X-Forwarded-For",
+	}
+}
+
+func setupApp(cfg *config.Config) *fiber.App {
+	watcher := config.NewWatcher(cfg, nil)
+	app := server.New(watcher)
+
+	app.Get("/ping", func(c *fiber.Ctx) error {
+		return c.SendString(c.IP())
+	})
+	app.Post("/echo", func(c *fiber.Ctx) error {
+		return c.Send(c.Body())
+	})
+
+	return app
+}
+
+func TestCORS_AllowedOrigin(t *testing.T) {
+	app := setupApp(testConfig())
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.Header.Set("Origin", "https://loja.com.br")
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if got := resp.Header.Get("Access-Control-Allow-Origin"); got != "https://loja.com.br" {
+		t.Errorf("Expected allowed origin header, got: %q", got)
+	}
+}
+
+func TestCORS_DisallowedOrigin(t *testing.T) {
+	app := setupApp(testConfig())
+
+	req := httptest.NewRequest(http.MethodOptions, "/ping", nil)
+	req.Header.Set("Origin", "https://malicioso.com")
+	req.Header.Set("Access-Control-Request-Method", "POST")
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if got := resp.Header.Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("Expected no allowed origin header, got: %q", got)
+	}
+}
+
+func TestCORS_NoOriginsConfigured(t *testing.T) {
+	cfg := testConfig()
+	cfg.HTTPCorsAllowedOrigins = ""
+	app := setupApp(cfg)
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.Header.Set("Origin", "https://loja.com.br")
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if got := resp.Header.Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("Expected CORS to be disabled, got: %q", got)
+	}
+}
+
+const reloadConfigYAML = `
+app_port: "8080"
+database_host: localhost
+database_port: "5432"
+database_user: postgres
+database_password: postgres
+database_name: desafio_frete_rapido
+fastdelivery_api_token: 1d52a9b6b78cf07b08586152459a5c90
+fastdelivery_api_platform_code: 5AKVkHqCn
+fastdelivery_api_sender_cnpj: "25438296000158"
+fastdelivery_api_zip_code: 29161376
+http_cors_allowed_origins: %s
+`
+
+func TestCORS_HotReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(fmt.Sprintf(reloadConfigYAML, "https://loja.com.br")), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	args := []string{"--config", path}
+	cfg, err := config.New(args)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	watcher := config.NewWatcher(cfg, args)
+	app := server.New(watcher)
+	app.Get("/ping", func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	if err := os.WriteFile(path, []byte(fmt.Sprintf(reloadConfigYAML, "https://nova-loja.com.br")), 0o600); err != nil {
+		t.Fatalf("failed to rewrite config: %v", err)
+	}
+
+	if event := watcher.Reload(); event.Error != "" {
+		t.Fatalf("Expected reload to succeed, got: %s", event.Error)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.Header.Set("Origin", "https://nova-loja.com.br")
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if got := resp.Header.Get("Access-Control-Allow-Origin"); got != "https://nova-loja.com.br" {
+		t.Errorf("Expected reloaded origin to be allowed, got: %q", got)
+	}
+}
+
+func TestSecurityHeaders(t *testing.T) {
+	app := setupApp(testConfig())
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/ping", nil))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	expected := map[string]string{
+		"X-Content-Type-Options": "nosniff",
+		"X-Frame-Options":        "DENY",
+		"Referrer-Policy":        "no-referrer",
+	}
+
+	for header, value := range expected {
+		if got := resp.Header.Get(header); got != value {
+			t.Errorf("Expected %s %q, got: %q", header, value, got)
+		}
+	}
+}
+
+func TestBodyLimit(t *testing.T) {
+	app := setupApp(testConfig())
+
+	// O limite é aplicado pelo fasthttp antes do handler, então é preciso uma conexão real
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	go func() { _ = app.Listener(ln) }()
+	defer func() { _ = app.Shutdown() }()
+
+	resp, err := http.Post("http://"+ln.Addr().String()+"/echo", "text/plain", strings.NewReader(strings.Repeat("a", 128)))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != fiber.StatusRequestEntityTooLarge {
+		t.Errorf("Expected status 413, got: %d", resp.StatusCode)
+	}
+}
+
+func TestTimeout_PerRoute(t *testing.T) {
+	cfg := testConfig()
+	cfg.HTTPRouteTimeouts = "GET /slow=10ms"
+	app := setupApp(cfg)
+
+	wait := func(c *fiber.Ctx) error {
+		select {
+		case <-c.UserContext().Done():
+			return c.UserContext().Err()
+		case <-time.After(100 * time.Millisecond):
+			return c.SendStatus(fiber.StatusOK)
+		}
+	}
+	app.Get("/slow", server.Timeout(cfg, "GET /slow", wait))
+	app.Get("/default", server.Timeout(cfg, "GET /default", wait))
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/slow", nil), 2000)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if resp.StatusCode != fiber.StatusRequestTimeout {
+		t.Errorf("Expected status 408 for /slow, got: %d", resp.StatusCode)
+	}
+
+	// Rotas sem timeout específico usam HTTP_REQUEST_TIMEOUT (1s)
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/default", nil), 2000)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if resp.StatusCode != fiber.StatusOK {
+		t.Errorf("Expected status 200 for /default, got: %d", resp.StatusCode)
+	}
+}
+
+// Uma rota com timeout padrão próprio ignora HTTP_REQUEST_TIMEOUT, mas
+// HTTP_ROUTE_TIMEOUTS continua valendo sobre ela
+func TestRouteTimeout_OwnDefault(t *testing.T) {
+	cfg := testConfig()
+	cfg.HTTPRequestTimeout = 10 * time.Millisecond
+	cfg.HTTPRouteTimeouts = "POST /override=10ms"
+	app := setupApp(cfg)
+
+	wait := func(c *fiber.Ctx) error {
+		select {
+		case <-c.UserContext().Done():
+			return c.UserContext().Err()
+		case <-time.After(100 * time.Millisecond):
+			return c.SendStatus(fiber.StatusOK)
+		}
+	}
+	app.Post("/import", server.RouteTimeout(cfg, "POST /import", time.Second, wait))
+	app.Post("/override", server.RouteTimeout(cfg, "POST /override", time.Second, wait))
+
+	tests := []struct {
+		path       string
+		wantStatus int
+	}{
+		{path: "/import", wantStatus: fiber.StatusOK},
+		{path: "/override", wantStatus: fiber.StatusRequestTimeout},
+	}
+
+	for _, tt := range tests {
+		resp, err := app.Test(httptest.NewRequest(http.MethodPost, tt.path, nil), 2000)
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+
+		if resp.StatusCode != tt.wantStatus {
+			t.Errorf("Expected status %d for %s, got: %d", tt.wantStatus, tt.path, resp.StatusCode)
+		}
+	}
+}
+
+func TestTrustedProxy_ClientIP(t *testing.T) {
+	cfg := testConfig()
+	cfg.HTTPTrustedProxies = "0.0.0.0"
+	app := setupApp(cfg)
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.Header.Set("X-Forwarded-For", "203.0.113.7")
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != "203.0.113.7" {
+		t.Errorf("Expected client IP from trusted proxy header, got: %q", body)
+	}
+}
+
+func TestUntrustedProxy_ClientIP(t *testing.T) {
+	app := setupApp(testConfig())
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.Header.Set("X-Forwarded-For", "203.0.113.7")
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) == "203.0.113.7" {
+		t.Error("Expected forwarded header to be ignored without trusted proxies")
+	}
+}
+
+func TestRateLimit_HeadersAndRetryAfter(t *testing.T) {
+	app := fiber.New()
+	app.Use(server.RateLimitByIP(ratelimit.New(60, 2)))
+	app.Get("/ping", func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	for i := 0; i < 2; i++ {
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/ping", nil))
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+
+		if resp.StatusCode != fiber.StatusOK {
+			t.Fatalf("Expected status 200, got: %d", resp.StatusCode)
+		}
+
+		if got := resp.Header.Get("RateLimit-Remaining"); got != fmt.Sprint(1-i) {
+			t.Errorf("Expected RateLimit-Remaining %d, got: %q", 1-i, got)
+		}
+	}
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/ping", nil))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if resp.StatusCode != fiber.StatusTooManyRequests {
+		t.Errorf("Expected status 429, got: %d", resp.StatusCode)
+	}
+
+	if got := resp.Header.Get("Retry-After"); got != "1" {
+		t.Errorf("Expected Retry-After 1, got: %q", got)
+	}
+}
+
+// O limite por IP vem antes da autenticação: chaves inválidas também são
+// limitadas, e cada chave válida tem o próprio balde
+func TestRateLimit_ThrottlesBeforeAuth(t *testing.T) {
+	limiter := ratelimit.New(60, 2)
+
+	app := fiber.New(fiber.Config{ProxyHeader: fiber.HeaderXForwardedFor})
+	app.Use(server.RateLimitByIP(limiter), func(c *fiber.Ctx) error {
+		key := c.Get("X-API-Key")
+		if key != "valid-a" && key != "valid-b" {
+			return c.SendStatus(fiber.StatusUnauthorized)
+		}
+
+		c.Locals("api_key_prefix", key)
+		return c.Next()
+	}, server.RateLimitByKey(limiter))
+	app.Get("/ping", func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	do := func(ip, key string) int {
+		t.Helper()
+
+		req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+		req.Header.Set(fiber.HeaderXForwardedFor, ip)
+		req.Header.Set("X-API-Key", key)
+
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+
+		return resp.StatusCode
+	}
+
+	tests := []struct {
+		name string
+		ip   string
+		key  string
+		want int
+	}{
+		{name: "chave inválida", ip: "198.51.100.1", key: "bad", want: fiber.StatusUnauthorized},
+		{name: "chave inválida", ip: "198.51.100.1", key: "bad", want: fiber.StatusUnauthorized},
+		{name: "IP esgotado", ip: "198.51.100.1", key: "bad", want: fiber.StatusTooManyRequests},
+		{name: "IP esgotado com chave válida", ip: "198.51.100.1", key: "valid-a", want: fiber.StatusTooManyRequests},
+		{name: "chave válida", ip: "198.51.100.2", key: "valid-a", want: fiber.StatusOK},
+		{name: "chave válida de outro IP", ip: "198.51.100.3", key: "valid-a", want: fiber.StatusOK},
+		{name: "chave esgotada", ip: "198.51.100.4", key: "valid-a", want: fiber.StatusTooManyRequests},
+		{name: "outra chave", ip: "198.51.100.4", key: "valid-b", want: fiber.StatusOK},
+	}
+
+	for _, tt := range tests {
+		if got := do(tt.ip, tt.key); got != tt.want {
+			t.Errorf("%s: expected status %d, got: %d", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestAdminAuth(t *testing.T) {
+	cfg := testConfig()
+	cfg.AdminAPIKey = "admin-key-admin-key-admin-key-00"
+
+	app := fiber.New()
+	app.Get("/admin", server.AdminAuth(cfg), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/admin", nil)
+	req.Header.Set("X-Admin-Key", "wrong")
+	resp, _ := app.Test(req)
+	if resp.StatusCode != fiber.StatusUnauthorized {
+		t.Errorf("Expected status 401 for wrong key, got: %d", resp.StatusCode)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/admin", nil)
+	req.Header.Set("X-Admin-Key", cfg.AdminAPIKey)
+	resp, _ = app.Test(req)
+	if resp.StatusCode != fiber.StatusOK {
+		t.Errorf("Expected status 200 for admin key, got: %d", resp.StatusCode)
+	}
+}
+
+func TestConfigReloadsHandler(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(fmt.Sprintf(reloadConfigYAML, "https://loja.com.br")), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	args := []string{"--config", path}
+	cfg, err := config.New(args)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	watcher := config.NewWatcher(cfg, args)
+	app := fiber.New()
+	app.Get("/admin/config/reloads", server.ConfigReloadsHandler(watcher))
+
+	if err := os.WriteFile(path, []byte(strings.Replace(fmt.Sprintf(reloadConfigYAML, "https://nova-loja.com.br"), "8080", "9090", 1)), 0o600); err != nil {
+		t.Fatalf("failed to rewrite config: %v", err)
+	}
+	watcher.Reload()
+
+	if err := os.WriteFile(path, []byte("app_port: ["), 0o600); err != nil {
+		t.Fatalf("failed to rewrite config: %v", err)
+	}
+	watcher.Reload()
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/admin/config/reloads", nil))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var reloads []config.ReloadEvent
+	if err := json.NewDecoder(resp.Body).Decode(&reloads); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if len(reloads) != 2 {
+		t.Fatalf("Expected 2 reloads, got: %d", len(reloads))
+	}
+
+	if got := reloads[0]; got.Error != "" || !slices.Equal(got.Applied, []string{"HTTP_CORS_ALLOWED_ORIGINS"}) || !slices.Equal(got.Ignored, []string{"APP_PORT"}) {
+		t.Errorf("Expected the first reload to apply the origins and ignore the port, got: %+v", got)
+	}
+
+	if reloads[1].Error == "" {
+		t.Errorf("Expected the invalid file to be reported, got: %+v", reloads[1])
+	}
+}